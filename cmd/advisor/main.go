@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/auth"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/cache"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/feedback"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/marketplace"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recommend"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/session"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/summary"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/translator"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/polly"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	impressions *feedback.Recorder
+	advisor     *session.Advisor
+	handler     middleware.Handler
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	// Таксономия перечитывается из источника в теплой Lambda
+	store, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(store)
+
+	resultCache, err := cache.NewFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init cache: %v", err)
+	}
+
+	sessions, err := session.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init session store: %v", err)
+	}
+
+	ttl, err := session.LoadTTL()
+	if err != nil {
+		log.Fatalf("unable to load session TTL: %v", err)
+	}
+
+	summaries, err := summary.NewFromEnv()
+	if err != nil {
+		log.Fatalf("unable to init summary generator: %v", err)
+	}
+
+	// Голосовые ответы доступны, только если задан бакет для аудио
+	var speaker recommend.Speaker
+	if bucketName := os.Getenv("AUDIO_BUCKET_NAME"); bucketName != "" {
+		speaker = translator.NewTranslator(nil, polly.NewFromConfig(cfg), s3.NewFromConfig(cfg), bucketName)
+	}
+
+	productService := marketplace.NewProductService(dynamoClient, resultCache)
+	ranker, err := feedback.NewRankerFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init feedback ranking: %v", err)
+	}
+	if ranker != nil {
+		productService.SetRanker(ranker)
+	}
+
+	// Показы выдачи пишет сервер, по ним принимаются реакции в /feedback
+	impressions, err = feedback.NewRecorderFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init feedback store: %v", err)
+	}
+
+	recommender := recommend.NewService(recommend.NewResolver(), productService, summaries, speaker)
+	advisor = session.NewAdvisor(sessions, recommender, ttl)
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "advisor", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
+}
+
+// POST /advisor/sessions                  - начать диалог
+// GET  /advisor/sessions/{id}             - состояние сессии
+// POST /advisor/sessions/{id}/messages    - ответ пользователя
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	id := request.PathParameters["id"]
+
+	var (
+		turn *types.AdvisorTurnResponseApi
+		err  error
+	)
+	switch {
+	case request.HTTPMethod == "GET" && id != "":
+		turn, err = advisor.Get(ctx, id)
+	case request.HTTPMethod == "POST":
+		var message types.AdvisorMessageRequestApi
+		if request.Body != "" {
+			if err := json.Unmarshal([]byte(request.Body), &message); err != nil {
+				return events.APIGatewayProxyResponse{
+					StatusCode: 400,
+					Body:       `{"error":"Invalid request body"}`,
+					Headers:    headers,
+				}, nil
+			}
+		}
+		if id == "" {
+			turn, err = advisor.Start(ctx, message.Request, message.Message)
+		} else {
+			if message.Message == "" {
+				return events.APIGatewayProxyResponse{
+					StatusCode: 400,
+					Body:       `{"error":"message is required"}`,
+					Headers:    headers,
+				}, nil
+			}
+			turn, err = advisor.Reply(ctx, id, message.Message)
+		}
+	default:
+		return events.APIGatewayProxyResponse{
+			StatusCode: 405,
+			Body:       `{"error":"Method not allowed"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	switch {
+	case errors.Is(err, session.ErrNotFound):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 404,
+			Body:       `{"error":"session not found"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, session.ErrConflict):
+		// Клиент отправил два ответа одновременно - пусть перечитает сессию
+		return events.APIGatewayProxyResponse{
+			StatusCode: 409,
+			Body:       `{"error":"session was modified concurrently, retry"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, recommend.ErrUnknownOccasion) || errors.Is(err, recommend.ErrInvalidAge),
+		errors.Is(err, money.ErrUnknownCurrency):
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	case err != nil:
+		log.Printf("Advisor request failed: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"advisor request failed"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	if turn.Recommendation != nil && request.HTTPMethod == "POST" {
+		turn.Recommendation.ImpressionID, err = impressions.Shown(ctx, auth.UserID(request), turn.SessionID, &turn.Request, turn.Recommendation.Products)
+		if err != nil {
+			log.Printf("Failed to record impression: %v", err)
+		}
+	}
+
+	response := types.ApiResponse{
+		Success: true,
+		Data:    turn,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"Failed to marshal response"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	statusCode := 200
+	if request.HTTPMethod == "POST" && id == "" {
+		statusCode = 201
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Body:       string(responseJSON),
+		Headers:    headers,
+	}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
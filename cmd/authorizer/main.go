@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/auth"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/quota"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+var (
+	authenticator *auth.Authenticator
+	keys          auth.KeyStore
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	keys, err = auth.NewKeyStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init api keys store: %v", err)
+	}
+
+	verifier, err := auth.NewJWTVerifierFromEnv()
+	if err != nil {
+		log.Fatalf("unable to init JWT verification: %v", err)
+	}
+
+	// Квоты ключей хранятся в QUOTA_TABLE вместе с квотой Serper
+	authenticator = auth.NewAuthenticator(keys, verifier, dynamoClient, quota.LimitsFromEnv("API_KEY"))
+}
+
+// handleRequest - REQUEST-авторизатор API Gateway: принимает ключ из
+// X-Api-Key или JWT из Authorization: Bearer. Пользователь передается
+// обработчикам в principalId, его читает auth.UserID.
+func handleRequest(ctx context.Context, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	principal, err := authenticator.Authenticate(ctx, request.Headers)
+	switch {
+	case errors.Is(err, auth.ErrUnauthorized):
+		// API Gateway отвечает 401 только на эту строку
+		return events.APIGatewayCustomAuthorizerResponse{}, errors.New("Unauthorized")
+	case errors.Is(err, auth.ErrForbidden), errors.Is(err, auth.ErrQuotaExceeded):
+		log.Printf("Access denied for key %s: %v", principal.KeyID, err)
+		return policy(principal, "Deny", request.MethodArn, err.Error()), nil
+	case err != nil:
+		// Ошибка хранилища: API Gateway ответит 500, доступ не выдается
+		log.Printf("Authorization failed: %v", err)
+		return events.APIGatewayCustomAuthorizerResponse{}, fmt.Errorf("authorization failed: %w", err)
+	}
+
+	return policy(principal, "Allow", request.MethodArn, ""), nil
+}
+
+// policy разрешает или запрещает все методы стадии: при кэшировании ответа
+// авторизатора одно решение применяется ко всем маршрутам
+func policy(principal *auth.Principal, effect, methodArn, reason string) events.APIGatewayCustomAuthorizerResponse {
+	// arn:aws:execute-api:region:account:apiId/stage/METHOD/path
+	resource := methodArn
+	if parts := strings.SplitN(methodArn, "/", 3); len(parts) == 3 {
+		resource = parts[0] + "/" + parts[1] + "/*"
+	}
+
+	authContext := map[string]interface{}{
+		"auth_method": principal.Method,
+	}
+	if principal.KeyID != "" {
+		authContext["key_id"] = principal.KeyID
+	}
+	if principal.Email != "" {
+		authContext["email"] = principal.Email
+	}
+	if principal.Usage != nil {
+		authContext["daily_remaining"] = principal.Usage.DailyRemaining()
+		authContext["monthly_remaining"] = principal.Usage.MonthlyRemaining()
+	}
+	if reason != "" {
+		authContext["error"] = reason
+	}
+
+	return events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID: principal.UserID,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version: "2012-10-17",
+			Statement: []events.IAMPolicyStatement{{
+				Action:   []string{"execute-api:Invoke"},
+				Effect:   effect,
+				Resource: []string{resource},
+			}},
+		},
+		Context: authContext,
+	}
+}
+
+func main() {
+	// Вне Lambda работает как утилита для выдачи и отключения ключей
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		lambda.Start(handleRequest)
+		return
+	}
+
+	userID := flag.String("issue", "", "issue a new api key for this user id")
+	name := flag.String("name", "", "api key description")
+	email := flag.String("email", "", "verified email of the key owner for notifications")
+	daily := flag.Int64("daily", 0, "daily request limit of the key (0 - API_KEY_DAILY_LIMIT)")
+	monthly := flag.Int64("monthly", 0, "monthly request limit of the key (0 - API_KEY_MONTHLY_LIMIT)")
+	disable := flag.String("disable", "", "disable this api key")
+	flag.Parse()
+
+	ctx := context.Background()
+	switch {
+	case *userID != "":
+		plain, key, err := auth.GenerateKey(*userID, *name)
+		if err != nil {
+			log.Fatalf("failed to issue api key: %v", err)
+		}
+		if *email != "" {
+			address, err := mail.ParseAddress(*email)
+			if err != nil {
+				log.Fatalf("invalid email: %v", err)
+			}
+			key.Email = address.Address
+		}
+		key.DailyLimit = *daily
+		key.MonthlyLimit = *monthly
+		if err := keys.Put(ctx, key); err != nil {
+			log.Fatalf("failed to save api key: %v", err)
+		}
+
+		// Открытое значение показывается один раз
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(map[string]interface{}{
+			"api_key": plain,
+			"key":     key,
+		})
+	case *disable != "":
+		key, err := keys.Get(ctx, auth.HashKey(*disable))
+		if err != nil {
+			log.Fatalf("failed to find api key: %v", err)
+		}
+		key.Disabled = true
+		if err := keys.Put(ctx, key); err != nil {
+			log.Fatalf("failed to disable api key: %v", err)
+		}
+		log.Printf("Api key %s of user %s disabled", key.ID, key.UserID)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
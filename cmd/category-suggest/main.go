@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recommend"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+var (
+	resolver *recommend.Resolver
+	handler  middleware.Handler
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	// Таксономия перечитывается из источника в теплой Lambda
+	store, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(store)
+
+	resolver = recommend.NewResolver()
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "categories-suggest", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
+}
+
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	// Принимаем тот же GiftRequest, что и подбор подарков: фронтенд может
+	// показать категории до поиска товаров
+	var giftRequest types.GiftRequest
+	if err := json.Unmarshal([]byte(request.Body), &giftRequest); err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       `{"error":"Invalid request body"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	resolution, err := resolver.Resolve(giftRequest)
+	if errors.Is(err, recommend.ErrUnknownOccasion) || errors.Is(err, recommend.ErrInvalidAge) {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	}
+	if err != nil {
+		log.Printf("Failed to resolve categories: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"failed to suggest categories"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	response := types.ApiResponse{
+		Success: true,
+		Data:    resolution,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"Failed to marshal response"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseJSON),
+		Headers:    headers,
+	}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/marketplace"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+var (
+	serperBudget *marketplace.SerperBudget
+	handler      middleware.Handler
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+	serperBudget = marketplace.NewSerperBudgetFromEnv(dynamoClient)
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "diagnostics", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
+}
+
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var diagnostics types.DiagnosticsResponseApi
+
+	if serperBudget != nil {
+		usage, err := serperBudget.Usage(ctx)
+		if err != nil {
+			log.Printf("Failed to read Serper quota: %v", err)
+			return events.APIGatewayProxyResponse{
+				StatusCode: 500,
+				Body:       `{"error":"failed to read quota"}`,
+				Headers:    headers,
+			}, nil
+		}
+
+		diagnostics.SerperQuota = &types.QuotaStatusApi{
+			DailyUsed:        usage.Daily,
+			DailyLimit:       usage.Limits.Daily,
+			DailyRemaining:   usage.DailyRemaining(),
+			MonthlyUsed:      usage.Monthly,
+			MonthlyLimit:     usage.Limits.Monthly,
+			MonthlyRemaining: usage.MonthlyRemaining(),
+			SoftLimitReached: usage.SoftLimitReached(),
+		}
+	}
+
+	response := types.ApiResponse{
+		Success: true,
+		Data:    diagnostics,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"Failed to marshal response"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseJSON),
+		Headers:    headers,
+	}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/feedback"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// За какой период по умолчанию считается CTR
+const defaultWindow = 30 * 24 * time.Hour
+
+var (
+	feedbackStore feedback.Store
+	statsStore    feedback.StatsStore
+	window        time.Duration
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	feedbackStore, err = feedback.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init feedback store: %v", err)
+	}
+
+	statsStore = feedback.NewStatsStoreFromEnv(dynamoClient)
+	if statsStore == nil {
+		log.Fatal("FEEDBACK_STATS_TABLE environment variable is required")
+	}
+
+	window = defaultWindow
+	if v := os.Getenv("FEEDBACK_WINDOW"); v != "" {
+		window, err = time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("invalid FEEDBACK_WINDOW: %v", err)
+		}
+	}
+}
+
+// handleRequest запускается по расписанию EventBridge: пересчитывает CTR
+// категорий и магазинов и сохраняет снимок, который читает поиск
+func handleRequest(ctx context.Context, event events.CloudWatchEvent) error {
+	since := time.Now().UTC().Add(-window)
+
+	stats, err := feedback.Aggregate(ctx, feedbackStore, since)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate feedback: %w", err)
+	}
+
+	if err := statsStore.Save(ctx, stats); err != nil {
+		return err
+	}
+
+	log.Printf("Feedback stats saved: %d events, %d categories, %d stores, global CTR %.3f",
+		stats.Events, len(stats.Categories), len(stats.Stores), stats.Global.CTR())
+	return nil
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/auth"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/feedback"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Сколько событий принимается за один запрос
+const maxEvents = 100
+
+var (
+	recorder *feedback.Recorder
+	handler  middleware.Handler
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	recorder, err = feedback.NewRecorderFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init feedback store: %v", err)
+	}
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "feedback", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
+}
+
+// POST /feedback - реакции на товары из выдачи impression_id. Показы пишут
+// обработчики, которые возвращают товары.
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var feedbackRequest types.FeedbackRequestApi
+	if err := json.Unmarshal([]byte(request.Body), &feedbackRequest); err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       `{"error":"Invalid request body"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	if len(feedbackRequest.Events) == 0 || len(feedbackRequest.Events) > maxEvents {
+		body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("events must contain 1 to %d items", maxEvents)})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	}
+
+	if feedbackRequest.ImpressionID == "" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       `{"error":"impression_id is required"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	reactions := make([]feedback.Reaction, 0, len(feedbackRequest.Events))
+	for _, e := range feedbackRequest.Events {
+		reactions = append(reactions, feedback.Reaction{Type: e.Type, ProductID: e.ProductID})
+	}
+
+	records, err := recorder.Engage(ctx, auth.UserID(request), feedbackRequest.ImpressionID, reactions)
+	if errors.Is(err, feedback.ErrInvalidEvent) {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	}
+	if errors.Is(err, feedback.ErrImpressionNotFound) {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 404,
+			Body:       `{"error":"impression not found"}`,
+			Headers:    headers,
+		}, nil
+	}
+	if err != nil {
+		log.Printf("Failed to record feedback: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"failed to record feedback"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	response := types.ApiResponse{
+		Success: true,
+		Data:    types.FeedbackResponseApi{Recorded: len(records)},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"Failed to marshal response"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseJSON),
+		Headers:    headers,
+	}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
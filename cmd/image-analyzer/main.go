@@ -6,14 +6,20 @@ import (
 	"log"
 
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/analyzer"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/rekognition"
 )
 
-var imageAnalyzer *analyzer.ImageAnalyzer
+var (
+	imageAnalyzer *analyzer.ImageAnalyzer
+	handler       middleware.Handler
+)
 
 func init() {
 	// Инициализация AWS клиентов при холодном старте
@@ -22,21 +28,28 @@ func init() {
 		log.Fatalf("unable to load SDK config: %v", err)
 	}
 
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
 	rekognitionClient := rekognition.NewFromConfig(cfg)
 	imageAnalyzer = analyzer.NewImageAnalyzer(rekognitionClient)
+
+	// Таксономия перечитывается из источника в теплой Lambda
+	store, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(store)
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "analyze-image", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
 }
 
 func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	headers := map[string]string{
-		"Access-Control-Allow-Origin": "*",
-		"Content-Type":                "application/json",
-	}
-
-	if request.HTTPMethod == "OPTIONS" {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 200,
-			Headers:    headers,
-		}, nil
+		"Content-Type": "application/json",
 	}
 
 	var analysisRequest types.ImageAnalysisRequestApi
@@ -69,12 +82,7 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 	}
 
 	// Преобразование меток в категории
-	var categories []string
-	for _, label := range labels {
-		if cats, ok := analyzer.LabelCategories[label]; ok {
-			categories = append(categories, cats...)
-		}
-	}
+	categories := taxonomy.Current().LabelCategories(labels)
 
 	response := types.ApiResponse{
 		Success: true,
@@ -101,5 +109,5 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 }
 
 func main() {
-	lambda.Start(handleRequest)
+	lambda.Start(handler)
 }
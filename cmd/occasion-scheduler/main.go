@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	_ "time/tzdata" // Часовые пояса поводов не зависят от образа Lambda
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/cache"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/feedback"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/marketplace"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/notify"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/occasion"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recipient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recommend"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/summary"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+)
+
+var (
+	store       occasion.Store
+	recipients  recipient.Store
+	recommender occasion.Recommender
+	notifier    notify.Notifier
+	options     occasion.Options
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	// Названия поводов и категории подарков берутся из таксономии
+	taxonomyStore, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(taxonomyStore)
+
+	resultCache, err := cache.NewFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init cache: %v", err)
+	}
+
+	store, err = occasion.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init occasions store: %v", err)
+	}
+
+	recipients, err = recipient.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init recipients store: %v", err)
+	}
+
+	summaries, err := summary.NewFromEnv()
+	if err != nil {
+		log.Fatalf("unable to init summary generator: %v", err)
+	}
+
+	productService := marketplace.NewProductService(dynamoClient, resultCache)
+	ranker, err := feedback.NewRankerFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init feedback ranking: %v", err)
+	}
+	if ranker != nil {
+		productService.SetRanker(ranker)
+	}
+
+	// Напоминания без озвучки
+	recommender = recommend.NewService(recommend.NewResolver(), productService, summaries, nil)
+
+	notifier, err = notify.NewFromEnv("REMINDER", "reminders.jsonl", sesv2.NewFromConfig(cfg))
+	if err != nil {
+		log.Fatalf("unable to init reminder notifier: %v", err)
+	}
+
+	options, err = occasion.LoadOptions()
+	if err != nil {
+		log.Fatalf("unable to load occasion scheduler options: %v", err)
+	}
+}
+
+// handleRequest запускается по расписанию EventBridge: подбирает подарки к
+// приближающимся поводам и рассылает напоминания
+func handleRequest(ctx context.Context, event events.CloudWatchEvent) error {
+	report, err := occasion.NewScheduler(store, recipients, recommender, notifier, options).Run(ctx)
+	if err != nil {
+		return fmt.Errorf("occasion reminders failed: %w", err)
+	}
+
+	log.Printf("Occasions checked: %d occasions, %d due, %d reminders, %d without gifts, %d undelivered",
+		report.Occasions, report.Due, report.Reminders, report.WithoutGifts, report.NotifyFailed)
+	return nil
+}
+
+func main() {
+	// Вне Lambda работает как утилита: один прогон и отчет в stdout
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		lambda.Start(handleRequest)
+		return
+	}
+
+	dryRun := flag.Bool("dry-run", false, "only log due occasions, do not recommend or send reminders")
+	flag.Parse()
+	options.DryRun = *dryRun
+
+	report, err := occasion.NewScheduler(store, recipients, recommender, notifier, options).Run(context.Background())
+	if err != nil {
+		log.Fatalf("occasion reminders failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	_ "time/tzdata" // Часовые пояса поводов не зависят от образа Lambda
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/auth"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/occasion"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recipient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+var (
+	occasions  occasion.Store
+	recipients recipient.Store
+	handler    middleware.Handler
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	// Поводы проверяются по таксономии
+	store, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(store)
+
+	occasions, err = occasion.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init occasions store: %v", err)
+	}
+
+	recipients, err = recipient.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init recipients store: %v", err)
+	}
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "occasions", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
+}
+
+// GET    /occasions        - календарь пользователя, ближайшие поводы первыми
+// POST   /occasions        - добавить повод
+// GET    /occasions/{id}   - повод с последней подборкой подарков
+// PUT    /occasions/{id}   - заменить повод
+// DELETE /occasions/{id}   - удалить повод
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	userID := auth.UserID(request)
+	if userID == "" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       `{"error":"Unauthorized"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	id := request.PathParameters["id"]
+	now := time.Now()
+
+	var (
+		data       interface{}
+		statusCode = 200
+		err        error
+	)
+	switch {
+	case id == "" && request.HTTPMethod == "GET":
+		data, err = list(ctx, userID, now)
+	case id == "" && request.HTTPMethod == "POST":
+		var entry *occasion.Entry
+		entry, err = create(ctx, userID, auth.Email(request), request.Body)
+		if err == nil {
+			data = entry.View(now)
+		}
+		statusCode = 201
+	case id != "" && request.HTTPMethod == "GET":
+		var entry *occasion.Entry
+		entry, err = occasions.Get(ctx, userID, id)
+		if err == nil {
+			data = entry.View(now)
+		}
+	case id != "" && request.HTTPMethod == "PUT":
+		var entry *occasion.Entry
+		entry, err = update(ctx, userID, id, auth.Email(request), request.Body)
+		if err == nil {
+			data = entry.View(now)
+		}
+	case id != "" && request.HTTPMethod == "DELETE":
+		err = occasions.Delete(ctx, userID, id)
+		statusCode = 204
+	default:
+		return events.APIGatewayProxyResponse{
+			StatusCode: 405,
+			Body:       `{"error":"Method not allowed"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxError) || errors.As(err, &typeError):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       `{"error":"Invalid request body"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, occasion.ErrInvalid):
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, occasion.ErrNotFound):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 404,
+			Body:       `{"error":"occasion not found"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, recipient.ErrNotFound):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 404,
+			Body:       `{"error":"recipient not found"}`,
+			Headers:    headers,
+		}, nil
+	case err != nil:
+		log.Printf("Occasions request failed: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"occasions request failed"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	if statusCode == 204 {
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+		}, nil
+	}
+
+	response := types.ApiResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"Failed to marshal response"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Body:       string(responseJSON),
+		Headers:    headers,
+	}, nil
+}
+
+// list возвращает календарь: сначала ближайшие поводы, прошедшие разовые
+// поводы в конце
+func list(ctx context.Context, userID string, now time.Time) ([]occasion.View, error) {
+	entries, err := occasions.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]occasion.View, 0, len(entries))
+	for i := range entries {
+		views = append(views, entries[i].View(now))
+	}
+	sort.SliceStable(views, func(i, j int) bool {
+		a, b := views[i].DaysLeft, views[j].DaysLeft
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return *a < *b
+	})
+	return views, nil
+}
+
+// parse разбирает и проверяет повод; получатель должен быть в адресной
+// книге. Письма отправляются только на подтвержденный адрес email.
+func parse(ctx context.Context, userID, email, body string) (*occasion.Entry, error) {
+	var entry occasion.Entry
+	if err := json.Unmarshal([]byte(body), &entry); err != nil {
+		return nil, err
+	}
+	entry.Email = ""
+	if entry.EmailReminders {
+		if email == "" {
+			return nil, fmt.Errorf("%w: email reminders require a verified email", occasion.ErrInvalid)
+		}
+		entry.Email = email
+	}
+	if err := entry.Normalize(); err != nil {
+		return nil, err
+	}
+	if entry.RecipientID != "" {
+		if _, err := recipients.Get(ctx, userID, entry.RecipientID); err != nil {
+			return nil, err
+		}
+	}
+	entry.UserID = userID
+	return &entry, nil
+}
+
+func create(ctx context.Context, userID, email, body string) (*occasion.Entry, error) {
+	entry, err := parse(ctx, userID, email, body)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := occasion.NewID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	entry.ID = id
+	entry.RemindedFor = ""
+	entry.RemindedAt = time.Time{}
+	entry.Recommendation = nil
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+
+	if err := occasions.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// update заменяет повод. Состояние напоминания хранилище не меняет: если
+// дата не изменилась, повторного напоминания не будет, а отметка
+// планировщика, поставленная во время правки, не теряется.
+func update(ctx context.Context, userID, id, email, body string) (*occasion.Entry, error) {
+	entry, err := parse(ctx, userID, email, body)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.ID = id
+	entry.UpdatedAt = time.Now().UTC()
+	return occasions.Update(ctx, entry)
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/auth"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/cache"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/marketplace"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/pricetrack"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// За сколько дней по умолчанию отдается история цен
+const defaultHistoryDays = 90
+
+var (
+	store   pricetrack.Store
+	fetcher pricetrack.Fetcher
+	handler middleware.Handler
+)
+
+// watchDetails - подписка вместе с историей цен товара
+type watchDetails struct {
+	*pricetrack.Watch
+	History []pricetrack.Point `json:"history"`
+}
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	store, err = pricetrack.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init price watch store: %v", err)
+	}
+
+	// Цена, от которой считается снижение, берется из источников, как у
+	// price-tracker, а не из запроса
+	taxonomyStore, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(taxonomyStore)
+
+	resultCache, err := cache.NewFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init cache: %v", err)
+	}
+	fetcher = pricetrack.NewSearchFetcher(marketplace.NewProductService(dynamoClient, resultCache))
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "price-alerts", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
+}
+
+// GET    /price-alerts                    - отслеживаемые товары пользователя
+// POST   /price-alerts                    - отслеживать товар (повторно - заменить настройки)
+// GET    /price-alerts/{product_id}       - подписка и история цен (?days=, по умолчанию 90)
+// DELETE /price-alerts/{product_id}       - перестать отслеживать
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	userID := auth.UserID(request)
+	if userID == "" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       `{"error":"Unauthorized"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	productID := request.PathParameters["product_id"]
+
+	var (
+		data       interface{}
+		statusCode = 200
+		err        error
+	)
+	switch {
+	case productID == "" && request.HTTPMethod == "GET":
+		data, err = store.List(ctx, userID)
+	case productID == "" && request.HTTPMethod == "POST":
+		data, err = watch(ctx, userID, auth.Email(request), request.Body)
+		statusCode = 201
+	case productID != "" && request.HTTPMethod == "GET":
+		data, err = details(ctx, userID, productID, request.QueryStringParameters["days"])
+	case productID != "" && request.HTTPMethod == "DELETE":
+		err = store.Delete(ctx, userID, productID)
+		statusCode = 204
+	default:
+		return events.APIGatewayProxyResponse{
+			StatusCode: 405,
+			Body:       `{"error":"Method not allowed"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxError) || errors.As(err, &typeError):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       `{"error":"Invalid request body"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, pricetrack.ErrInvalid), errors.Is(err, pricetrack.ErrNoVerifiedEmail),
+		errors.Is(err, money.ErrUnknownCurrency):
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, pricetrack.ErrNotFound):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 404,
+			Body:       `{"error":"price watch not found"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, pricetrack.ErrProductGone):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 404,
+			Body:       `{"error":"product is not listed in any source"}`,
+			Headers:    headers,
+		}, nil
+	case err != nil:
+		log.Printf("Price alerts request failed: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"price alerts request failed"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	if statusCode == 204 {
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+		}, nil
+	}
+
+	response := types.ApiResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"Failed to marshal response"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Body:       string(responseJSON),
+		Headers:    headers,
+	}, nil
+}
+
+// watch создает подписку или меняет настройки существующей; результаты
+// прошлых проверок сохраняются. Товар новой подписки перечитывается из
+// источников: от его текущей цены считается снижение. Письма уходят только
+// на подтвержденный адрес пользователя.
+func watch(ctx context.Context, userID, email, body string) (*pricetrack.Watch, error) {
+	var watchRequest types.PriceWatchRequestApi
+	if err := json.Unmarshal([]byte(body), &watchRequest); err != nil {
+		return nil, err
+	}
+	if watchRequest.Product.ID == "" || strings.TrimSpace(watchRequest.Product.Title) == "" {
+		return nil, fmt.Errorf("%w: product id and title are required", pricetrack.ErrInvalid)
+	}
+	if watchRequest.EmailAlerts && email == "" {
+		return nil, pricetrack.ErrNoVerifiedEmail
+	}
+
+	w := &pricetrack.Watch{
+		UserID:      userID,
+		TargetPrice: watchRequest.TargetPrice,
+		Language:    watchRequest.Language,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if watchRequest.EmailAlerts {
+		w.Email = email
+	}
+
+	current, err := store.Get(ctx, userID, watchRequest.Product.ID)
+	switch {
+	case err == nil:
+		w.Product = current.Product
+		w.CreatedAt = current.CreatedAt
+		w.CurrentPrice = current.CurrentPrice
+		w.CheckedAt = current.CheckedAt
+		w.NotifiedPrice = current.NotifiedPrice
+	case errors.Is(err, pricetrack.ErrNotFound):
+		watches, err := store.List(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(watches) >= pricetrack.MaxWatches {
+			return nil, fmt.Errorf("%w: up to %d products can be tracked", pricetrack.ErrInvalid, pricetrack.MaxWatches)
+		}
+
+		product, err := fetcher.Fetch(ctx, watchRequest.Product)
+		if err != nil {
+			return nil, err
+		}
+		w.Product = *product
+	default:
+		return nil, err
+	}
+
+	if err := w.Normalize(); err != nil {
+		return nil, err
+	}
+	if err := store.Put(ctx, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func details(ctx context.Context, userID, productID, daysParam string) (*watchDetails, error) {
+	days := defaultHistoryDays
+	if daysParam != "" {
+		var err error
+		days, err = strconv.Atoi(daysParam)
+		if err != nil || days <= 0 {
+			return nil, fmt.Errorf("%w: days must be a positive number", pricetrack.ErrInvalid)
+		}
+	}
+
+	w, err := store.Get(ctx, userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := store.History(ctx, productID, time.Now().UTC().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+	return &watchDetails{Watch: w, History: history}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/cache"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/marketplace"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/notify"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/pricetrack"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+)
+
+var (
+	store    pricetrack.Store
+	fetcher  pricetrack.Fetcher
+	notifier notify.Notifier
+	options  pricetrack.Options
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	// Категории товаров переводятся в названия маркетплейсов по таксономии
+	taxonomyStore, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(taxonomyStore)
+
+	resultCache, err := cache.NewFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init cache: %v", err)
+	}
+
+	store, err = pricetrack.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init price watch store: %v", err)
+	}
+
+	notifier, err = notify.NewFromEnv("PRICE_ALERT", "price-alerts.jsonl", sesv2.NewFromConfig(cfg))
+	if err != nil {
+		log.Fatalf("unable to init price alert notifier: %v", err)
+	}
+
+	options, err = pricetrack.LoadOptions()
+	if err != nil {
+		log.Fatalf("unable to load price tracker options: %v", err)
+	}
+
+	fetcher = pricetrack.NewSearchFetcher(marketplace.NewProductService(dynamoClient, resultCache))
+}
+
+// handleRequest запускается по расписанию EventBridge: перепроверяет цены
+// отслеживаемых товаров и рассылает оповещения о снижении
+func handleRequest(ctx context.Context, event events.CloudWatchEvent) error {
+	report, err := pricetrack.NewTracker(store, fetcher, notifier, options).Run(ctx)
+	if err != nil {
+		return fmt.Errorf("price tracking failed: %w", err)
+	}
+
+	log.Printf("Prices checked: %d products, %d checked, %d gone, %d failed, %d alerts, %d undelivered",
+		report.Products, report.Checked, report.Gone, report.Failed, report.Alerts, report.NotifyFailed)
+	return nil
+}
+
+func main() {
+	// Вне Lambda работает как утилита: один прогон и отчет в stdout
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		lambda.Start(handleRequest)
+		return
+	}
+
+	dryRun := flag.Bool("dry-run", false, "only log price drops, do not save history or send alerts")
+	flag.Parse()
+	options.DryRun = *dryRun
+
+	report, err := pricetrack.NewTracker(store, fetcher, notifier, options).Run(context.Background())
+	if err != nil {
+		log.Fatalf("price tracking failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
@@ -3,9 +3,17 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"strings"
 
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/auth"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/cache"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/feedback"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/marketplace"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -13,7 +21,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 )
 
-var productService *marketplace.ProductService
+var (
+	impressions    *feedback.Recorder
+	productService *marketplace.ProductService
+	handler        middleware.Handler
+)
 
 func init() {
 	// Инициализация AWS клиентов при холодном старте
@@ -23,20 +35,47 @@ func init() {
 	}
 
 	dynamoClient := dynamodb.NewFromConfig(cfg)
-	productService = marketplace.NewProductService(dynamoClient)
+
+	// Таксономия перечитывается из источника в теплой Lambda
+	store, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(store)
+
+	// Кэш создается один раз и переживает вызовы "теплой" Lambda
+	resultCache, err := cache.NewFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init cache: %v", err)
+	}
+
+	productService = marketplace.NewProductService(dynamoClient, resultCache)
+
+	// CTR категорий и магазинов из обратной связи поднимает их в выдаче
+	ranker, err := feedback.NewRankerFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init feedback ranking: %v", err)
+	}
+	if ranker != nil {
+		productService.SetRanker(ranker)
+	}
+
+	// Показы выдачи пишет сервер, по ним принимаются реакции в /feedback
+	impressions, err = feedback.NewRecorderFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init feedback store: %v", err)
+	}
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "search-products", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
 }
 
 func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	headers := map[string]string{
-		"Access-Control-Allow-Origin": "*",
-		"Content-Type":                "application/json",
-	}
-
-	if request.HTTPMethod == "OPTIONS" {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 200,
-			Headers:    headers,
-		}, nil
+		"Content-Type": "application/json",
 	}
 
 	var searchRequest types.ProductSearchRequestApi
@@ -48,10 +87,10 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 		}, nil
 	}
 
-	if len(searchRequest.Categories) == 0 {
+	if len(searchRequest.Categories) == 0 && strings.TrimSpace(searchRequest.Query) == "" && len(searchRequest.Interests) == 0 {
 		return events.APIGatewayProxyResponse{
 			StatusCode: 400,
-			Body:       `{"error":"at least one category is required"}`,
+			Body:       `{"error":"categories, query or interests are required"}`,
 			Headers:    headers,
 		}, nil
 	}
@@ -61,7 +100,50 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 		priceRange = *searchRequest.PriceRange
 	}
 
-	products, err := productService.SearchProducts(ctx, searchRequest.Categories, priceRange, searchRequest.Marketplace)
+	// Проверяем параметры страницы до поиска, чтобы не тратить запросы к источникам
+	listOptions, err := marketplace.NormalizeListOptions(marketplace.ListOptions{
+		Sort:   searchRequest.Sort,
+		Limit:  searchRequest.Limit,
+		Offset: searchRequest.Offset,
+		Cursor: searchRequest.Cursor,
+	})
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	}
+
+	result, err := productService.SearchProducts(ctx, types.SearchIntent{
+		Categories:  searchRequest.Categories,
+		Query:       searchRequest.Query,
+		Interests:   searchRequest.Interests,
+		PriceRange:  priceRange,
+		Marketplace: searchRequest.Marketplace,
+	})
+	if errors.Is(err, money.ErrUnknownCurrency) {
+		body, _ := json.Marshal(map[string]string{"error": "unsupported currency: " + priceRange.Currency})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	}
+	if errors.Is(err, marketplace.ErrAllSourcesFailed) {
+		log.Printf("All product sources failed: %+v", result.Sources)
+		body, _ := json.Marshal(types.ApiResponse{
+			Success: false,
+			Data:    types.ProductSearchResponseApi{Products: []types.Product{}, Sources: result.Sources},
+			Error:   "all product sources failed",
+		})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 502,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	}
 	if err != nil {
 		log.Printf("Failed to search products: %v", err)
 		return events.APIGatewayProxyResponse{
@@ -71,10 +153,34 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 		}, nil
 	}
 
+	page, err := productService.List(result, listOptions)
+	if err != nil {
+		log.Printf("Failed to build result page: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"failed to search products"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	impressionID, err := impressions.Shown(ctx, auth.UserID(request), "", nil, page.Products)
+	if err != nil {
+		log.Printf("Failed to record impression: %v", err)
+	}
+
 	response := types.ApiResponse{
 		Success: true,
 		Data: types.ProductSearchResponseApi{
-			Products: products,
+			Products:     page.Products,
+			Total:        page.Total,
+			Limit:        page.Limit,
+			Offset:       page.Offset,
+			NextCursor:   page.NextCursor,
+			Facets:       page.Facets,
+			Groups:       page.Groups,
+			Sources:      result.Sources,
+			Cache:        result.Cache,
+			ImpressionID: impressionID,
 		},
 	}
 
@@ -95,5 +201,5 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 }
 
 func main() {
-	lambda.Start(handleRequest)
+	lambda.Start(handler)
 }
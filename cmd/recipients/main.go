@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/auth"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recipient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Сколько раз повторять добавление подарка при параллельном изменении
+const giftRetries = 3
+
+var (
+	recipients recipient.Store
+	handler    middleware.Handler
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	// Пол и поводы проверяются по таксономии
+	store, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(store)
+
+	recipients, err = recipient.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init recipients store: %v", err)
+	}
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "recipients", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
+}
+
+// GET    /recipients              - адресная книга пользователя
+// POST   /recipients              - добавить получателя
+// GET    /recipients/{id}         - получатель
+// PUT    /recipients/{id}         - заменить профиль (version - текущая версия)
+// DELETE /recipients/{id}         - удалить получателя
+// POST   /recipients/{id}/gifts   - отметить подаренный товар
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	userID := auth.UserID(request)
+	if userID == "" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 401,
+			Body:       `{"error":"Unauthorized"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	id := request.PathParameters["id"]
+	gifts := strings.HasSuffix(request.Resource, "/gifts") || strings.HasSuffix(request.Path, "/gifts")
+
+	var (
+		data       interface{}
+		statusCode = 200
+		err        error
+	)
+	switch {
+	case id == "" && request.HTTPMethod == "GET":
+		data, err = recipients.List(ctx, userID)
+	case id == "" && request.HTTPMethod == "POST":
+		data, err = create(ctx, userID, request.Body)
+		statusCode = 201
+	case gifts && request.HTTPMethod == "POST":
+		data, err = addGift(ctx, userID, id, request.Body)
+	case id != "" && request.HTTPMethod == "GET":
+		data, err = recipients.Get(ctx, userID, id)
+	case id != "" && request.HTTPMethod == "PUT":
+		data, err = update(ctx, userID, id, request.Body)
+	case id != "" && request.HTTPMethod == "DELETE":
+		err = recipients.Delete(ctx, userID, id)
+		statusCode = 204
+	default:
+		return events.APIGatewayProxyResponse{
+			StatusCode: 405,
+			Body:       `{"error":"Method not allowed"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxError) || errors.As(err, &typeError):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       `{"error":"Invalid request body"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, recipient.ErrInvalid):
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, recipient.ErrNotFound):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 404,
+			Body:       `{"error":"recipient not found"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, recipient.ErrConflict):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 409,
+			Body:       `{"error":"recipient was modified concurrently, reload and retry"}`,
+			Headers:    headers,
+		}, nil
+	case err != nil:
+		log.Printf("Recipients request failed: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"recipients request failed"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	if statusCode == 204 {
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+		}, nil
+	}
+
+	response := types.ApiResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"Failed to marshal response"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Body:       string(responseJSON),
+		Headers:    headers,
+	}, nil
+}
+
+func create(ctx context.Context, userID, body string) (*recipient.Recipient, error) {
+	var r recipient.Recipient
+	if err := json.Unmarshal([]byte(body), &r); err != nil {
+		return nil, err
+	}
+	if err := r.Normalize(); err != nil {
+		return nil, err
+	}
+
+	id, err := recipient.NewID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	r.UserID = userID
+	r.ID = id
+	r.Version = 1
+	r.CreatedAt = now
+	r.UpdatedAt = now
+
+	if err := recipients.Put(ctx, &r, 0); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// update заменяет профиль целиком. Версия из тела защищает от затирания
+// чужих изменений; без нее запись идет поверх текущей версии. История
+// подарков сохраняется, если ее нет в теле.
+func update(ctx context.Context, userID, id, body string) (*recipient.Recipient, error) {
+	var r recipient.Recipient
+	if err := json.Unmarshal([]byte(body), &r); err != nil {
+		return nil, err
+	}
+	if err := r.Normalize(); err != nil {
+		return nil, err
+	}
+
+	current, err := recipients.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	expectedVersion := r.Version
+	if expectedVersion == 0 {
+		expectedVersion = current.Version
+	}
+
+	r.UserID = userID
+	r.ID = id
+	r.Version = expectedVersion + 1
+	r.CreatedAt = current.CreatedAt
+	if r.PastGifts == nil {
+		r.PastGifts = current.PastGifts
+	}
+	r.UpdatedAt = time.Now().UTC()
+
+	if err := recipients.Put(ctx, &r, expectedVersion); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// addGift дописывает подарок в историю; при параллельной записи
+// перечитывает получателя и повторяет
+func addGift(ctx context.Context, userID, id, body string) (*recipient.Recipient, error) {
+	var gift recipient.PastGift
+	if err := json.Unmarshal([]byte(body), &gift); err != nil {
+		return nil, err
+	}
+	if gift.ProductID == "" && gift.URL == "" {
+		return nil, fmt.Errorf("%w: product_id or url is required", recipient.ErrInvalid)
+	}
+	if gift.GivenAt.IsZero() {
+		gift.GivenAt = time.Now().UTC()
+	}
+
+	for attempt := 0; ; attempt++ {
+		r, err := recipients.Get(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+
+		expectedVersion := r.Version
+		r.PastGifts = append(r.PastGifts, gift)
+		r.Version++
+		r.UpdatedAt = time.Now().UTC()
+
+		err = recipients.Put(ctx, r, expectedVersion)
+		if errors.Is(err, recipient.ErrConflict) && attempt < giftRetries {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/auth"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/cache"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/feedback"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/marketplace"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recipient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recommend"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/summary"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/translator"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/polly"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	impressions *feedback.Recorder
+	recommender *recommend.Service
+	recipients  recipient.Store
+	handler     middleware.Handler
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	// Таксономия перечитывается из источника в теплой Lambda
+	store, err := taxonomy.NewStoreFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to load taxonomy: %v", err)
+	}
+	taxonomy.SetDefault(store)
+
+	resultCache, err := cache.NewFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init cache: %v", err)
+	}
+
+	recipients, err = recipient.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init recipients store: %v", err)
+	}
+
+	summaries, err := summary.NewFromEnv()
+	if err != nil {
+		log.Fatalf("unable to init summary generator: %v", err)
+	}
+
+	// Голосовые ответы доступны, только если задан бакет для аудио
+	var speaker recommend.Speaker
+	if bucketName := os.Getenv("AUDIO_BUCKET_NAME"); bucketName != "" {
+		speaker = translator.NewTranslator(nil, polly.NewFromConfig(cfg), s3.NewFromConfig(cfg), bucketName)
+	}
+
+	productService := marketplace.NewProductService(dynamoClient, resultCache)
+	ranker, err := feedback.NewRankerFromEnv(context.Background(), dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init feedback ranking: %v", err)
+	}
+	if ranker != nil {
+		productService.SetRanker(ranker)
+	}
+
+	// Показы выдачи пишет сервер, по ним принимаются реакции в /feedback
+	impressions, err = feedback.NewRecorderFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init feedback store: %v", err)
+	}
+
+	recommender = recommend.NewService(recommend.NewResolver(), productService, summaries, speaker)
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "recommend", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
+}
+
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var recommendRequest types.RecommendRequestApi
+	if err := json.Unmarshal([]byte(request.Body), &recommendRequest); err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       `{"error":"Invalid request body"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	giftRequest := recommendRequest.GiftRequest
+	var exclude []string
+
+	// Получатель из адресной книги: недостающие поля берем из профиля и не
+	// предлагаем уже подаренное
+	if recommendRequest.RecipientID != "" {
+		userID := auth.UserID(request)
+		if userID == "" {
+			return events.APIGatewayProxyResponse{
+				StatusCode: 401,
+				Body:       `{"error":"Unauthorized"}`,
+				Headers:    headers,
+			}, nil
+		}
+
+		r, err := recipients.Get(ctx, userID, recommendRequest.RecipientID)
+		if errors.Is(err, recipient.ErrNotFound) {
+			return events.APIGatewayProxyResponse{
+				StatusCode: 404,
+				Body:       `{"error":"recipient not found"}`,
+				Headers:    headers,
+			}, nil
+		}
+		if err != nil {
+			log.Printf("Failed to get recipient: %v", err)
+			return events.APIGatewayProxyResponse{
+				StatusCode: 500,
+				Body:       `{"error":"failed to load recipient"}`,
+				Headers:    headers,
+			}, nil
+		}
+
+		giftRequest = r.GiftRequest(giftRequest, time.Now())
+		exclude = r.GiftedProducts()
+	}
+
+	recommendation, err := recommender.Recommend(ctx, giftRequest, exclude)
+	if errors.Is(err, money.ErrUnknownCurrency) {
+		body, _ := json.Marshal(map[string]string{"error": "unsupported currency: " + giftRequest.PriceRange.Currency})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	}
+	if errors.Is(err, recommend.ErrUnknownOccasion) || errors.Is(err, recommend.ErrInvalidAge) {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	}
+	if err != nil {
+		log.Printf("Failed to recommend gifts: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"failed to recommend gifts"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	recommendation.ImpressionID, err = impressions.Shown(ctx, auth.UserID(request), "", &giftRequest, recommendation.Products)
+	if err != nil {
+		log.Printf("Failed to record impression: %v", err)
+	}
+
+	response := types.ApiResponse{
+		Success: true,
+		Data: types.RecommendResponseApi{
+			Request:        giftRequest,
+			Recommendation: *recommendation,
+		},
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"Failed to marshal response"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       string(responseJSON),
+		Headers:    headers,
+	}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -6,16 +6,21 @@ import (
 	"log"
 	"os"
 
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/translator"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/polly"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
-var speechService *translator.Translator
+var (
+	speechService *translator.Translator
+	handler       middleware.Handler
+)
 
 func init() {
 	// Инициализация AWS клиентов при холодном старте
@@ -24,6 +29,8 @@ func init() {
 		log.Fatalf("unable to load SDK config: %v", err)
 	}
 
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
 	pollyClient := polly.NewFromConfig(cfg)
 	s3Client := s3.NewFromConfig(cfg)
 
@@ -34,19 +41,17 @@ func init() {
 	}
 
 	speechService = translator.NewTranslator(nil, pollyClient, s3Client, bucketName)
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "text-to-speech", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
 }
 
 func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	headers := map[string]string{
-		"Access-Control-Allow-Origin": "*",
-		"Content-Type":                "application/json",
-	}
-
-	if request.HTTPMethod == "OPTIONS" {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 200,
-			Headers:    headers,
-		}, nil
+		"Content-Type": "application/json",
 	}
 
 	var speechRequest types.SpeechRequestApi
@@ -109,5 +114,5 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 }
 
 func main() {
-	lambda.Start(handleRequest)
+	lambda.Start(handler)
 }
@@ -6,15 +6,20 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/translator"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/translate"
 )
 
-var translatorService *translator.Translator
+var (
+	translatorService *translator.Translator
+	handler           middleware.Handler
+)
 
 func init() {
 	// Инициализация AWS клиентов при холодном старте
@@ -23,14 +28,21 @@ func init() {
 		log.Fatalf("unable to load SDK config: %v", err)
 	}
 
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
 	translateClient := translate.NewFromConfig(cfg)
 	translatorService = translator.NewTranslator(translateClient, nil, nil, "")
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "translate", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
 }
 
 func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	headers := map[string]string{
-		"Access-Control-Allow-Origin": "*",
-		"Content-Type":                "application/json",
+		"Content-Type": "application/json",
 	}
 
 	// Подробное логирование запроса
@@ -44,13 +56,6 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 	log.Printf("IsBase64Encoded: %v", request.IsBase64Encoded)
 	log.Printf("=== END REQUEST DEBUGGING ===")
 
-	if request.HTTPMethod == "OPTIONS" {
-		return events.APIGatewayProxyResponse{
-			StatusCode: 200,
-			Headers:    headers,
-		}, nil
-	}
-
 	// Проверяем, что тело запроса не пустое
 	if request.Body == "" {
 		log.Printf("Empty request body received")
@@ -140,5 +145,5 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 }
 
 func main() {
-	lambda.Start(handleRequest)
+	lambda.Start(handler)
 }
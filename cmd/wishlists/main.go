@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/auth"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/middleware"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/wishlist"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+var (
+	wishlists *wishlist.Service
+	handler   middleware.Handler
+)
+
+func init() {
+	// Инициализация AWS клиентов при холодном старте
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		log.Fatalf("unable to load SDK config: %v", err)
+	}
+
+	dynamoClient := dynamodb.NewFromConfig(cfg)
+
+	store, err := wishlist.NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		log.Fatalf("unable to init wishlist store: %v", err)
+	}
+	wishlists = wishlist.NewService(store)
+
+	// Ограничение частоты запросов и CORS
+	handler, err = middleware.Wrap(dynamoClient, "wishlists", handleRequest)
+	if err != nil {
+		log.Fatalf("unable to init middleware: %v", err)
+	}
+}
+
+// GET    /wishlists                                 - списки пользователя
+// POST   /wishlists                                 - создать список
+// GET    /wishlists/{id}                            - список
+// PUT    /wishlists/{id}                            - переименовать (version - текущая версия)
+// DELETE /wishlists/{id}                            - удалить список
+// POST   /wishlists/{id}/items                      - добавить товар
+// DELETE /wishlists/{id}/items/{item_id}            - убрать товар
+// POST   /wishlists/{id}/share                      - создать или заменить публичную ссылку
+// DELETE /wishlists/{id}/share                      - отозвать ссылку
+// GET    /wishlists/{id}/export?format=json|csv     - выгрузка
+// GET    /shared/{token}                            - список по ссылке, без авторизации
+// POST   /shared/{token}/items/{item_id}/reserve    - зарезервировать подарок
+// DELETE /shared/{token}/items/{item_id}/reserve    - снять резерв (claim_id в query)
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var (
+		data       interface{}
+		statusCode = 200
+		err        error
+	)
+	if token := request.PathParameters["token"]; token != "" {
+		data, statusCode, err = handleShared(ctx, request, token)
+	} else {
+		userID := auth.UserID(request)
+		if userID == "" {
+			return events.APIGatewayProxyResponse{
+				StatusCode: 401,
+				Body:       `{"error":"Unauthorized"}`,
+				Headers:    headers,
+			}, nil
+		}
+
+		if hasSuffix(request, "/export") && request.HTTPMethod == "GET" {
+			return export(ctx, userID, request, headers), nil
+		}
+		data, statusCode, err = handleOwner(ctx, request, userID)
+	}
+
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxError) || errors.As(err, &typeError):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       `{"error":"Invalid request body"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, wishlist.ErrInvalid):
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, wishlist.ErrNotFound), errors.Is(err, wishlist.ErrItemNotFound):
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return events.APIGatewayProxyResponse{
+			StatusCode: 404,
+			Body:       string(body),
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, wishlist.ErrReserved):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 409,
+			Body:       `{"error":"item is reserved by someone else"}`,
+			Headers:    headers,
+		}, nil
+	case errors.Is(err, wishlist.ErrConflict):
+		return events.APIGatewayProxyResponse{
+			StatusCode: 409,
+			Body:       `{"error":"wishlist was modified concurrently, reload and retry"}`,
+			Headers:    headers,
+		}, nil
+	case err != nil:
+		log.Printf("Wishlists request failed: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"wishlists request failed"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	if statusCode == 405 {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 405,
+			Body:       `{"error":"Method not allowed"}`,
+			Headers:    headers,
+		}, nil
+	}
+	if statusCode == 204 {
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+		}, nil
+	}
+
+	response := types.ApiResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"Failed to marshal response"}`,
+			Headers:    headers,
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Body:       string(responseJSON),
+		Headers:    headers,
+	}, nil
+}
+
+// handleOwner - запросы владельца списков
+func handleOwner(ctx context.Context, request events.APIGatewayProxyRequest, userID string) (interface{}, int, error) {
+	id := request.PathParameters["id"]
+	itemID := request.PathParameters["item_id"]
+	// Сам список, а не его товары или ссылка
+	listOnly := id != "" && itemID == "" && !hasSuffix(request, "/items") && !hasSuffix(request, "/share")
+
+	var (
+		list       *wishlist.Wishlist
+		statusCode = 200
+		err        error
+	)
+	switch {
+	case id == "" && request.HTTPMethod == "GET":
+		lists, err := wishlists.List(ctx, userID)
+		if err != nil {
+			return nil, 0, err
+		}
+		views := make([]wishlist.View, len(lists))
+		for i := range lists {
+			views[i] = lists[i].OwnerView()
+		}
+		return views, 200, nil
+	case id == "" && request.HTTPMethod == "POST":
+		var body types.WishlistRequestApi
+		if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+			return nil, 0, err
+		}
+		list, err = wishlists.Create(ctx, userID, body.Name)
+		statusCode = 201
+	case hasSuffix(request, "/share") && request.HTTPMethod == "POST":
+		list, err = wishlists.Share(ctx, userID, id)
+	case hasSuffix(request, "/share") && request.HTTPMethod == "DELETE":
+		list, err = wishlists.Unshare(ctx, userID, id)
+	case hasSuffix(request, "/items") && request.HTTPMethod == "POST":
+		var body types.WishlistItemRequestApi
+		if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+			return nil, 0, err
+		}
+		list, err = wishlists.AddItem(ctx, userID, id, body.Product, body.Note)
+	case itemID != "" && request.HTTPMethod == "DELETE":
+		list, err = wishlists.RemoveItem(ctx, userID, id, itemID)
+	case listOnly && request.HTTPMethod == "GET":
+		list, err = wishlists.Get(ctx, userID, id)
+	case listOnly && request.HTTPMethod == "PUT":
+		var body types.WishlistRequestApi
+		if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+			return nil, 0, err
+		}
+		list, err = wishlists.Rename(ctx, userID, id, body.Name, body.Version)
+	case listOnly && request.HTTPMethod == "DELETE":
+		return nil, 204, wishlists.Delete(ctx, userID, id)
+	default:
+		return nil, 405, nil
+	}
+
+	if err != nil {
+		return nil, 0, err
+	}
+	return list.OwnerView(), statusCode, nil
+}
+
+// handleShared - публичные запросы по ссылке
+func handleShared(ctx context.Context, request events.APIGatewayProxyRequest, token string) (interface{}, int, error) {
+	itemID := request.PathParameters["item_id"]
+
+	switch {
+	case itemID == "" && request.HTTPMethod == "GET":
+		return publicView(wishlists.Shared(ctx, token))
+	case itemID != "" && request.HTTPMethod == "POST":
+		var body types.ReserveRequestApi
+		if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+			return nil, 0, err
+		}
+		claimID, err := wishlists.Reserve(ctx, token, itemID, body.Name)
+		if err != nil {
+			return nil, 0, err
+		}
+		return types.ReserveResponseApi{ClaimID: claimID}, 201, nil
+	case itemID != "" && request.HTTPMethod == "DELETE":
+		return publicView(wishlists.CancelReservation(ctx, token, itemID, request.QueryStringParameters["claim_id"]))
+	}
+	return nil, 405, nil
+}
+
+// export отдает список файлом, а не в обертке ApiResponse
+func export(ctx context.Context, userID string, request events.APIGatewayProxyRequest, headers map[string]string) events.APIGatewayProxyResponse {
+	format := strings.ToLower(request.QueryStringParameters["format"])
+	if format == "" {
+		format = wishlist.FormatJSON
+	}
+	if format != wishlist.FormatJSON && format != wishlist.FormatCSV {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 400,
+			Body:       `{"error":"format must be json or csv"}`,
+			Headers:    headers,
+		}
+	}
+
+	list, err := wishlists.Get(ctx, userID, request.PathParameters["id"])
+	if errors.Is(err, wishlist.ErrNotFound) {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 404,
+			Body:       `{"error":"wishlist not found"}`,
+			Headers:    headers,
+		}
+	}
+	var buf bytes.Buffer
+	if err == nil {
+		err = wishlist.Export(&buf, list, format)
+	}
+	if err != nil {
+		log.Printf("Failed to export wishlist: %v", err)
+		return events.APIGatewayProxyResponse{
+			StatusCode: 500,
+			Body:       `{"error":"failed to export wishlist"}`,
+			Headers:    headers,
+		}
+	}
+
+	headers["Content-Type"] = wishlist.ContentType(format)
+	headers["Content-Disposition"] = fmt.Sprintf(`attachment; filename="wishlist-%s.%s"`, list.ID, format)
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Body:       buf.String(),
+		Headers:    headers,
+	}
+}
+
+func publicView(list *wishlist.Wishlist, err error) (interface{}, int, error) {
+	if err != nil {
+		return nil, 0, err
+	}
+	return list.PublicView(), 200, nil
+}
+
+func hasSuffix(request events.APIGatewayProxyRequest, suffix string) bool {
+	return strings.HasSuffix(request.Resource, suffix) || strings.HasSuffix(request.Path, suffix)
+}
+
+func main() {
+	lambda.Start(handler)
+}
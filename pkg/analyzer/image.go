@@ -122,21 +122,3 @@ func (a *ImageAnalyzer) downloadImage(url string) ([]byte, error) {
 	log.Printf("Successfully downloaded image, size: %d bytes", len(imageBytes))
 	return imageBytes, nil
 }
-
-// Маппинг меток Rekognition на категории подарков
-var LabelCategories = map[string][]string{
-	"Sports":      {"sports"},
-	"Electronics": {"electronics"},
-	"Book":        {"books"},
-	"Game":        {"toys", "electronics"},
-	"Pet":         {"home"},
-	"Music":       {"electronics"},
-	"Art":         {"home"},
-	"Food":        {"home"},
-	"Fashion":     {"beauty"},
-	"Technology":  {"electronics"},
-	"Fitness":     {"sports"},
-	"Baby":        {"toys"},
-	"Garden":      {"home"},
-	"Beauty":      {"beauty"},
-}
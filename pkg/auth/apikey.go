@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/quota"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Ключи выглядят как gft_<id>_<секрет>; в таблице хранится только хеш
+const keyPrefix = "gft_"
+
+var ErrKeyNotFound = errors.New("api key not found")
+
+// APIKey - ключ доступа к API. Лимиты 0 означают лимиты по умолчанию из
+// API_KEY_DAILY_LIMIT и API_KEY_MONTHLY_LIMIT.
+type APIKey struct {
+	Hash         string    `dynamodbav:"key_hash" json:"-"`
+	ID           string    `dynamodbav:"key_id" json:"id"`
+	UserID       string    `dynamodbav:"user_id" json:"user_id"` // Владелец; запросы с ключом идут от его имени
+	Name         string    `dynamodbav:"name,omitempty" json:"name,omitempty"`
+	Email        string    `dynamodbav:"email,omitempty" json:"email,omitempty"` // Адрес владельца, указанный при выдаче ключа
+	DailyLimit   int64     `dynamodbav:"daily_limit,omitempty" json:"daily_limit,omitempty"`
+	MonthlyLimit int64     `dynamodbav:"monthly_limit,omitempty" json:"monthly_limit,omitempty"`
+	Disabled     bool      `dynamodbav:"disabled" json:"disabled"`
+	CreatedAt    time.Time `dynamodbav:"created_at" json:"created_at"`
+}
+
+// Limits возвращает лимиты ключа поверх лимитов по умолчанию
+func (k *APIKey) Limits(defaults quota.Limits) quota.Limits {
+	limits := defaults
+	if k.DailyLimit > 0 {
+		limits.Daily = k.DailyLimit
+	}
+	if k.MonthlyLimit > 0 {
+		limits.Monthly = k.MonthlyLimit
+	}
+	return limits
+}
+
+// GenerateKey создает ключ для пользователя. Открытое значение возвращается
+// один раз и нигде не сохраняется.
+func GenerateKey(userID, name string) (string, *APIKey, error) {
+	id := make([]byte, 6)
+	secret := make([]byte, 24)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	keyID := hex.EncodeToString(id)
+	plain := keyPrefix + keyID + "_" + hex.EncodeToString(secret)
+	return plain, &APIKey{
+		Hash:      HashKey(plain),
+		ID:        keyID,
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// HashKey возвращает SHA-256 ключа в hex - по нему ключ ищется в таблице
+func HashKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyStore хранит ключи по хешу
+type KeyStore interface {
+	Get(ctx context.Context, hash string) (*APIKey, error)
+	Put(ctx context.Context, key *APIKey) error
+}
+
+// NewKeyStoreFromEnv выбирает хранилище по API_KEYS_BACKEND: dynamodb (по
+// умолчанию, таблица API_KEYS_TABLE) или memory для локального запуска
+func NewKeyStoreFromEnv(dynamoClient *dynamodb.Client) (KeyStore, error) {
+	switch backend := os.Getenv("API_KEYS_BACKEND"); backend {
+	case "", "dynamodb":
+		table := os.Getenv("API_KEYS_TABLE")
+		if table == "" {
+			table = "api_keys"
+		}
+		return NewDynamoKeyStore(dynamoClient, table), nil
+	case "memory":
+		return NewMemoryKeyStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown API_KEYS_BACKEND: %s", backend)
+	}
+}
+
+// DynamoKeyStore хранит ключи в таблице с ключом key_hash
+type DynamoKeyStore struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewDynamoKeyStore(client *dynamodb.Client, tableName string) *DynamoKeyStore {
+	return &DynamoKeyStore{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (s *DynamoKeyStore) Get(ctx context.Context, hash string) (*APIKey, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]dyntypes.AttributeValue{
+			"key_hash": &dyntypes.AttributeValueMemberS{Value: hash},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if result.Item == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	var key APIKey
+	if err := attributevalue.UnmarshalMap(result.Item, &key); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal api key: %w", err)
+	}
+	return &key, nil
+}
+
+func (s *DynamoKeyStore) Put(ctx context.Context, key *APIKey) error {
+	item, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal api key: %w", err)
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put api key: %w", err)
+	}
+	return nil
+}
+
+// MemoryKeyStore хранит ключи в памяти процесса - для локального запуска
+type MemoryKeyStore struct {
+	mu   sync.Mutex
+	keys map[string]APIKey
+}
+
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{keys: make(map[string]APIKey)}
+}
+
+func (s *MemoryKeyStore) Get(ctx context.Context, hash string) (*APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[hash]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return &key, nil
+}
+
+func (s *MemoryKeyStore) Put(ctx context.Context, key *APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key.Hash] = *key
+	return nil
+}
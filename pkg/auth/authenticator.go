@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/quota"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Способы аутентификации
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+var (
+	// ErrUnauthorized - нет учетных данных, ключ неизвестен или токен неверный
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden - ключ отключен
+	ErrForbidden = errors.New("api key is disabled")
+	// ErrQuotaExceeded - дневная или месячная квота ключа исчерпана
+	ErrQuotaExceeded = errors.New("api key quota exceeded")
+)
+
+// Principal - кто выполняет запрос
+type Principal struct {
+	UserID string
+	Method string
+	KeyID  string       // Только для ключей
+	Email  string       // Подтвержденный адрес пользователя; пусто, если его нет
+	Usage  *quota.Usage // Использование квоты ключа, если квоты считаются
+}
+
+// Authenticator проверяет ключи API с квотами и токены JWT
+type Authenticator struct {
+	keys     KeyStore
+	jwt      *JWTVerifier     // nil - JWT не принимаются
+	dynamo   *dynamodb.Client // nil - квоты ключей не считаются
+	defaults quota.Limits     // Лимиты ключей без своих лимитов
+}
+
+func NewAuthenticator(keys KeyStore, jwt *JWTVerifier, dynamoClient *dynamodb.Client, defaults quota.Limits) *Authenticator {
+	return &Authenticator{
+		keys:     keys,
+		jwt:      jwt,
+		dynamo:   dynamoClient,
+		defaults: defaults,
+	}
+}
+
+// Credentials достает ключ из X-Api-Key и токен из Authorization: Bearer.
+// Имена заголовков сравниваются без учета регистра.
+func Credentials(headers map[string]string) (apiKey, token string) {
+	for name, value := range headers {
+		switch {
+		case strings.EqualFold(name, "X-Api-Key"):
+			apiKey = strings.TrimSpace(value)
+		case strings.EqualFold(name, "Authorization"):
+			if scheme, rest, ok := strings.Cut(strings.TrimSpace(value), " "); ok && strings.EqualFold(scheme, "Bearer") {
+				token = strings.TrimSpace(rest)
+			}
+		}
+	}
+	return apiKey, token
+}
+
+// Authenticate проверяет учетные данные из заголовков; ключ API важнее
+// токена, если переданы оба. При ErrForbidden и ErrQuotaExceeded
+// возвращается и Principal - чтобы записать, чей ключ отклонен.
+func (a *Authenticator) Authenticate(ctx context.Context, headers map[string]string) (*Principal, error) {
+	apiKey, token := Credentials(headers)
+	switch {
+	case apiKey != "":
+		return a.authenticateKey(ctx, apiKey)
+	case token != "" && a.jwt != nil:
+		claims, err := a.jwt.Verify(ctx, token)
+		if errors.Is(err, ErrInvalidToken) {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		principal := &Principal{UserID: claims.Subject, Method: MethodJWT}
+		if claims.EmailVerified {
+			principal.Email = claims.Email
+		}
+		return principal, nil
+	default:
+		return nil, ErrUnauthorized
+	}
+}
+
+// authenticateKey находит ключ по хешу и списывает один запрос из его квоты
+func (a *Authenticator) authenticateKey(ctx context.Context, plain string) (*Principal, error) {
+	if !strings.HasPrefix(plain, keyPrefix) {
+		return nil, ErrUnauthorized
+	}
+	key, err := a.keys.Get(ctx, HashKey(plain))
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	principal := &Principal{UserID: key.UserID, Method: MethodAPIKey, KeyID: key.ID, Email: key.Email}
+	if key.Disabled {
+		return principal, ErrForbidden
+	}
+	if a.dynamo == nil {
+		return principal, nil
+	}
+
+	tracker := quota.NewTracker(a.dynamo, quota.TableName(), "apikey#"+key.ID, key.Limits(a.defaults))
+	usage, err := tracker.Consume(ctx)
+	if errors.Is(err, quota.ErrExhausted) {
+		principal.Usage = &usage
+		return principal, ErrQuotaExceeded
+	}
+	if err != nil {
+		return nil, err
+	}
+	principal.Usage = &usage
+	return principal, nil
+}
@@ -0,0 +1,416 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+)
+
+const (
+	// Допустимое расхождение часов с издателем токена
+	clockLeeway = time.Minute
+	// Неизвестный kid перечитывает JWKS не чаще этого интервала
+	jwksMinRefresh = time.Minute
+	// Сколько читать ответ JWKS
+	jwksMaxBytes = 1 << 20
+)
+
+// ErrInvalidToken - токен не разобран, подпись не сошлась или истек срок
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims - поля JWT, которые нужны сервису
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	IssuedAt  int64    `json:"iat"`
+	Email     string   `json:"email"`
+	// Адрес подтвержден провайдером; без этого письма на него не отправляются
+	EmailVerified claimBool `json:"email_verified"`
+}
+
+// claimBool - логическое поле, которое часть провайдеров передает строкой
+type claimBool bool
+
+func (b *claimBool) UnmarshalJSON(data []byte) error {
+	var value bool
+	if err := json.Unmarshal(data, &value); err == nil {
+		*b = claimBool(value)
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*b = claimBool(text == "true")
+	return nil
+}
+
+// audience в JWT бывает строкой или массивом строк
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// KeySource возвращает открытый ключ по kid из заголовка токена
+type KeySource interface {
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// JWTVerifier проверяет токены RS256: подпись, срок, издателя и аудиторию
+type JWTVerifier struct {
+	keys     KeySource
+	issuer   string // Пусто - издатель не проверяется
+	audience string // Пусто - аудитория не проверяется
+	now      func() time.Time
+}
+
+func NewJWTVerifier(keys KeySource, issuer, audience string) *JWTVerifier {
+	return &JWTVerifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}
+}
+
+// NewJWTVerifierFromEnv читает JWT_ISSUER, JWT_AUDIENCE и источник ключей:
+// JWT_PUBLIC_KEY_FILE (PEM или JWKS, для тестов и локального запуска) или
+// JWT_JWKS_URL (по умолчанию <JWT_ISSUER>/.well-known/jwks.json). Если не
+// задан ни издатель, ни файл ключа, возвращает nil - JWT не принимаются.
+// С издателем или JWKS обязательны и издатель, и аудитория: иначе подошел
+// бы любой токен того же провайдера, выданный для другого приложения.
+func NewJWTVerifierFromEnv() (*JWTVerifier, error) {
+	issuer := strings.TrimSuffix(os.Getenv("JWT_ISSUER"), "/")
+	audience := os.Getenv("JWT_AUDIENCE")
+	keyFile := os.Getenv("JWT_PUBLIC_KEY_FILE")
+	jwksURL := os.Getenv("JWT_JWKS_URL")
+	if jwksURL != "" && issuer == "" {
+		return nil, errors.New("JWT_ISSUER is required with JWT_JWKS_URL")
+	}
+	if issuer != "" && audience == "" {
+		return nil, errors.New("JWT_AUDIENCE is required with JWT_ISSUER")
+	}
+
+	var keys KeySource
+	switch {
+	case keyFile != "":
+		static, err := LoadKeyFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = static
+	case jwksURL != "" || issuer != "":
+		if jwksURL == "" {
+			jwksURL = issuer + "/.well-known/jwks.json"
+		}
+		ttl := time.Hour
+		if v := os.Getenv("JWT_JWKS_CACHE_TTL"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid JWT_JWKS_CACHE_TTL: %q", v)
+			}
+			ttl = d
+		}
+		keys = NewJWKS(jwksURL, httpclient.Default(), ttl)
+	default:
+		return nil, nil
+	}
+
+	return NewJWTVerifier(keys, issuer, audience), nil
+}
+
+// Verify проверяет токен и возвращает его поля. Принимается только RS256:
+// токены с alg none или HS256 отклоняются.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: bad header: %v", ErrInvalidToken, err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad signature encoding", ErrInvalidToken)
+	}
+	key, err := v.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: bad claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.validate(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (v *JWTVerifier) validate(claims *Claims) error {
+	now := v.now()
+	switch {
+	case claims.Subject == "":
+		return fmt.Errorf("%w: missing sub", ErrInvalidToken)
+	case claims.ExpiresAt == 0:
+		return fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	case now.After(time.Unix(claims.ExpiresAt, 0).Add(clockLeeway)):
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	case claims.NotBefore != 0 && now.Add(clockLeeway).Before(time.Unix(claims.NotBefore, 0)):
+		return fmt.Errorf("%w: token not valid yet", ErrInvalidToken)
+	case v.issuer != "" && strings.TrimSuffix(claims.Issuer, "/") != v.issuer:
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+
+	if v.audience != "" {
+		for _, aud := range claims.Audience {
+			if aud == v.audience {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// StaticKeys - ключи по kid. Ключ без kid (из PEM) подходит любому токену,
+// токен без kid проверяется единственным ключом набора.
+type StaticKeys map[string]*rsa.PublicKey
+
+func (k StaticKeys) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if key, ok := k[kid]; ok {
+		return key, nil
+	}
+	if key, ok := k[""]; ok {
+		return key, nil
+	}
+	if kid == "" && len(k) == 1 {
+		for _, key := range k {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, kid)
+}
+
+// LoadKeyFile читает открытый ключ RSA в PEM (PUBLIC KEY, RSA PUBLIC KEY
+// или CERTIFICATE) либо набор ключей в формате JWKS
+func LoadKeyFile(path string) (StaticKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key file: %w", err)
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		return parseJWKS(data)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("JWT key file %s is neither PEM nor JWKS", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in JWT key file", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWT key file: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("JWT key file %s does not contain an RSA key", path)
+	}
+	return StaticKeys{"": rsaKey}, nil
+}
+
+// parseJWKS разбирает набор ключей; ключи, кроме RSA для подписи, пропускаются
+func parseJWKS(data []byte) (StaticKeys, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(StaticKeys)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Alg != "" && jwk.Alg != "RS256") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) > 4 {
+			continue
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no RSA signing keys")
+	}
+	return keys, nil
+}
+
+// JWKS загружает ключи издателя и кэширует их. Неизвестный kid (ротация
+// ключей) перечитывает набор, но не чаще раза в минуту.
+type JWKS struct {
+	url    string
+	client httpclient.Doer
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	keys      StaticKeys
+	fetchedAt time.Time
+	triedAt   time.Time
+	now       func() time.Time
+}
+
+func NewJWKS(url string, client httpclient.Doer, ttl time.Duration) *JWKS {
+	return &JWKS{
+		url:    url,
+		client: client,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+func (j *JWKS) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.keys == nil || j.now().Sub(j.fetchedAt) > j.ttl {
+		if err := j.tryRefresh(ctx); err != nil {
+			// Устаревшие ключи лучше, чем отказ всем пользователям
+			if j.keys == nil {
+				return nil, err
+			}
+			log.Printf("Failed to refresh JWKS, using cached keys: %v", err)
+		}
+	}
+
+	key, err := j.keys.Key(ctx, kid)
+	if err != nil {
+		if refreshErr := j.tryRefresh(ctx); refreshErr != nil {
+			log.Printf("Failed to refresh JWKS: %v", refreshErr)
+			return nil, err
+		}
+		return j.keys.Key(ctx, kid)
+	}
+	return key, nil
+}
+
+// tryRefresh перечитывает набор, если с прошлой попытки прошло не меньше
+// jwksMinRefresh; вызывается под j.mu
+func (j *JWKS) tryRefresh(ctx context.Context) error {
+	now := j.now()
+	if !j.triedAt.IsZero() && now.Sub(j.triedAt) < jwksMinRefresh {
+		return nil
+	}
+	j.triedAt = now
+	return j.refresh(ctx)
+}
+
+// refresh загружает набор ключей; вызывается под j.mu
+func (j *JWKS) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS request failed with status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, jwksMaxBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS: %w", err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+	j.keys = keys
+	j.fetchedAt = j.now()
+	return nil
+}
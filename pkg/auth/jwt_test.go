@@ -0,0 +1,32 @@
+package auth
+
+import "testing"
+
+func TestNewJWTVerifierFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		wantErr  bool
+		disabled bool
+	}{
+		{name: "not configured", disabled: true},
+		{name: "issuer and audience", env: map[string]string{"JWT_ISSUER": "https://auth.example.com", "JWT_AUDIENCE": "gift-api"}},
+		{name: "issuer without audience", env: map[string]string{"JWT_ISSUER": "https://auth.example.com"}, wantErr: true},
+		{name: "jwks without issuer", env: map[string]string{"JWT_JWKS_URL": "https://auth.example.com/jwks.json", "JWT_AUDIENCE": "gift-api"}, wantErr: true},
+		{name: "jwks without audience", env: map[string]string{"JWT_JWKS_URL": "https://auth.example.com/jwks.json", "JWT_ISSUER": "https://auth.example.com"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"JWT_ISSUER", "JWT_AUDIENCE", "JWT_JWKS_URL", "JWT_PUBLIC_KEY_FILE", "JWT_JWKS_CACHE_TTL"} {
+				t.Setenv(name, tt.env[name])
+			}
+			verifier, err := NewJWTVerifierFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewJWTVerifierFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (verifier == nil) != tt.disabled {
+				t.Errorf("verifier = %v, disabled %v", verifier, tt.disabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// UserID возвращает пользователя из principalId авторизатора API Gateway
+// (cmd/authorizer: владелец ключа API или sub из JWT).
+// Заголовок X-User-Id принимается только при ALLOW_USER_ID_HEADER=true -
+// для локального запуска без авторизатора.
+func UserID(request events.APIGatewayProxyRequest) string {
+	if principal, ok := request.RequestContext.Authorizer["principalId"].(string); ok && principal != "" {
+		return principal
+	}
+	if os.Getenv("ALLOW_USER_ID_HEADER") != "true" {
+		return ""
+	}
+	for _, name := range []string{"X-User-Id", "x-user-id"} {
+		if userID := request.Headers[name]; userID != "" {
+			return userID
+		}
+	}
+	return ""
+}
+
+// Email возвращает подтвержденный адрес пользователя из контекста
+// авторизатора: email_verified из JWT или адрес, указанный при выдаче ключа
+// API. Письма отправляются только на него - адрес из тела запроса не
+// принимается. Локально без авторизатора адрес берется из X-User-Email при
+// ALLOW_USER_ID_HEADER=true.
+func Email(request events.APIGatewayProxyRequest) string {
+	if email, ok := request.RequestContext.Authorizer["email"].(string); ok && email != "" {
+		return email
+	}
+	if os.Getenv("ALLOW_USER_ID_HEADER") != "true" {
+		return ""
+	}
+	for _, name := range []string{"X-User-Email", "x-user-email"} {
+		if email := request.Headers[name]; email != "" {
+			return email
+		}
+	}
+	return ""
+}
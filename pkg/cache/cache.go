@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Cache хранит сериализованные значения с ограниченным временем жизни
+type Cache interface {
+	// Get возвращает значение и признак попадания в кэш
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set сохраняет значение на время ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// NewFromEnv создает кэш по переменным окружения:
+// CACHE_BACKEND = memory (по умолчанию) | dynamodb | tiered | none,
+// CACHE_SIZE - емкость LRU, CACHE_TABLE - таблица DynamoDB с TTL.
+func NewFromEnv(dynamoClient *dynamodb.Client) (Cache, error) {
+	size := 512
+	if v := os.Getenv("CACHE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid CACHE_SIZE: %q", v)
+		}
+		size = n
+	}
+
+	tableName := os.Getenv("CACHE_TABLE")
+	if tableName == "" {
+		tableName = "search_cache" // значение по умолчанию
+	}
+
+	backend := os.Getenv("CACHE_BACKEND")
+	switch backend {
+	case "", "memory":
+		return NewLRU(size), nil
+	case "dynamodb":
+		return NewDynamoCache(dynamoClient, tableName), nil
+	case "tiered":
+		return NewTiered(NewLRU(size), NewDynamoCache(dynamoClient, tableName)), nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported CACHE_BACKEND: %s", backend)
+	}
+}
+
+// Tiered читает сначала из быстрого уровня, затем из медленного,
+// прогревая быстрый уровень при попадании в медленный.
+type Tiered struct {
+	front Cache
+	back  Cache
+}
+
+func NewTiered(front, back Cache) *Tiered {
+	return &Tiered{front: front, back: back}
+}
+
+func (t *Tiered) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if value, ok, err := t.front.Get(ctx, key); err == nil && ok {
+		return value, true, nil
+	}
+
+	value, ok, err := t.back.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	// Срок жизни в медленном уровне неизвестен, поэтому держим копию недолго
+	if err := t.front.Set(ctx, key, value, time.Minute); err != nil {
+		log.Printf("Failed to warm front cache: %v", err)
+	}
+	return value, true, nil
+}
+
+func (t *Tiered) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := t.front.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return t.back.Set(ctx, key, value, ttl)
+}
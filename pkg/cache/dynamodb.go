@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoCache хранит записи в таблице DynamoDB с включенным TTL по атрибуту
+// expires_at, поэтому кэш общий для всех экземпляров Lambda.
+type DynamoCache struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewDynamoCache(client *dynamodb.Client, tableName string) *DynamoCache {
+	return &DynamoCache{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (c *DynamoCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	result, err := c.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(c.tableName),
+		Key: map[string]dyntypes.AttributeValue{
+			"cache_key": &dyntypes.AttributeValueMemberS{Value: key},
+		},
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get cache item: %w", err)
+	}
+	if result.Item == nil {
+		return nil, false, nil
+	}
+
+	// DynamoDB удаляет просроченные записи с задержкой, проверяем срок сами
+	expiresAttr, ok := result.Item["expires_at"].(*dyntypes.AttributeValueMemberN)
+	if !ok {
+		return nil, false, nil
+	}
+	expiresAt, err := strconv.ParseInt(expiresAttr.Value, 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return nil, false, nil
+	}
+
+	payload, ok := result.Item["payload"].(*dyntypes.AttributeValueMemberB)
+	if !ok {
+		return nil, false, nil
+	}
+
+	return payload.Value, true, nil
+}
+
+func (c *DynamoCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	expiresAt := time.Now().Add(ttl).Unix()
+
+	_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(c.tableName),
+		Item: map[string]dyntypes.AttributeValue{
+			"cache_key":  &dyntypes.AttributeValueMemberS{Value: key},
+			"payload":    &dyntypes.AttributeValueMemberB{Value: value},
+			"expires_at": &dyntypes.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put cache item: %w", err)
+	}
+
+	return nil
+}
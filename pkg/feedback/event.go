@@ -0,0 +1,69 @@
+package feedback
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Типы событий обратной связи
+const (
+	EventShown     = "shown"
+	EventClicked   = "clicked"
+	EventLiked     = "liked"
+	EventDismissed = "dismissed"
+	EventPurchased = "purchased"
+)
+
+var eventTypes = map[string]bool{
+	EventShown:     true,
+	EventClicked:   true,
+	EventLiked:     true,
+	EventDismissed: true,
+	EventPurchased: true,
+}
+
+// ErrInvalidEvent возвращается для события без товара или с неизвестным типом
+var ErrInvalidEvent = errors.New("invalid feedback event")
+
+// Event - показ рекомендованного товара или реакция пользователя на него
+type Event struct {
+	ID           string             `dynamodbav:"event_id" json:"event_id"`
+	ImpressionID string             `dynamodbav:"impression_id,omitempty" json:"impression_id,omitempty"` // Выдача, в которой показан товар
+	Type         string             `dynamodbav:"type" json:"type"`
+	ProductID    string             `dynamodbav:"product_id" json:"product_id"`
+	Category     string             `dynamodbav:"category,omitempty" json:"category,omitempty"`
+	Store        string             `dynamodbav:"store,omitempty" json:"store,omitempty"`
+	Position     int                `dynamodbav:"position,omitempty" json:"position,omitempty"`     // Место товара в выдаче, с 1
+	SessionID    string             `dynamodbav:"session_id,omitempty" json:"session_id,omitempty"` // Сессия советника, если есть
+	Request      *types.GiftRequest `dynamodbav:"request,omitempty" json:"request,omitempty"`       // Запрос, по которому товар был показан
+	CreatedAt    time.Time          `dynamodbav:"created_at" json:"created_at"`
+	ExpiresAt    int64              `dynamodbav:"expires_at" json:"-"` // TTL DynamoDB, unix-время
+}
+
+// Validate проверяет тип события и наличие товара
+func (e Event) Validate() error {
+	if !eventTypes[e.Type] {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, e.Type)
+	}
+	if e.ProductID == "" {
+		return fmt.Errorf("%w: product_id is required", ErrInvalidEvent)
+	}
+	if e.Position < 0 {
+		return fmt.Errorf("%w: position must not be negative", ErrInvalidEvent)
+	}
+	return nil
+}
+
+// NewID возвращает случайный идентификатор события
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate event id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,186 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// ErrImpressionNotFound - выдачи нет, она истекла или показана другому
+// пользователю
+var ErrImpressionNotFound = errors.New("impression not found")
+
+// Impression - выдача, которую сервер вернул пользователю. Показы пишет
+// сервер, а реакции клиента принимаются только на товары из выдачи - так
+// клиент не может накрутить CTR категории или магазина.
+type Impression struct {
+	ID        string              `dynamodbav:"impression_id"`
+	UserID    string              `dynamodbav:"user_id,omitempty"`
+	SessionID string              `dynamodbav:"session_id,omitempty"`
+	Request   *types.GiftRequest  `dynamodbav:"request,omitempty"`
+	Products  []ImpressionProduct `dynamodbav:"products"`
+	CreatedAt time.Time           `dynamodbav:"created_at"`
+	ExpiresAt int64               `dynamodbav:"expires_at"` // TTL DynamoDB, unix-время
+}
+
+// ImpressionProduct - товар выдачи с категорией и магазином на момент показа
+type ImpressionProduct struct {
+	ProductID string `dynamodbav:"product_id"`
+	Category  string `dynamodbav:"category,omitempty"`
+	Store     string `dynamodbav:"store,omitempty"`
+	Position  int    `dynamodbav:"position"` // Место в выдаче, с 1
+}
+
+// Reaction - реакция клиента на товар из выдачи
+type Reaction struct {
+	Type      string
+	ProductID string
+}
+
+// Recorder пишет показы выдач и проверяет реакции на них
+type Recorder struct {
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+func NewRecorder(store Store, ttl time.Duration) *Recorder {
+	return &Recorder{store: store, ttl: ttl, now: time.Now}
+}
+
+// NewRecorderFromEnv собирает Recorder из NewStoreFromEnv и LoadEventTTL
+func NewRecorderFromEnv(dynamoClient *dynamodb.Client) (*Recorder, error) {
+	store, err := NewStoreFromEnv(dynamoClient)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := LoadEventTTL()
+	if err != nil {
+		return nil, err
+	}
+	return NewRecorder(store, ttl), nil
+}
+
+// Shown сохраняет выдачу и события показа ее товаров и возвращает ID
+// выдачи, который клиент передает вместе с реакциями
+func (r *Recorder) Shown(ctx context.Context, userID, sessionID string, request *types.GiftRequest, products []types.Product) (string, error) {
+	if len(products) == 0 {
+		return "", nil
+	}
+
+	id, err := NewID()
+	if err != nil {
+		return "", err
+	}
+	now := r.now().UTC()
+	impression := &Impression{
+		ID:        id,
+		UserID:    userID,
+		SessionID: sessionID,
+		Request:   request,
+		Products:  make([]ImpressionProduct, 0, len(products)),
+		CreatedAt: now,
+		ExpiresAt: now.Add(r.ttl).Unix(),
+	}
+
+	seen := make(map[string]bool, len(products))
+	for i, product := range products {
+		if product.ID == "" || seen[product.ID] {
+			continue
+		}
+		seen[product.ID] = true
+		impression.Products = append(impression.Products, ImpressionProduct{
+			ProductID: product.ID,
+			Category:  product.Category,
+			Store:     product.Store,
+			Position:  i + 1,
+		})
+	}
+
+	if err := r.store.SaveImpression(ctx, impression); err != nil {
+		return "", err
+	}
+
+	events := make([]Event, 0, len(impression.Products))
+	for _, product := range impression.Products {
+		events = append(events, impression.event(EventShown, product))
+	}
+	if err := r.store.Record(ctx, events); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Engage записывает реакции на товары выдачи impressionID. Категория,
+// магазин и позиция берутся из выдачи; повторная реакция того же типа на
+// тот же товар перезаписывает событие и учитывается один раз.
+func (r *Recorder) Engage(ctx context.Context, userID, impressionID string, reactions []Reaction) ([]Event, error) {
+	impression, err := r.store.Impression(ctx, impressionID)
+	if err != nil {
+		return nil, err
+	}
+	now := r.now().UTC()
+	if impression.UserID != userID || impression.ExpiresAt < now.Unix() {
+		return nil, ErrImpressionNotFound
+	}
+
+	events := make([]Event, 0, len(reactions))
+	recorded := make(map[string]bool, len(reactions))
+	for _, reaction := range reactions {
+		if reaction.Type == EventShown {
+			return nil, fmt.Errorf("%w: shown events are recorded by the server", ErrInvalidEvent)
+		}
+		if !eventTypes[reaction.Type] {
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, reaction.Type)
+		}
+		product, ok := impression.product(reaction.ProductID)
+		if !ok {
+			return nil, fmt.Errorf("%w: product %q was not shown in this impression", ErrInvalidEvent, reaction.ProductID)
+		}
+
+		event := impression.event(reaction.Type, product)
+		event.CreatedAt = now
+		event.ExpiresAt = now.Add(r.ttl).Unix()
+		if recorded[event.ID] {
+			continue
+		}
+		recorded[event.ID] = true
+		events = append(events, event)
+	}
+
+	if err := r.store.Record(ctx, events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (i *Impression) product(productID string) (ImpressionProduct, bool) {
+	for _, product := range i.Products {
+		if product.ProductID == productID {
+			return product, true
+		}
+	}
+	return ImpressionProduct{}, false
+}
+
+// event строит событие с ID из выдачи, товара и типа - одна реакция
+// каждого типа на товар выдачи
+func (i *Impression) event(eventType string, product ImpressionProduct) Event {
+	return Event{
+		ID:           i.ID + ":" + product.ProductID + ":" + eventType,
+		ImpressionID: i.ID,
+		Type:         eventType,
+		ProductID:    product.ProductID,
+		Category:     product.Category,
+		Store:        product.Store,
+		Position:     product.Position,
+		SessionID:    i.SessionID,
+		Request:      i.Request,
+		CreatedAt:    i.CreatedAt,
+		ExpiresAt:    i.ExpiresAt,
+	}
+}
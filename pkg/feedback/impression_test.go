@@ -0,0 +1,166 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+var testProducts = []types.Product{
+	{ID: "p1", Category: "electronics", Store: "kaspi"},
+	{ID: "p2", Category: "books", Store: "ozon"},
+	{ID: "p1", Category: "electronics", Store: "kaspi"},
+}
+
+// newTestRecorder возвращает Recorder с управляемыми часами
+func newTestRecorder(store Store, now *time.Time) *Recorder {
+	recorder := NewRecorder(store, time.Hour)
+	recorder.now = func() time.Time { return *now }
+	return recorder
+}
+
+func TestRecorderShown(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	id, err := newTestRecorder(store, &now).Shown(ctx, "u1", "s1", nil, testProducts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	impression, err := store.Impression(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Повтор товара в выдаче - один показ
+	if len(impression.Products) != 2 || impression.Products[1].Position != 2 {
+		t.Errorf("impression products = %+v", impression.Products)
+	}
+	if len(store.events) != 2 || store.events[0].Type != EventShown {
+		t.Errorf("events = %+v, want two shown events", store.events)
+	}
+
+	if id, err := newTestRecorder(store, &now).Shown(ctx, "u1", "", nil, nil); err != nil || id != "" {
+		t.Errorf("Shown(empty) = %q, %v", id, err)
+	}
+}
+
+func TestRecorderEngage(t *testing.T) {
+	ctx := context.Background()
+	shownAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		userID    string
+		after     time.Duration
+		reactions []Reaction
+		want      int
+		wantErr   error
+	}{
+		{
+			name:      "reactions on shown products",
+			userID:    "u1",
+			reactions: []Reaction{{Type: EventClicked, ProductID: "p1"}, {Type: EventDismissed, ProductID: "p2"}},
+			want:      2,
+		},
+		{
+			name:      "duplicate reactions in one request",
+			userID:    "u1",
+			reactions: []Reaction{{Type: EventLiked, ProductID: "p1"}, {Type: EventLiked, ProductID: "p1"}},
+			want:      1,
+		},
+		{
+			name:      "impression of another user",
+			userID:    "u2",
+			reactions: []Reaction{{Type: EventClicked, ProductID: "p1"}},
+			wantErr:   ErrImpressionNotFound,
+		},
+		{
+			name:      "expired impression",
+			userID:    "u1",
+			after:     2 * time.Hour,
+			reactions: []Reaction{{Type: EventClicked, ProductID: "p1"}},
+			wantErr:   ErrImpressionNotFound,
+		},
+		{
+			name:      "product not in impression",
+			userID:    "u1",
+			reactions: []Reaction{{Type: EventClicked, ProductID: "p3"}},
+			wantErr:   ErrInvalidEvent,
+		},
+		{
+			name:      "client cannot record shown",
+			userID:    "u1",
+			reactions: []Reaction{{Type: EventShown, ProductID: "p1"}},
+			wantErr:   ErrInvalidEvent,
+		},
+		{
+			name:      "unknown reaction type",
+			userID:    "u1",
+			reactions: []Reaction{{Type: "shared", ProductID: "p1"}},
+			wantErr:   ErrInvalidEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			now := shownAt
+			recorder := newTestRecorder(store, &now)
+			id, err := recorder.Shown(ctx, "u1", "", nil, testProducts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			now = shownAt.Add(tt.after)
+			events, err := recorder.Engage(ctx, tt.userID, id, tt.reactions)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Engage() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(events) != tt.want {
+				t.Errorf("events = %d, want %d", len(events), tt.want)
+			}
+			for _, event := range events {
+				if event.ImpressionID != id || event.Category == "" || event.Position == 0 {
+					t.Errorf("event must take product data from the impression: %+v", event)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown impression", func(t *testing.T) {
+		now := shownAt
+		_, err := newTestRecorder(NewMemoryStore(), &now).Engage(ctx, "u1", "missing", []Reaction{{Type: EventClicked, ProductID: "p1"}})
+		if !errors.Is(err, ErrImpressionNotFound) {
+			t.Errorf("Engage() error = %v, want ErrImpressionNotFound", err)
+		}
+	})
+}
+
+func TestRecorderEngageRepeatedRequestCountsOnce(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	recorder := newTestRecorder(store, &now)
+
+	id, err := recorder.Shown(ctx, "u1", "", nil, testProducts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := recorder.Engage(ctx, "u1", id, []Reaction{{Type: EventClicked, ProductID: "p1"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := Aggregate(ctx, store, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Global.Clicked != 1 || stats.Global.Engaged != 1 {
+		t.Errorf("global = %+v, want one click", stats.Global)
+	}
+}
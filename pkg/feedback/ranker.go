@@ -0,0 +1,150 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+const (
+	// Как часто теплая Lambda перечитывает снимок статистики
+	defaultStatsReloadInterval = 10 * time.Minute
+	// Время на чтение снимка
+	statsReloadTimeout = 3 * time.Second
+	// Сколько "виртуальных" показов со средним CTR добавляется к категории
+	// или магазину, чтобы пара случайных кликов не перевернула выдачу
+	priorImpressions = 50
+	// Влияние CTR на порядок выдачи по умолчанию
+	defaultRankWeight = 0.5
+	// Границы множителя релевантности
+	minBoost = 0.5
+	maxBoost = 1.5
+)
+
+// Ranker поднимает в выдаче категории и магазины с высоким CTR и опускает
+// те, которые чаще среднего скрывают. Снимок
+// статистики перечитывается лениво, как таксономия.
+type Ranker struct {
+	source    StatsStore
+	interval  time.Duration
+	weight    float64
+	current   atomic.Pointer[Stats]
+	checkedAt atomic.Int64
+	reloading sync.Mutex
+}
+
+// NewRanker загружает снимок статистики. Если снимка еще нет, ранжирование
+// нейтральное до следующей перезагрузки.
+func NewRanker(ctx context.Context, source StatsStore, interval time.Duration, weight float64) *Ranker {
+	r := &Ranker{source: source, interval: interval, weight: weight}
+	r.current.Store(&Stats{})
+
+	stats, err := source.Load(ctx)
+	if err != nil {
+		fmt.Printf("Feedback stats are not loaded, ranking is neutral: %v\n", err)
+	} else {
+		r.current.Store(stats)
+	}
+	r.checkedAt.Store(time.Now().UnixNano())
+	return r
+}
+
+// NewRankerFromEnv создает ранжировщик по FEEDBACK_STATS_TABLE,
+// FEEDBACK_RELOAD_INTERVAL и FEEDBACK_RANK_WEIGHT; без таблицы
+// возвращает nil - ранжирование по обратной связи выключено
+func NewRankerFromEnv(ctx context.Context, dynamoClient *dynamodb.Client) (*Ranker, error) {
+	source := NewStatsStoreFromEnv(dynamoClient)
+	if source == nil {
+		return nil, nil
+	}
+
+	interval := defaultStatsReloadInterval
+	if v := os.Getenv("FEEDBACK_RELOAD_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FEEDBACK_RELOAD_INTERVAL: %w", err)
+		}
+		interval = d
+	}
+
+	weight := defaultRankWeight
+	if v := os.Getenv("FEEDBACK_RANK_WEIGHT"); v != "" {
+		w, err := strconv.ParseFloat(v, 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid FEEDBACK_RANK_WEIGHT: %s", v)
+		}
+		weight = w
+	}
+
+	return NewRanker(ctx, source, interval, weight), nil
+}
+
+// Stats возвращает актуальный снимок, при необходимости перечитывая его
+func (r *Ranker) Stats() *Stats {
+	if r.interval > 0 && time.Since(time.Unix(0, r.checkedAt.Load())) >= r.interval {
+		r.reload()
+	}
+	return r.current.Load()
+}
+
+func (r *Ranker) reload() {
+	if !r.reloading.TryLock() {
+		return
+	}
+	defer r.reloading.Unlock()
+	defer r.checkedAt.Store(time.Now().UnixNano())
+
+	ctx, cancel := context.WithTimeout(context.Background(), statsReloadTimeout)
+	defer cancel()
+
+	stats, err := r.source.Load(ctx)
+	if err != nil {
+		fmt.Printf("Feedback stats reload failed, keeping previous snapshot: %v\n", err)
+		return
+	}
+	r.current.Store(stats)
+}
+
+// Boost возвращает множитель релевантности товара: 1 - нейтрально, больше 1 -
+// категория и магазин товара кликают чаще среднего, меньше 1 - реже или
+// чаще скрывают
+func (r *Ranker) Boost(product types.Product) float64 {
+	stats := r.Stats()
+	if stats.Global.Shown == 0 {
+		return 1
+	}
+
+	ratio := relativeRate(stats.Categories[product.Category], stats.Global) * relativeRate(stats.Stores[product.Store], stats.Global)
+	// Среднее геометрическое, чтобы категория и магазин весили одинаково
+	boost := 1 + r.weight*(math.Sqrt(ratio)-1)
+	return math.Max(minBoost, math.Min(maxBoost, boost))
+}
+
+// relativeRate сравнивает counts со средним: сглаженный CTR относительно
+// среднего, умноженный на долю показов без отказа относительно средней.
+// Без показов это 1.
+func relativeRate(counts, global Counts) float64 {
+	ratio := 1.0
+	if ctr := global.CTR(); ctr > 0 {
+		ratio *= smoothed(counts.Engaged, counts.Shown, ctr) / ctr
+	}
+	if dismissRate := global.DismissRate(); dismissRate > 0 && dismissRate < 1 {
+		kept := math.Max(0, 1-smoothed(counts.Dismissed, counts.Shown, dismissRate))
+		ratio *= kept / (1 - dismissRate)
+	}
+	return ratio
+}
+
+// smoothed добавляет к count и shown priorImpressions показов со средней
+// долей rate, чтобы пара случайных событий не перевернула выдачу
+func smoothed(count, shown int64, rate float64) float64 {
+	return (float64(count) + rate*priorImpressions) / (float64(shown) + priorImpressions)
+}
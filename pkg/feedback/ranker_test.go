@@ -0,0 +1,82 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+type staticStats struct {
+	stats *Stats
+	err   error
+}
+
+func (s staticStats) Load(ctx context.Context) (*Stats, error) {
+	return s.stats, s.err
+}
+
+func (s staticStats) Save(ctx context.Context, stats *Stats) error {
+	return nil
+}
+
+func TestRankerBoost(t *testing.T) {
+	stats := &Stats{
+		// Средний CTR 0.1, средняя доля отказов 0.1
+		Global: Counts{Shown: 10000, Engaged: 1000, Dismissed: 1000},
+		Categories: map[string]Counts{
+			"electronics": {Shown: 1000, Engaged: 300, Dismissed: 100},
+			"books":       {Shown: 1000, Engaged: 100, Dismissed: 100},
+			"toys":        {Shown: 1000, Engaged: 100, Dismissed: 500},
+			"home":        {Shown: 5, Engaged: 5},
+			"beauty":      {Shown: 100000, Engaged: 100000},
+			"sports":      {Shown: 100000},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		product types.Product
+		weight  float64
+		want    float64
+	}{
+		{name: "average category", product: types.Product{Category: "books"}, weight: 0.5, want: 1},
+		{name: "no statistics is neutral", product: types.Product{Category: "garden", Store: "kaspi"}, weight: 0.5, want: 1},
+		// sqrt((300+5)/1050 / 0.1) = 1.704
+		{name: "high ctr raises", product: types.Product{Category: "electronics"}, weight: 0.5, want: 1.352},
+		// sqrt((1 - (500+5)/1050) / 0.9) = 0.759
+		{name: "dismissals lower", product: types.Product{Category: "toys"}, weight: 0.5, want: 0.880},
+		// Приор в 50 показов гасит пять случайных кликов: sqrt(10/55 / 0.1 * (1 - 5/55) / 0.9) = 1.355
+		{name: "few impressions stay near neutral", product: types.Product{Category: "home"}, weight: 0.5, want: 1.178},
+		{name: "clamped from above", product: types.Product{Category: "beauty"}, weight: 2, want: maxBoost},
+		{name: "clamped from below", product: types.Product{Category: "sports"}, weight: 2, want: minBoost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranker := NewRanker(context.Background(), staticStats{stats: stats}, 0, tt.weight)
+			if got := ranker.Boost(tt.product); math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("Boost() = %.3f, want %.3f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankerNeutralWithoutStats(t *testing.T) {
+	product := types.Product{Category: "electronics", Store: "kaspi"}
+
+	ranker := NewRanker(context.Background(), staticStats{err: errors.New("not found")}, 0, 0.5)
+	if got := ranker.Boost(product); got != 1 {
+		t.Errorf("Boost() without snapshot = %v, want 1", got)
+	}
+
+	weightless := NewRanker(context.Background(), staticStats{stats: &Stats{
+		Global:     Counts{Shown: 100, Engaged: 10},
+		Categories: map[string]Counts{"electronics": {Shown: 100, Engaged: 90}},
+	}}, 0, 0)
+	if got := weightless.Boost(product); got != 1 {
+		t.Errorf("Boost() with zero weight = %v, want 1", got)
+	}
+}
@@ -0,0 +1,201 @@
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Counts - число событий каждого типа. Engaged - показы товара, после
+// которых была хотя бы одна положительная реакция: переход, лайк и покупка
+// того же товара из той же выдачи - одно вовлечение.
+type Counts struct {
+	Shown     int64 `json:"shown"`
+	Clicked   int64 `json:"clicked"`
+	Liked     int64 `json:"liked"`
+	Dismissed int64 `json:"dismissed"`
+	Purchased int64 `json:"purchased"`
+	Engaged   int64 `json:"engaged"`
+}
+
+func (c *Counts) add(eventType string, engaged bool) {
+	if engaged {
+		c.Engaged++
+	}
+	switch eventType {
+	case EventShown:
+		c.Shown++
+	case EventClicked:
+		c.Clicked++
+	case EventLiked:
+		c.Liked++
+	case EventDismissed:
+		c.Dismissed++
+	case EventPurchased:
+		c.Purchased++
+	}
+}
+
+// CTR - доля показов, после которых была положительная реакция; не больше 1
+func (c Counts) CTR() float64 {
+	if c.Shown == 0 {
+		return 0
+	}
+	return math.Min(1, float64(c.Engaged)/float64(c.Shown))
+}
+
+// DismissRate - доля показов, после которых товар скрыли
+func (c Counts) DismissRate() float64 {
+	if c.Shown == 0 {
+		return 0
+	}
+	return math.Min(1, float64(c.Dismissed)/float64(c.Shown))
+}
+
+func positive(eventType string) bool {
+	return eventType == EventClicked || eventType == EventLiked || eventType == EventPurchased
+}
+
+// Stats - агрегаты событий за окно по категориям и магазинам
+type Stats struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Since       time.Time         `json:"since"`
+	Events      int64             `json:"events"`
+	Global      Counts            `json:"global"`
+	Categories  map[string]Counts `json:"categories"`
+	Stores      map[string]Counts `json:"stores"`
+
+	engaged map[string]bool // Показы товара, уже учтенные в Engaged
+}
+
+// Aggregate собирает статистику по событиям из store не старше since
+func Aggregate(ctx context.Context, store Store, since time.Time) (*Stats, error) {
+	stats := &Stats{
+		Since:      since,
+		Categories: make(map[string]Counts),
+		Stores:     make(map[string]Counts),
+	}
+
+	err := store.Scan(ctx, since, func(event Event) error {
+		stats.Add(event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats.GeneratedAt = time.Now().UTC()
+	return stats, nil
+}
+
+// Add учитывает событие в общих счетчиках, категории и магазине товара.
+// Вовлечение считается один раз на товар выдачи.
+func (s *Stats) Add(event Event) {
+	s.Events++
+
+	engaged := false
+	if positive(event.Type) {
+		key := event.ImpressionID + ":" + event.ProductID
+		if event.ImpressionID == "" {
+			key = event.ID
+		}
+		if s.engaged == nil {
+			s.engaged = make(map[string]bool)
+		}
+		engaged = !s.engaged[key]
+		s.engaged[key] = true
+	}
+
+	s.Global.add(event.Type, engaged)
+	if event.Category != "" {
+		counts := s.Categories[event.Category]
+		counts.add(event.Type, engaged)
+		s.Categories[event.Category] = counts
+	}
+	if event.Store != "" {
+		counts := s.Stores[event.Store]
+		counts.add(event.Type, engaged)
+		s.Stores[event.Store] = counts
+	}
+}
+
+// StatsStore хранит последний снимок статистики
+type StatsStore interface {
+	Load(ctx context.Context) (*Stats, error)
+	Save(ctx context.Context, stats *Stats) error
+}
+
+// DynamoStatsStore хранит снимок JSON-документом в атрибуте document
+// элемента с ключом stats_id, как и таксономия
+type DynamoStatsStore struct {
+	client    *dynamodb.Client
+	tableName string
+	id        string
+}
+
+func NewDynamoStatsStore(client *dynamodb.Client, tableName, id string) *DynamoStatsStore {
+	return &DynamoStatsStore{
+		client:    client,
+		tableName: tableName,
+		id:        id,
+	}
+}
+
+// NewStatsStoreFromEnv возвращает хранилище статистики из таблицы
+// FEEDBACK_STATS_TABLE или nil, если таблица не задана
+func NewStatsStoreFromEnv(dynamoClient *dynamodb.Client) StatsStore {
+	table := os.Getenv("FEEDBACK_STATS_TABLE")
+	if table == "" {
+		return nil
+	}
+	return NewDynamoStatsStore(dynamoClient, table, "current")
+}
+
+func (s *DynamoStatsStore) Load(ctx context.Context) (*Stats, error) {
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]dyntypes.AttributeValue{
+			"stats_id": &dyntypes.AttributeValueMemberS{Value: s.id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback stats: %w", err)
+	}
+	document, ok := out.Item["document"].(*dyntypes.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf("feedback stats %q not found in %s", s.id, s.tableName)
+	}
+
+	var stats Stats
+	if err := json.Unmarshal([]byte(document.Value), &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse feedback stats: %w", err)
+	}
+	return &stats, nil
+}
+
+func (s *DynamoStatsStore) Save(ctx context.Context, stats *Stats) error {
+	document, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feedback stats: %w", err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item: map[string]dyntypes.AttributeValue{
+			"stats_id":     &dyntypes.AttributeValueMemberS{Value: s.id},
+			"document":     &dyntypes.AttributeValueMemberS{Value: string(document)},
+			"generated_at": &dyntypes.AttributeValueMemberS{Value: stats.GeneratedAt.Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save feedback stats: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package feedback
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	recorder := newTestRecorder(store, &now)
+
+	id, err := recorder.Shown(ctx, "u1", "", nil, testProducts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Переход, лайк и покупка одного товара - одно вовлечение
+	_, err = recorder.Engage(ctx, "u1", id, []Reaction{
+		{Type: EventClicked, ProductID: "p1"},
+		{Type: EventLiked, ProductID: "p1"},
+		{Type: EventPurchased, ProductID: "p1"},
+		{Type: EventDismissed, ProductID: "p2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Старое событие за пределами окна не учитывается
+	old := Event{ID: "old", Type: EventClicked, ProductID: "p9", Category: "books", CreatedAt: now.Add(-48 * time.Hour)}
+	if err := store.Record(ctx, []Event{old}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := Aggregate(ctx, store, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Events != 6 {
+		t.Errorf("events = %d, want 6", stats.Events)
+	}
+	wantGlobal := Counts{Shown: 2, Clicked: 1, Liked: 1, Dismissed: 1, Purchased: 1, Engaged: 1}
+	if stats.Global != wantGlobal {
+		t.Errorf("global = %+v, want %+v", stats.Global, wantGlobal)
+	}
+	if got := stats.Global.CTR(); got != 0.5 {
+		t.Errorf("CTR = %v, want 0.5", got)
+	}
+	if got := stats.Categories["electronics"]; got.Engaged != 1 || got.Shown != 1 || got.CTR() != 1 {
+		t.Errorf("electronics = %+v", got)
+	}
+	if got := stats.Stores["ozon"]; got.Dismissed != 1 || got.DismissRate() != 1 || got.CTR() != 0 {
+		t.Errorf("ozon = %+v", got)
+	}
+}
+
+func TestCountsRates(t *testing.T) {
+	tests := []struct {
+		counts      Counts
+		wantCTR     float64
+		wantDismiss float64
+	}{
+		{counts: Counts{}, wantCTR: 0, wantDismiss: 0},
+		{counts: Counts{Shown: 4, Engaged: 1, Dismissed: 2}, wantCTR: 0.25, wantDismiss: 0.5},
+		// Показы истекли раньше реакций - доля все равно не больше 1
+		{counts: Counts{Shown: 1, Engaged: 3, Dismissed: 2}, wantCTR: 1, wantDismiss: 1},
+	}
+	for _, tt := range tests {
+		if got := tt.counts.CTR(); got != tt.wantCTR {
+			t.Errorf("%+v CTR() = %v, want %v", tt.counts, got, tt.wantCTR)
+		}
+		if got := tt.counts.DismissRate(); got != tt.wantDismiss {
+			t.Errorf("%+v DismissRate() = %v, want %v", tt.counts, got, tt.wantDismiss)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Сколько хранятся сырые события
+const defaultEventTTL = 90 * 24 * time.Hour
+
+// Лимит BatchWriteItem
+const batchSize = 25
+
+// Сколько раз дописывать элементы, которые DynamoDB не успела обработать
+const batchRetries = 3
+
+// Store хранит события обратной связи и выдачи, к которым они относятся
+type Store interface {
+	Record(ctx context.Context, events []Event) error
+	// Scan перебирает события не старше since
+	Scan(ctx context.Context, since time.Time, fn func(Event) error) error
+	SaveImpression(ctx context.Context, impression *Impression) error
+	// Impression возвращает выдачу или ErrImpressionNotFound
+	Impression(ctx context.Context, id string) (*Impression, error)
+}
+
+// NewStoreFromEnv выбирает хранилище по FEEDBACK_BACKEND: dynamodb (по
+// умолчанию, таблицы FEEDBACK_TABLE и FEEDBACK_IMPRESSIONS_TABLE) или
+// memory для локального запуска
+func NewStoreFromEnv(dynamoClient *dynamodb.Client) (Store, error) {
+	switch backend := os.Getenv("FEEDBACK_BACKEND"); backend {
+	case "", "dynamodb":
+		table := os.Getenv("FEEDBACK_TABLE")
+		if table == "" {
+			table = "feedback_events"
+		}
+		impressionsTable := os.Getenv("FEEDBACK_IMPRESSIONS_TABLE")
+		if impressionsTable == "" {
+			impressionsTable = "feedback_impressions"
+		}
+		return NewDynamoStore(dynamoClient, table, impressionsTable), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown FEEDBACK_BACKEND: %s", backend)
+	}
+}
+
+// LoadEventTTL читает FEEDBACK_TTL (по умолчанию 90 дней)
+func LoadEventTTL() (time.Duration, error) {
+	v := os.Getenv("FEEDBACK_TTL")
+	if v == "" {
+		return defaultEventTTL, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid FEEDBACK_TTL: %w", err)
+	}
+	return d, nil
+}
+
+// DynamoStore хранит события в таблице с ключом event_id, выдачи - в
+// таблице с ключом impression_id; у обеих TTL по expires_at
+type DynamoStore struct {
+	client           *dynamodb.Client
+	tableName        string
+	impressionsTable string
+}
+
+func NewDynamoStore(client *dynamodb.Client, tableName, impressionsTable string) *DynamoStore {
+	return &DynamoStore{
+		client:           client,
+		tableName:        tableName,
+		impressionsTable: impressionsTable,
+	}
+}
+
+func (s *DynamoStore) Record(ctx context.Context, events []Event) error {
+	for start := 0; start < len(events); start += batchSize {
+		end := min(start+batchSize, len(events))
+
+		requests := make([]dyntypes.WriteRequest, 0, end-start)
+		for _, event := range events[start:end] {
+			item, err := attributevalue.MarshalMap(event)
+			if err != nil {
+				return fmt.Errorf("failed to marshal event: %w", err)
+			}
+			requests = append(requests, dyntypes.WriteRequest{PutRequest: &dyntypes.PutRequest{Item: item}})
+		}
+
+		if err := s.write(ctx, requests); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write отправляет пачку и дописывает необработанные элементы
+func (s *DynamoStore) write(ctx context.Context, requests []dyntypes.WriteRequest) error {
+	for attempt := 0; len(requests) > 0; attempt++ {
+		if attempt > batchRetries {
+			return fmt.Errorf("failed to write %d feedback events: retries exhausted", len(requests))
+		}
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt*50) * time.Millisecond):
+			}
+		}
+
+		out, err := s.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]dyntypes.WriteRequest{s.tableName: requests},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write feedback events: %w", err)
+		}
+		requests = out.UnprocessedItems[s.tableName]
+	}
+	return nil
+}
+
+func (s *DynamoStore) Scan(ctx context.Context, since time.Time, fn func(Event) error) error {
+	sinceValue, err := attributevalue.Marshal(since)
+	if err != nil {
+		return fmt.Errorf("failed to marshal time: %w", err)
+	}
+
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName:        aws.String(s.tableName),
+		FilterExpression: aws.String("created_at >= :since"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":since": sinceValue,
+		},
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to scan feedback events: %w", err)
+		}
+
+		var events []Event
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &events); err != nil {
+			return fmt.Errorf("failed to unmarshal feedback events: %w", err)
+		}
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *DynamoStore) SaveImpression(ctx context.Context, impression *Impression) error {
+	item, err := attributevalue.MarshalMap(impression)
+	if err != nil {
+		return fmt.Errorf("failed to marshal impression: %w", err)
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.impressionsTable),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save impression: %w", err)
+	}
+	return nil
+}
+
+func (s *DynamoStore) Impression(ctx context.Context, id string) (*Impression, error) {
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.impressionsTable),
+		Key: map[string]dyntypes.AttributeValue{
+			"impression_id": &dyntypes.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get impression: %w", err)
+	}
+	if out.Item == nil {
+		return nil, ErrImpressionNotFound
+	}
+
+	var impression Impression
+	if err := attributevalue.UnmarshalMap(out.Item, &impression); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal impression: %w", err)
+	}
+	return &impression, nil
+}
+
+// MemoryStore хранит события в памяти процесса - для локального запуска.
+// Событие с тем же event_id заменяет прежнее, как PutItem в DynamoDB.
+type MemoryStore struct {
+	mu          sync.Mutex
+	events      []Event
+	index       map[string]int
+	impressions map[string]Impression
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		index:       make(map[string]int),
+		impressions: make(map[string]Impression),
+	}
+}
+
+func (s *MemoryStore) Record(ctx context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		if i, ok := s.index[event.ID]; ok {
+			s.events[i] = event
+			continue
+		}
+		s.index[event.ID] = len(s.events)
+		s.events = append(s.events, event)
+	}
+	return nil
+}
+
+func (s *MemoryStore) SaveImpression(ctx context.Context, impression *Impression) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.impressions[impression.ID] = *impression
+	return nil
+}
+
+func (s *MemoryStore) Impression(ctx context.Context, id string) (*Impression, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	impression, ok := s.impressions[id]
+	if !ok {
+		return nil, ErrImpressionNotFound
+	}
+	return &impression, nil
+}
+
+func (s *MemoryStore) Scan(ctx context.Context, since time.Time, fn func(Event) error) error {
+	s.mu.Lock()
+	events := append([]Event(nil), s.events...)
+	s.mu.Unlock()
+
+	for _, event := range events {
+		if event.CreatedAt.Before(since) {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// Состояния размыкателя цепи
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// Breaker размыкает цепь после серии отказов хоста. После паузы пропускает
+// один пробный запрос: успех замыкает цепь, отказ снова размыкает ее.
+type Breaker struct {
+	mu        sync.Mutex
+	host      string
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func NewBreaker(host string, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		host:      host,
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     StateClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow сообщает, можно ли отправить запрос
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		// Пока пробный запрос не завершился, остальные ждут
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.state = StateClosed
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= b.threshold) {
+		b.state = StateOpen
+		b.openedAt = b.now()
+		metrics.tripped(b.host)
+	}
+}
+
+// Release снимает пробный запрос без вывода о здоровье хоста
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// failure, success и release допускают nil - у клиента без реестра
+// размыкателей нет
+
+func (b *Breaker) failure() {
+	if b != nil {
+		b.Failure()
+	}
+}
+
+func (b *Breaker) success() {
+	if b != nil {
+		b.Success()
+	}
+}
+
+func (b *Breaker) release() {
+	if b != nil {
+		b.Release()
+	}
+}
+
+// State возвращает текущее состояние цепи
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// BreakerRegistry хранит по одному размыкателю на хост
+type BreakerRegistry struct {
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// DefaultBreakers - реестр процесса, общий для всех сервисов
+var DefaultBreakers = NewBreakerRegistry()
+
+func NewBreakerRegistry() *BreakerRegistry {
+	return &BreakerRegistry{breakers: make(map[string]*Breaker)}
+}
+
+func (r *BreakerRegistry) Get(host string, threshold int, cooldown time.Duration) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	breaker, ok := r.breakers[host]
+	if !ok {
+		breaker = NewBreaker(host, threshold, cooldown)
+		r.breakers[host] = breaker
+	}
+	return breaker
+}
+
+// States возвращает состояние цепи для каждого известного хоста
+func (r *BreakerRegistry) States() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := make(map[string]string, len(r.breakers))
+	for host, breaker := range r.breakers {
+		states[host] = breaker.State()
+	}
+	return states
+}
@@ -0,0 +1,54 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker("example.com", 3, 10*time.Second)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Failure()
+	breaker.Failure()
+	breaker.Success()
+	breaker.Failure()
+	breaker.Failure()
+	if breaker.State() != StateClosed || !breaker.Allow() {
+		t.Fatal("success must reset the failure count")
+	}
+
+	breaker.Failure()
+	if breaker.State() != StateOpen || breaker.Allow() {
+		t.Fatalf("state = %s, want open after 3 failures", breaker.State())
+	}
+
+	now = now.Add(9 * time.Second)
+	if breaker.Allow() {
+		t.Fatal("open breaker must reject until cooldown ends")
+	}
+
+	// После паузы проходит ровно один пробный запрос
+	now = now.Add(time.Second)
+	if !breaker.Allow() || breaker.State() != StateHalfOpen {
+		t.Fatalf("state = %s, want half-open probe", breaker.State())
+	}
+	if breaker.Allow() {
+		t.Fatal("second request must wait for the probe")
+	}
+
+	breaker.Failure()
+	if breaker.State() != StateOpen || breaker.Allow() {
+		t.Fatalf("state = %s, want open after failed probe", breaker.State())
+	}
+
+	now = now.Add(10 * time.Second)
+	if !breaker.Allow() {
+		t.Fatal("probe must be allowed after cooldown")
+	}
+	breaker.Success()
+	if breaker.State() != StateClosed || !breaker.Allow() || !breaker.Allow() {
+		t.Fatalf("state = %s, want closed after successful probe", breaker.State())
+	}
+}
@@ -0,0 +1,267 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Doer - минимальный интерфейс HTTP клиента, его реализуют и *http.Client, и *Client
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Options настраивает повторы и размыкатели цепи
+type Options struct {
+	MaxAttempts      int           // Всего попыток, включая первую
+	BaseDelay        time.Duration // Задержка перед первым повтором
+	MaxDelay         time.Duration // Верхняя граница задержки (в том числе из Retry-After)
+	BreakerThreshold int           // Подряд идущих отказов до размыкания
+	BreakerCooldown  time.Duration // Сколько цепь остается разомкнутой
+}
+
+var DefaultOptions = Options{
+	MaxAttempts:      3,
+	BaseDelay:        200 * time.Millisecond,
+	MaxDelay:         5 * time.Second,
+	BreakerThreshold: 5,
+	BreakerCooldown:  30 * time.Second,
+}
+
+// Client выполняет исходящие запросы с повторами при 429/5xx и сетевых
+// ошибках и не пускает запросы к хостам с разомкнутой цепью
+type Client struct {
+	http     *http.Client
+	options  Options
+	breakers *BreakerRegistry
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+func New(httpClient *http.Client, options Options, breakers *BreakerRegistry) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 1
+	}
+
+	return &Client{
+		http:     httpClient,
+		options:  options,
+		breakers: breakers,
+		sleep:    sleepContext,
+	}
+}
+
+var (
+	defaultClient *Client
+	defaultOnce   sync.Once
+)
+
+// Default возвращает общий клиент процесса. Состояние размыкателей хранится
+// в глобальном реестре и поэтому переживает вызовы "теплой" Lambda.
+func Default() *Client {
+	defaultOnce.Do(func() {
+		defaultClient = New(&http.Client{}, LoadOptions(), DefaultBreakers)
+	})
+	return defaultClient
+}
+
+// LoadOptions читает HTTP_RETRY_MAX_ATTEMPTS, HTTP_RETRY_BASE_DELAY,
+// HTTP_RETRY_MAX_DELAY, HTTP_BREAKER_THRESHOLD и HTTP_BREAKER_COOLDOWN
+func LoadOptions() Options {
+	options := DefaultOptions
+
+	ints := map[string]*int{
+		"HTTP_RETRY_MAX_ATTEMPTS": &options.MaxAttempts,
+		"HTTP_BREAKER_THRESHOLD":  &options.BreakerThreshold,
+	}
+	for key, target := range ints {
+		if v := os.Getenv(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				log.Printf("Invalid %s: %q", key, v)
+				continue
+			}
+			*target = n
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"HTTP_RETRY_BASE_DELAY": &options.BaseDelay,
+		"HTTP_RETRY_MAX_DELAY":  &options.MaxDelay,
+		"HTTP_BREAKER_COOLDOWN": &options.BreakerCooldown,
+	}
+	for key, target := range durations {
+		if v := os.Getenv(key); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				log.Printf("Invalid %s: %v", key, err)
+				continue
+			}
+			*target = d
+		}
+	}
+
+	return options
+}
+
+// ErrCircuitOpen возвращается без обращения к хосту, пока цепь разомкнута
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Do выполняет запрос с повторами. Размыкатель учитывает один исход на
+// вызов Do: серия повторов одного запроса - это один отказ хоста, а не
+// несколько.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	var breaker *Breaker
+	if c.breakers != nil {
+		breaker = c.breakers.Get(host, c.options.BreakerThreshold, c.options.BreakerCooldown)
+		if !breaker.Allow() {
+			metrics.rejected(host)
+			return nil, fmt.Errorf("%w for %s", ErrCircuitOpen, host)
+		}
+	}
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if err := rewindBody(req); err != nil {
+				// Повторить нельзя, запрос закончился отказом хоста
+				breaker.failure()
+				return nil, lastErr
+			}
+		}
+
+		resp, err := c.http.Do(req)
+		retryable, delay := c.classify(req.Context(), resp, err, attempt)
+
+		if !retryable {
+			if err == nil {
+				breaker.success()
+			} else {
+				// Запрос отменен вызывающим - о здоровье хоста это ничего не говорит
+				breaker.release()
+			}
+			return resp, err
+		}
+		if attempt >= c.options.MaxAttempts {
+			breaker.failure()
+			return resp, err
+		}
+
+		// Тело неудачного ответа больше не нужно, освобождаем соединение
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+			lastErr = fmt.Errorf("status %d", resp.StatusCode)
+		} else {
+			lastErr = err
+		}
+
+		metrics.retried(host)
+		if err := c.sleep(req.Context(), delay); err != nil {
+			// Повтор не успел, но хост уже ответил отказом
+			breaker.failure()
+			return nil, fmt.Errorf("retry aborted after %v: %w", lastErr, err)
+		}
+	}
+}
+
+// classify решает, стоит ли повторять запрос, и через сколько
+func (c *Client) classify(ctx context.Context, resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	if err != nil {
+		// Отмена контекста - решение вызывающего, а не отказ хоста
+		if ctx.Err() != nil {
+			return false, 0
+		}
+		return true, c.backoff(attempt)
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return false, 0
+	}
+	if resp.StatusCode == http.StatusNotImplemented {
+		return false, 0
+	}
+
+	if delay, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		if delay > c.options.MaxDelay {
+			delay = c.options.MaxDelay
+		}
+		return true, delay
+	}
+
+	return true, c.backoff(attempt)
+}
+
+// backoff - экспоненциальная задержка с полным джиттером
+func (c *Client) backoff(attempt int) time.Duration {
+	ceiling := c.options.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > c.options.MaxDelay {
+		ceiling = c.options.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
+
+// retryAfter разбирает Retry-After в секундах или в формате HTTP-даты
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func rewindBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.GetBody == nil {
+		return errors.New("request body cannot be replayed")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	// Не ждем, если повтор все равно не успеет до дедлайна
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return context.DeadlineExceeded
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,282 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedTransport отвечает статусами по очереди; последний повторяется
+type scriptedTransport struct {
+	mu        sync.Mutex
+	responses []*http.Response
+	bodies    []string
+	calls     int
+}
+
+func (t *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		t.bodies = append(t.bodies, string(body))
+	}
+	resp := t.responses[min(t.calls, len(t.responses)-1)]
+	t.calls++
+	// Копия, чтобы повторяющийся ответ можно было читать снова
+	copied := *resp
+	copied.Body = io.NopCloser(strings.NewReader(""))
+	copied.Request = req
+	return &copied, nil
+}
+
+func status(code int, headers ...string) *http.Response {
+	resp := &http.Response{StatusCode: code, Header: http.Header{}}
+	for i := 0; i+1 < len(headers); i += 2 {
+		resp.Header.Set(headers[i], headers[i+1])
+	}
+	return resp
+}
+
+// newTestClient создает клиент без реальных пауз; задержки повторов
+// записываются в sleeps
+func newTestClient(transport http.RoundTripper, options Options, breakers *BreakerRegistry) (*Client, *[]time.Duration) {
+	client := New(&http.Client{Transport: transport}, options, breakers)
+	var sleeps []time.Duration
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	return client, &sleeps
+}
+
+func get(t *testing.T, client *Client) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "https://api.example.com/items", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client.Do(req)
+}
+
+func TestDoRetries(t *testing.T) {
+	options := Options{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		name       string
+		responses  []*http.Response
+		wantCalls  int
+		wantStatus int
+		wantSleeps []time.Duration // nil - проверяется только число пауз
+		maxSleeps  []time.Duration // Верхние границы пауз с джиттером
+	}{
+		{
+			name:       "success",
+			responses:  []*http.Response{status(200)},
+			wantCalls:  1,
+			wantStatus: 200,
+		},
+		{
+			name:       "server error then success",
+			responses:  []*http.Response{status(503), status(502), status(200)},
+			wantCalls:  3,
+			wantStatus: 200,
+			maxSleeps:  []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:       "server error exhausts attempts",
+			responses:  []*http.Response{status(500)},
+			wantCalls:  3,
+			wantStatus: 500,
+			maxSleeps:  []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name:       "too many requests with retry-after",
+			responses:  []*http.Response{status(429, "Retry-After", "2"), status(200)},
+			wantCalls:  2,
+			wantStatus: 200,
+			wantSleeps: []time.Duration{2 * time.Second},
+		},
+		{
+			name:       "retry-after capped by max delay",
+			responses:  []*http.Response{status(429, "Retry-After", "120"), status(200)},
+			wantCalls:  2,
+			wantStatus: 200,
+			wantSleeps: []time.Duration{5 * time.Second},
+		},
+		{
+			name:       "too many requests without retry-after",
+			responses:  []*http.Response{status(429), status(200)},
+			wantCalls:  2,
+			wantStatus: 200,
+			maxSleeps:  []time.Duration{100 * time.Millisecond},
+		},
+		{
+			name:       "client error is not retried",
+			responses:  []*http.Response{status(404)},
+			wantCalls:  1,
+			wantStatus: 404,
+		},
+		{
+			name:       "not implemented is not retried",
+			responses:  []*http.Response{status(501)},
+			wantCalls:  1,
+			wantStatus: 501,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &scriptedTransport{responses: tt.responses}
+			client, sleeps := newTestClient(transport, options, nil)
+
+			resp, err := get(t, client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus || transport.calls != tt.wantCalls {
+				t.Errorf("status %d after %d calls, want %d after %d",
+					resp.StatusCode, transport.calls, tt.wantStatus, tt.wantCalls)
+			}
+
+			if len(*sleeps) != tt.wantCalls-1 {
+				t.Fatalf("sleeps = %v, want %d", *sleeps, tt.wantCalls-1)
+			}
+			for i, d := range *sleeps {
+				if tt.wantSleeps != nil && d != tt.wantSleeps[i] {
+					t.Errorf("sleep %d = %v, want %v", i, d, tt.wantSleeps[i])
+				}
+				if tt.maxSleeps != nil && (d < 0 || d >= tt.maxSleeps[i]) {
+					t.Errorf("sleep %d = %v, want below %v", i, d, tt.maxSleeps[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDoReplaysBody(t *testing.T) {
+	transport := &scriptedTransport{responses: []*http.Response{status(503), status(200)}}
+	client, _ := newTestClient(transport, Options{MaxAttempts: 3}, nil)
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com/items", strings.NewReader(`{"q":"чайник"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.bodies) != 2 || transport.bodies[0] != transport.bodies[1] || transport.bodies[1] != `{"q":"чайник"}` {
+		t.Errorf("bodies = %q, want the same body twice", transport.bodies)
+	}
+}
+
+func TestDoCircuitBreaker(t *testing.T) {
+	options := Options{MaxAttempts: 3, BreakerThreshold: 2, BreakerCooldown: 30 * time.Second}
+	registry := NewBreakerRegistry()
+	breaker := registry.Get("api.example.com", options.BreakerThreshold, options.BreakerCooldown)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+
+	transport := &scriptedTransport{responses: []*http.Response{status(500)}}
+	client, _ := newTestClient(transport, options, registry)
+
+	// Три неудачные попытки одного вызова - один отказ хоста
+	if _, err := get(t, client); err != nil {
+		t.Fatal(err)
+	}
+	if transport.calls != 3 || breaker.State() != StateClosed {
+		t.Fatalf("after first call: %d requests, state %s; want 3, closed", transport.calls, breaker.State())
+	}
+
+	if _, err := get(t, client); err != nil {
+		t.Fatal(err)
+	}
+	if breaker.State() != StateOpen {
+		t.Fatalf("state = %s, want open after two failed calls", breaker.State())
+	}
+
+	// Разомкнутая цепь не пускает запросы к хосту
+	if _, err := get(t, client); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want ErrCircuitOpen", err)
+	}
+	if transport.calls != 6 {
+		t.Errorf("requests = %d, want 6", transport.calls)
+	}
+
+	// Неудачная проба снова размыкает цепь
+	now = now.Add(options.BreakerCooldown)
+	if _, err := get(t, client); err != nil {
+		t.Fatal(err)
+	}
+	if breaker.State() != StateOpen {
+		t.Fatalf("state = %s, want open after failed probe", breaker.State())
+	}
+	if _, err := get(t, client); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want ErrCircuitOpen", err)
+	}
+
+	// Удачная проба замыкает цепь
+	now = now.Add(options.BreakerCooldown)
+	transport.responses = []*http.Response{status(200)}
+	resp, err := get(t, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 200 || breaker.State() != StateClosed {
+		t.Errorf("status %d, state %s; want 200, closed", resp.StatusCode, breaker.State())
+	}
+}
+
+func TestDoCanceledRequestReleasesProbe(t *testing.T) {
+	options := Options{MaxAttempts: 2, BreakerThreshold: 1, BreakerCooldown: time.Minute}
+	registry := NewBreakerRegistry()
+	breaker := registry.Get("api.example.com", options.BreakerThreshold, options.BreakerCooldown)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+	breaker.Failure()
+	now = now.Add(options.BreakerCooldown)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := New(&http.Client{}, options, registry)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/items", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+
+	// Отмена не считается отказом: цепь ждет новую пробу
+	if breaker.State() != StateHalfOpen || !breaker.Allow() {
+		t.Errorf("state = %s, want half-open with probe allowed", breaker.State())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HostStats - счетчики исходящих запросов к одному хосту
+type HostStats struct {
+	Retries  int64 `json:"retries"`
+	Trips    int64 `json:"trips"`    // Сколько раз цепь размыкалась
+	Rejected int64 `json:"rejected"` // Запросы, не отправленные из-за разомкнутой цепи
+}
+
+type counters struct {
+	mu    sync.Mutex
+	hosts map[string]*HostStats
+}
+
+var metrics = &counters{hosts: make(map[string]*HostStats)}
+
+// Stats возвращает копию счетчиков по хостам
+func Stats() map[string]HostStats {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	stats := make(map[string]HostStats, len(metrics.hosts))
+	for host, s := range metrics.hosts {
+		stats[host] = *s
+	}
+	return stats
+}
+
+func (c *counters) host(host string) *HostStats {
+	s, ok := c.hosts[host]
+	if !ok {
+		s = &HostStats{}
+		c.hosts[host] = s
+	}
+	return s
+}
+
+func (c *counters) retried(host string) {
+	c.mu.Lock()
+	c.host(host).Retries++
+	c.mu.Unlock()
+}
+
+func (c *counters) rejected(host string) {
+	c.mu.Lock()
+	c.host(host).Rejected++
+	c.mu.Unlock()
+}
+
+func (c *counters) tripped(host string) {
+	c.mu.Lock()
+	c.host(host).Trips++
+	c.mu.Unlock()
+
+	emitTripMetric(host)
+}
+
+// emitTripMetric пишет метрику в формате CloudWatch Embedded Metric Format:
+// CloudWatch Logs сам превращает такую строку лога в метрику CircuitBreakerTrips
+func emitTripMetric(host string) {
+	line, err := json.Marshal(map[string]interface{}{
+		"_aws": map[string]interface{}{
+			"Timestamp": time.Now().UnixMilli(),
+			"CloudWatchMetrics": []map[string]interface{}{{
+				"Namespace":  "GiftAdvisor/Outbound",
+				"Dimensions": [][]string{{"Host"}},
+				"Metrics":    []map[string]string{{"Name": "CircuitBreakerTrips", "Unit": "Count"}},
+			}},
+		},
+		"Host":                host,
+		"CircuitBreakerTrips": 1,
+	})
+	if err != nil {
+		return
+	}
+	// Печатаем напрямую в stdout без префикса log, чтобы CloudWatch распознал JSON
+	fmt.Println(string(line))
+}
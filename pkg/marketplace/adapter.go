@@ -0,0 +1,107 @@
+package marketplace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// MarketplaceAdapter ищет товары в одном маркетплейсе и отвечает за
+// преобразование его ответа в types.Product
+type MarketplaceAdapter interface {
+	// Name возвращает ключ маркетплейса ("kaspi", "ozon", ...)
+	Name() string
+	// Configured сообщает, заданы ли учетные данные для API
+	Configured() bool
+	Search(ctx context.Context, categories []string, priceRange types.Range) ([]types.Product, error)
+}
+
+// StatusError - ответ маркетплейса с неуспешным HTTP статусом
+type StatusError struct {
+	Marketplace string
+	StatusCode  int
+	Body        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.Marketplace, e.StatusCode, e.Body)
+}
+
+// maxErrorBody ограничивает размер тела ответа, попадающего в текст ошибки
+const maxErrorBody = 1024
+
+// doJSON выполняет запрос и декодирует JSON ответ в out
+func doJSON(client httpclient.Doer, req *http.Request, marketplace string, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &StatusError{
+			Marketplace: marketplace,
+			StatusCode:  resp.StatusCode,
+			Body:        string(body),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", marketplace, err)
+	}
+
+	return nil
+}
+
+// localizedCategories переводит наши категории в названия маркетплейса
+func localizedCategories(marketplace string, categories []string) []string {
+	localized := make([]string, 0, len(categories))
+	for _, cat := range categories {
+		if name, ok := taxonomy.Current().CategoryName(marketplace, cat); ok {
+			localized = append(localized, name)
+		}
+	}
+	return localized
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// NewWebSearchServiceFromEnv создает поиск по всем маркетплейсам с учетными
+// данными из переменных окружения. Адаптеры без учетных данных пропускаются.
+func NewWebSearchServiceFromEnv() *WebSearchService {
+	// Общий клиент с повторами и размыкателями цепи по хостам
+	client := httpclient.Default()
+
+	service := NewWebSearchService(
+		NewKaspiAdapter(client, os.Getenv("KASPI_API_TOKEN")),
+		NewAliExpressAdapter(client, os.Getenv("ALIEXPRESS_API_TOKEN")),
+		NewWildberriesAdapter(client, os.Getenv("WILDBERRIES_API_TOKEN")),
+		NewOzonAdapter(client, os.Getenv("OZON_CLIENT_ID"), os.Getenv("OZON_API_KEY")),
+	)
+	service.SetOptions(LoadWebSearchOptions())
+
+	return service
+}
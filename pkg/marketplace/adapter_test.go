@@ -0,0 +1,172 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// fixtureServer отдает записанные ответы API из testdata по пути запроса и
+// запоминает запросы для проверки параметров
+type fixtureServer struct {
+	*httptest.Server
+	requests []*http.Request
+	bodies   []string
+}
+
+func newFixtureServer(t *testing.T, routes map[string]string) *fixtureServer {
+	t.Helper()
+	s := &fixtureServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, string(body))
+
+		name, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("fixture %s: %v", name, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestKaspiAdapterSearch(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"/shop/api/products/search": "kaspi_search.json"})
+	t.Setenv("KASPI_API_URL", server.URL)
+
+	adapter := NewKaspiAdapter(server.Client(), "kaspi-token")
+	products, err := adapter.Search(context.Background(), []string{"electronics", "books"}, types.Range{Min: 5000, Max: 150000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := server.requests[0]
+	if got := req.Header.Get("Authorization"); got != "Bearer kaspi-token" {
+		t.Errorf("Authorization = %q", got)
+	}
+	query := req.URL.Query()
+	if got := query.Get("categories"); got != "Электроника,Книги" {
+		t.Errorf("categories = %q", got)
+	}
+	if query.Get("price_from") != "5000" || query.Get("price_to") != "150000" {
+		t.Errorf("price filter = %q..%q", query.Get("price_from"), query.Get("price_to"))
+	}
+	assertGolden(t, "kaspi_search.golden.json", products)
+}
+
+func TestAliExpressAdapterSearch(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"/v2/products/search": "aliexpress_search.json"})
+	t.Setenv("ALIEXPRESS_API_URL", server.URL)
+
+	adapter := NewAliExpressAdapter(server.Client(), "ae-token")
+	products, err := adapter.Search(context.Background(), []string{"electronics"}, types.Range{Max: 25.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := server.requests[0].URL.Query()
+	if got := query.Get("keywords"); got != "Electronics" {
+		t.Errorf("keywords = %q", got)
+	}
+	// Границы цены передаются в центах
+	if got := query.Get("max_sale_price"); got != "2550" {
+		t.Errorf("max_sale_price = %q, want 2550", got)
+	}
+	if query.Has("min_sale_price") {
+		t.Error("min_sale_price must be omitted for open range")
+	}
+	assertGolden(t, "aliexpress_search.golden.json", products)
+}
+
+func TestAliExpressAdapterErrorInBody(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"/v2/products/search": "aliexpress_error.json"})
+	t.Setenv("ALIEXPRESS_API_URL", server.URL)
+
+	_, err := NewAliExpressAdapter(server.Client(), "ae-token").Search(context.Background(), []string{"books"}, types.Range{})
+	if err == nil || !strings.Contains(err.Error(), "405") {
+		t.Fatalf("err = %v, want aliexpress API error 405", err)
+	}
+}
+
+func TestWildberriesAdapterSearch(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{"/exactmatch/ru/common/v4/search": "wildberries_search.json"})
+	t.Setenv("WILDBERRIES_API_URL", server.URL)
+
+	adapter := NewWildberriesAdapter(server.Client(), "wb-token")
+	products, err := adapter.Search(context.Background(), []string{"home", "sports"}, types.Range{Min: 1000, Max: 5000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := server.requests[0]
+	if got := req.Header.Get("Authorization"); got != "wb-token" {
+		t.Errorf("Authorization = %q", got)
+	}
+	query := req.URL.Query()
+	if got := query.Get("query"); got != "Дом Спорт" {
+		t.Errorf("query = %q", got)
+	}
+	// Цена в копейках: priceU=min;max
+	if got := query.Get("priceU"); got != "100000;500000" {
+		t.Errorf("priceU = %q", got)
+	}
+	assertGolden(t, "wildberries_search.golden.json", products)
+}
+
+func TestOzonAdapterSearch(t *testing.T) {
+	server := newFixtureServer(t, map[string]string{
+		"/v3/product/list":      "ozon_list.json",
+		"/v3/product/info/list": "ozon_info.json",
+	})
+	t.Setenv("OZON_API_URL", server.URL)
+
+	adapter := NewOzonAdapter(server.Client(), "client-id", "api-key")
+	products, err := adapter.Search(context.Background(), []string{"electronics"}, types.Range{Max: 10000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.requests) != 2 {
+		t.Fatalf("requests = %d, want list and info", len(server.requests))
+	}
+	for _, req := range server.requests {
+		if req.Header.Get("Client-Id") != "client-id" || req.Header.Get("Api-Key") != "api-key" {
+			t.Errorf("%s: missing seller credentials", req.URL.Path)
+		}
+	}
+	if got := server.bodies[1]; got != `{"product_id":[223681945,223681946,223681947,223681948]}` {
+		t.Errorf("info request = %s", got)
+	}
+	// Чайник не электроника, Sony дороже верхней границы; цена колонки
+	// "2990.000" - десятичная запись, а не 2 990 000
+	assertGolden(t, "ozon_search.golden.json", products)
+}
+
+func TestAdapterStatusError(t *testing.T) {
+	server := newFixtureServer(t, nil)
+	t.Setenv("KASPI_API_URL", server.URL)
+
+	_, err := NewKaspiAdapter(server.Client(), "kaspi-token").Search(context.Background(), []string{"books"}, types.Range{})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound || statusErr.Marketplace != "kaspi" {
+		t.Fatalf("err = %v, want kaspi StatusError 404", err)
+	}
+}
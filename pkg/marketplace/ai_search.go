@@ -7,16 +7,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
-	"strconv"
 	"strings"
 
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/queryplan"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
 )
 
 type AISearchService struct {
 	serperToken string
-	httpClient  *http.Client
+	httpClient  httpclient.Doer
+	budget      *SerperBudget
+	planner     *queryplan.Planner
 }
 
 type SerperRequest struct {
@@ -36,20 +39,30 @@ type SerperResponse struct {
 	} `json:"shopping"`
 }
 
-func NewAISearchService(serperToken string) *AISearchService {
+// NewAISearchService создает сервис; budget может быть nil, тогда запросы не ограничиваются
+func NewAISearchService(serperToken string, budget *SerperBudget) *AISearchService {
 	return &AISearchService{
 		serperToken: serperToken,
-		httpClient:  &http.Client{},
+		httpClient:  httpclient.Default(),
+		budget:      budget,
+		planner:     queryplan.NewDefault(),
 	}
 }
 
-func (s *AISearchService) SearchProducts(ctx context.Context, categories []string, priceRange types.Range, marketplace string) ([]types.Product, error) {
+func (s *AISearchService) SearchProducts(ctx context.Context, intent types.SearchIntent) ([]types.Product, error) {
 	// Формируем поисковый запрос
-	query := s.buildSearchQuery(categories, priceRange, marketplace)
+	plan, err := s.planner.Plan(queryplan.ProviderAISearch, intent)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.budget.Acquire(ctx); err != nil {
+		return nil, err
+	}
 
 	// Создаем запрос к Serper API
 	reqBody := SerperRequest{
-		Q:          query,
+		Q:          plan.Query,
 		GL:         "kz",       // Ищем в Казахстане
 		Num:        20,         // Получаем 20 результатов
 		SearchType: "shopping", // Поиск по товарам
@@ -79,32 +92,18 @@ func (s *AISearchService) SearchProducts(ctx context.Context, categories []strin
 		return nil, fmt.Errorf("failed to read response: %v", err)
 	}
 
+	// Повторы 429/5xx уже выполнены клиентом, здесь остается только сообщить об ошибке
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("serper API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
 	var serperResp SerperResponse
 	if err := json.Unmarshal(body, &serperResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %v", err)
 	}
 
 	// Преобразуем результаты в наш формат
-	return s.convertToProducts(serperResp.Shopping, categories[0])
-}
-
-func (s *AISearchService) buildSearchQuery(categories []string, priceRange types.Range, marketplace string) string {
-	query := strings.Join(categories, " ")
-
-	// Добавляем ценовой диапазон
-	if priceRange.Min > 0 || priceRange.Max > 0 {
-		query += fmt.Sprintf(" price %d-%d тенге", int(priceRange.Min), int(priceRange.Max))
-	}
-
-	// Добавляем маркетплейс если указан
-	if marketplace != "" {
-		query += " site:" + marketplace
-	}
-
-	// Добавляем "купить в Казахстане" для релевантности
-	query += " купить в Казахстане"
-
-	return query
+	return s.convertToProducts(serperResp.Shopping, plan.Query, intent.Categories)
 }
 
 func (s *AISearchService) convertToProducts(results []struct {
@@ -113,21 +112,25 @@ func (s *AISearchService) convertToProducts(results []struct {
 	Price    string `json:"price"`
 	Source   string `json:"source"`
 	ImageURL string `json:"imageUrl"`
-}, category string) ([]types.Product, error) {
+}, query string, categories []string) ([]types.Product, error) {
 	var products []types.Product
 
 	for _, result := range results {
-		price, _ := s.extractPrice(result.Price)
+		price, err := s.extractPrice(result.Price)
+		if err != nil {
+			price.Currency = money.KZT
+		}
 
 		product := types.Product{
 			ID:          generateProductID(result.Link),
 			Title:       result.Title,
 			Description: result.Title, // Используем заголовок как описание
-			Price:       price,
+			Price:       price.Value,
+			Currency:    price.Currency,
 			URL:         result.Link,
 			ImageURL:    result.ImageURL,
 			Store:       result.Source,
-			Category:    category,
+			Category:    inferCategory(result.Title, query, categories),
 			Rating:      0, // У нас нет рейтинга из поиска
 		}
 
@@ -137,18 +140,9 @@ func (s *AISearchService) convertToProducts(results []struct {
 	return products, nil
 }
 
-func (s *AISearchService) extractPrice(priceStr string) (float64, error) {
-	// Удаляем все символы кроме цифр и точки
-	re := regexp.MustCompile(`[^\d.]`)
-	cleanPrice := re.ReplaceAllString(priceStr, "")
-
-	// Конвертируем в float64
-	price, err := strconv.ParseFloat(cleanPrice, 64)
-	if err != nil {
-		return 0, err
-	}
-
-	return price, nil
+func (s *AISearchService) extractPrice(priceStr string) (money.Amount, error) {
+	// Поиск идет по Казахстану, поэтому цены без валюты считаем в тенге
+	return money.Parse(priceStr, money.LocaleKZ)
 }
 
 func generateProductID(url string) string {
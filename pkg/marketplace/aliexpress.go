@@ -0,0 +1,138 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+const aliExpressBaseURL = "https://api.aliexpress.com"
+
+type AliExpressAdapter struct {
+	token   string
+	baseURL string
+	client  httpclient.Doer
+}
+
+type aliExpressSearchResponse struct {
+	Response struct {
+		RespResult struct {
+			RespCode int    `json:"resp_code"`
+			RespMsg  string `json:"resp_msg"`
+			Result   struct {
+				Products struct {
+					Product []aliExpressProduct `json:"product"`
+				} `json:"products"`
+			} `json:"result"`
+		} `json:"resp_result"`
+	} `json:"aliexpress_affiliate_product_query_response"`
+}
+
+type aliExpressProduct struct {
+	ProductID               int64  `json:"product_id"`
+	ProductTitle            string `json:"product_title"`
+	TargetSalePrice         string `json:"target_sale_price"`
+	TargetSalePriceCurrency string `json:"target_sale_price_currency"`
+	EvaluateRate            string `json:"evaluate_rate"` // Доля положительных отзывов, например "95.3%"
+	ProductDetailURL        string `json:"product_detail_url"`
+	PromotionLink           string `json:"promotion_link"`
+	ProductMainImageURL     string `json:"product_main_image_url"`
+	FirstLevelCategoryName  string `json:"first_level_category_name"`
+	SecondLevelCategoryName string `json:"second_level_category_name"`
+}
+
+func NewAliExpressAdapter(client httpclient.Doer, token string) *AliExpressAdapter {
+	return &AliExpressAdapter{
+		token:   token,
+		baseURL: envOrDefault("ALIEXPRESS_API_URL", aliExpressBaseURL),
+		client:  client,
+	}
+}
+
+func (a *AliExpressAdapter) Name() string { return "aliexpress" }
+
+func (a *AliExpressAdapter) Configured() bool { return a.token != "" }
+
+func (a *AliExpressAdapter) Search(ctx context.Context, categories []string, priceRange types.Range) ([]types.Product, error) {
+	params := url.Values{}
+	params.Add("keywords", strings.Join(localizedCategories("aliexpress", categories), ","))
+	params.Add("target_currency", money.USD)
+	params.Add("ship_to_country", "KZ")
+	// AliExpress принимает границы цены в центах
+	if priceRange.Min > 0 {
+		params.Add("min_sale_price", fmt.Sprintf("%.0f", priceRange.Min*100))
+	}
+	if priceRange.Max > 0 {
+		params.Add("max_sale_price", fmt.Sprintf("%.0f", priceRange.Max*100))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", a.baseURL+"/v2/products/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+
+	var resp aliExpressSearchResponse
+	if err := doJSON(a.client, req, a.Name(), &resp); err != nil {
+		return nil, err
+	}
+
+	// Ошибки API AliExpress приходят с HTTP 200 и кодом в теле ответа
+	result := resp.Response.RespResult
+	if result.RespCode != 0 && result.RespCode != 200 {
+		return nil, fmt.Errorf("aliexpress API error %d: %s", result.RespCode, result.RespMsg)
+	}
+
+	products := make([]types.Product, 0, len(result.Result.Products.Product))
+	for _, item := range result.Result.Products.Product {
+		products = append(products, a.toProduct(item, categories))
+	}
+
+	return products, nil
+}
+
+func (a *AliExpressAdapter) toProduct(item aliExpressProduct, categories []string) types.Product {
+	price, err := money.Parse(item.TargetSalePrice, money.LocaleEN)
+	if err != nil {
+		price = money.Amount{Currency: money.USD}
+	}
+	if item.TargetSalePriceCurrency != "" {
+		price.Currency = strings.ToUpper(item.TargetSalePriceCurrency)
+	}
+
+	link := item.PromotionLink
+	if link == "" {
+		link = item.ProductDetailURL
+	}
+
+	categoryText := item.ProductTitle + " " + item.FirstLevelCategoryName + " " + item.SecondLevelCategoryName
+
+	return types.Product{
+		ID:          "aliexpress-" + strconv.FormatInt(item.ProductID, 10),
+		Title:       item.ProductTitle,
+		Description: item.ProductTitle,
+		Price:       price.Value,
+		Currency:    price.Currency,
+		Rating:      aliExpressRating(item.EvaluateRate),
+		URL:         link,
+		ImageURL:    item.ProductMainImageURL,
+		Store:       a.Name(),
+		Category:    inferCategory(categoryText, "", categories),
+	}
+}
+
+// aliExpressRating переводит долю положительных отзывов в пятибалльную шкалу
+func aliExpressRating(evaluateRate string) float64 {
+	rate, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(evaluateRate), "%"), 64)
+	if err != nil {
+		return 0
+	}
+	return rate / 20
+}
@@ -0,0 +1,124 @@
+package marketplace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Время жизни кэша по умолчанию для каждого источника.
+// Переопределяется переменными CACHE_TTL_<SOURCE>, например CACHE_TTL_SERPER=30m.
+var defaultCacheTTL = map[string]time.Duration{
+	"dynamodb": 5 * time.Minute,
+	"serper":   time.Hour,
+}
+
+type cachedProducts struct {
+	Products []types.Product `json:"products"`
+	CachedAt time.Time       `json:"cached_at"`
+}
+
+func loadCacheTTL() map[string]time.Duration {
+	ttl := make(map[string]time.Duration, len(defaultCacheTTL))
+	for source, def := range defaultCacheTTL {
+		ttl[source] = def
+
+		v := os.Getenv("CACHE_TTL_" + strings.ToUpper(source))
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			fmt.Printf("Invalid cache TTL for %s: %v\n", source, err)
+			continue
+		}
+		ttl[source] = d
+	}
+	return ttl
+}
+
+// cacheKey строит ключ по нормализованному запросу: категории и интересы
+// приводятся к нижнему регистру, сортируются и очищаются от повторов.
+func cacheKey(source string, intent types.SearchIntent) string {
+	priceRange := intent.PriceRange
+	raw := fmt.Sprintf("%s|%s|%s|%s|%.2f|%.2f|%s|%s",
+		source,
+		strings.Join(normalizeTerms(intent.Categories), ","),
+		strings.Join(strings.Fields(strings.ToLower(intent.Query)), " "),
+		strings.Join(normalizeTerms(intent.Interests), ","),
+		priceRange.Min,
+		priceRange.Max,
+		strings.ToUpper(priceRange.Currency),
+		strings.ToLower(strings.TrimSpace(intent.Marketplace)),
+	)
+
+	sum := sha256.Sum256([]byte(raw))
+	return "products:v3:" + source + ":" + hex.EncodeToString(sum[:16])
+}
+
+// normalizeTerms приводит список к нижнему регистру, убирает повторы и
+// сортирует, чтобы порядок в запросе не влиял на ключ
+func normalizeTerms(terms []string) []string {
+	normalized := make([]string, 0, len(terms))
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		normalized = append(normalized, term)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// cachedSearch отдает результат источника из кэша или выполняет поиск и сохраняет его
+func (s *ProductService) cachedSearch(ctx context.Context, source, key string, search func() ([]types.Product, error)) ([]types.Product, types.CacheSourceStatus, error) {
+	status := types.CacheSourceStatus{Source: source}
+
+	if s.cache == nil {
+		products, err := search()
+		return products, status, err
+	}
+
+	if data, ok, err := s.cache.Get(ctx, key); err != nil {
+		fmt.Printf("Cache get error for %s: %v\n", source, err)
+	} else if ok {
+		var entry cachedProducts
+		if err := json.Unmarshal(data, &entry); err == nil {
+			status.Hit = true
+			status.CachedAt = entry.CachedAt
+			return entry.Products, status, nil
+		}
+	}
+
+	products, err := search()
+	if err != nil {
+		return nil, status, err
+	}
+
+	ttl := s.cacheTTL[source]
+	if ttl <= 0 {
+		return products, status, nil
+	}
+
+	data, err := json.Marshal(cachedProducts{Products: products, CachedAt: time.Now().UTC()})
+	if err != nil {
+		fmt.Printf("Failed to marshal cache entry for %s: %v\n", source, err)
+		return products, status, nil
+	}
+	if err := s.cache.Set(ctx, key, data, ttl); err != nil {
+		fmt.Printf("Cache set error for %s: %v\n", source, err)
+	}
+
+	return products, status, nil
+}
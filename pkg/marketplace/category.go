@@ -0,0 +1,83 @@
+package marketplace
+
+import (
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+)
+
+// Ключевые слова для определения категории по названию товара
+// (дополняют локализованные названия из таксономии)
+var categoryKeywords = map[string][]string{
+	"electronics": {"смартфон", "телефон", "iphone", "samsung", "наушник", "airpods", "ноутбук", "планшет", "ipad", "смарт-часы", "часы apple", "колонк", "игровая приставка", "playstation", "xbox", "камера", "электронн", "headphones", "laptop", "phone", "tablet", "speaker"},
+	"books":       {"книга", "книги", "роман", "издание", "kindle", "электронная книга", "book"},
+	"sports":      {"спорт", "фитнес", "гантел", "велосипед", "самокат", "мяч", "тренажер", "коврик для йоги", "кроссовк", "ракетк", "fitness", "sport", "bike"},
+	"health":      {"тонометр", "массажер", "ортопедическ", "витамин", "глюкометр", "ингалятор", "massager"},
+	"beauty":      {"парфюм", "духи", "туалетная вода", "косметик", "крем", "помада", "уход за кожей", "фен", "плойк", "perfume", "cosmetic", "makeup"},
+	"toys":        {"игрушк", "конструктор", "lego", "кукла", "пазл", "настольная игра", "машинка", "детск", "toy", "puzzle"},
+	"home":        {"посуда", "кофеварк", "кофемашин", "чайник", "плед", "подушк", "постельн", "светильник", "ваза", "пылесос", "мультиварк", "блендер", "кастрюл", "сковород", "home", "kitchen"},
+}
+
+// inferCategory определяет категорию товара: сначала по названию среди
+// запрошенных категорий, затем по всем известным, и в последнюю очередь
+// по самому поисковому запросу.
+func inferCategory(title, query string, requested []string) string {
+	lowerTitle := strings.ToLower(title)
+
+	if category := bestCategoryMatch(lowerTitle, requested); category != "" {
+		return category
+	}
+
+	if category := bestCategoryMatch(lowerTitle, taxonomy.Current().CategoryKeys()); category != "" {
+		return category
+	}
+
+	// Название ничего не подсказало - берем категорию, упомянутую в запросе
+	lowerQuery := strings.ToLower(query)
+	for _, category := range requested {
+		if strings.Contains(lowerQuery, strings.ToLower(category)) {
+			return category
+		}
+	}
+
+	if len(requested) == 1 {
+		return requested[0]
+	}
+
+	return ""
+}
+
+func bestCategoryMatch(text string, candidates []string) string {
+	best := ""
+	bestScore := 0
+	for _, category := range candidates {
+		score := categoryScore(text, category)
+		// При равном счете предпочитаем лексикографически меньшую категорию,
+		// чтобы результат не зависел от порядка обхода map
+		if score > bestScore || (score == bestScore && score > 0 && category < best) {
+			best = category
+			bestScore = score
+		}
+	}
+	return best
+}
+
+func categoryScore(text, category string) int {
+	category = strings.ToLower(category)
+	score := 0
+
+	for _, keyword := range categoryKeywords[category] {
+		if strings.Contains(text, keyword) {
+			score++
+		}
+	}
+
+	// Локализованные названия категорий тоже считаются ключевыми словами
+	for _, name := range taxonomy.Current().Names(category) {
+		if strings.Contains(text, strings.ToLower(name)) {
+			score++
+		}
+	}
+
+	return score
+}
@@ -0,0 +1,120 @@
+package marketplace
+
+import (
+	"sort"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Пороги для товаров из разных магазинов: названия у маркетплейсов
+// оформлены по-разному, а цены расходятся сильнее, чем у дубликатов
+const (
+	equivalentTitleSimilarity = 0.6
+	equivalentPriceTolerance  = 0.5
+)
+
+// compareOffers группирует одинаковые товары из разных магазинов и находит
+// в каждой группе самое дешевое предложение в валюте запроса
+func compareOffers(products []types.Product, rates money.RateTable, currency string) []types.ProductGroup {
+	infos := make([]dedupInfo, len(products))
+	for i, product := range products {
+		infos[i] = newDedupInfo(product, rates)
+	}
+
+	clusters := newUnionFind(len(products))
+	for i := range products {
+		for j := i + 1; j < len(products); j++ {
+			if clusters.find(i) == clusters.find(j) {
+				continue
+			}
+			if equivalentProducts(products[i], products[j], infos[i], infos[j]) {
+				clusters.union(i, j)
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	var order []int
+	for i := range products {
+		root := clusters.find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	result := make([]types.ProductGroup, 0, len(order))
+	for _, root := range order {
+		if group, ok := buildGroup(products, groups[root], rates, currency); ok {
+			result = append(result, group)
+		}
+	}
+
+	return result
+}
+
+func equivalentProducts(a, b types.Product, infoA, infoB dedupInfo) bool {
+	if a.Category != "" && b.Category != "" && a.Category != b.Category {
+		return false
+	}
+	if titleSimilarity(infoA.tokens, infoB.tokens) < equivalentTitleSimilarity {
+		return false
+	}
+	return pricesClose(infoA.priceKZT, infoB.priceKZT, equivalentPriceTolerance)
+}
+
+type pricedOffer struct {
+	offer types.Offer
+	price float64 // Цена в валюте запроса
+}
+
+func buildGroup(products []types.Product, indexes []int, rates money.RateTable, currency string) (types.ProductGroup, bool) {
+	first := products[indexes[0]]
+	group := types.ProductGroup{
+		Title:    first.Title,
+		Category: first.Category,
+		Currency: currency,
+	}
+
+	var offers []pricedOffer
+	for _, i := range indexes {
+		product := products[i]
+		group.ProductIDs = append(group.ProductIDs, product.ID)
+		if group.Category == "" {
+			group.Category = product.Category
+		}
+
+		for _, offer := range productOffers(product) {
+			if offer.Price <= 0 {
+				continue
+			}
+			offerCurrency := offer.Currency
+			if offerCurrency == "" {
+				offerCurrency = money.StoreCurrency(offer.Store)
+			}
+			price, err := rates.Convert(offer.Price, offerCurrency, currency)
+			if err != nil {
+				continue
+			}
+			offers = append(offers, pricedOffer{offer: offer, price: price})
+		}
+	}
+
+	if len(offers) == 0 {
+		return group, false
+	}
+
+	sort.SliceStable(offers, func(i, j int) bool {
+		return offers[i].price < offers[j].price
+	})
+
+	for _, o := range offers {
+		group.Offers = append(group.Offers, o.offer)
+	}
+	group.BestOffer = offers[0].offer
+	group.BestPrice = offers[0].price
+	group.Savings = offers[len(offers)-1].price - offers[0].price
+
+	return group, true
+}
@@ -0,0 +1,376 @@
+package marketplace
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Пороги, при которых два товара одного магазина считаются одной позицией
+const (
+	duplicateTitleSimilarity = 0.8
+	duplicatePriceTolerance  = 0.1
+)
+
+// Параметры ссылок, которые не влияют на товар: метки рекламных кампаний и аналитики
+var trackingParams = map[string]bool{
+	"gclid": true, "fbclid": true, "yclid": true, "msclkid": true, "srsltid": true,
+	"ref": true, "ref_": true, "_openstat": true, "from": true, "spm": true,
+	"scm": true, "algo_pvid": true, "algo_exp_id": true, "aff_platform": true,
+	"aff_trace_key": true, "sk": true, "dp": true, "terminal_id": true, "gatewayadapt": true,
+	"asc_source": true, "c": true,
+}
+
+// Мобильные и региональные хосты, которые отдают те же страницы, что и основной
+var hostAliases = map[string]string{
+	"m.kaspi.kz":            "kaspi.kz",
+	"m.aliexpress.com":      "aliexpress.com",
+	"m.aliexpress.ru":       "aliexpress.com",
+	"aliexpress.ru":         "aliexpress.com",
+	"m.wildberries.ru":      "wildberries.ru",
+	"global.wildberries.ru": "wildberries.ru",
+	"wildberries.kz":        "wildberries.ru",
+	"m.ozon.ru":             "ozon.ru",
+	"ozon.kz":               "ozon.ru",
+}
+
+// CanonicalURL приводит ссылку на товар к каноническому виду: без схемы,
+// "www."/"m." префиксов, трекинговых параметров, якоря и завершающего "/"
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSpace(raw))
+	}
+
+	host := canonicalHost(u.Hostname())
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+
+	canonical := host + strings.TrimRight(u.EscapedPath(), "/")
+	if len(params) > 0 {
+		canonical += "?" + strings.Join(params, "&")
+	}
+	return canonical
+}
+
+// canonicalHost убирает "www."/"m."/"mobile." и заменяет псевдонимы, пока
+// хост меняется: m.ozon.kz -> ozon.kz -> ozon.ru
+func canonicalHost(host string) string {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	for i := 0; i <= len(hostAliases); i++ {
+		if alias, ok := hostAliases[host]; ok {
+			host = alias
+		} else if strings.HasPrefix(host, "m.") || strings.HasPrefix(host, "mobile.") {
+			host = host[strings.Index(host, ".")+1:]
+		} else {
+			break
+		}
+	}
+	return host
+}
+
+// deduplicate объединяет записи об одном и том же товаре, пришедшие из разных
+// источников: совпадение канонической ссылки или похожие название и цена в
+// одном магазине. Из группы остается самая полная запись, а все предложения
+// группы прикрепляются к ней в Offers.
+func deduplicate(products []types.Product, rates money.RateTable) []types.Product {
+	if len(products) == 0 {
+		return products
+	}
+
+	infos := make([]dedupInfo, len(products))
+	for i, product := range products {
+		infos[i] = newDedupInfo(product, rates)
+	}
+
+	clusters := newUnionFind(len(products))
+	byID := make(map[string]int)
+	byURL := make(map[string]int)
+	for i, info := range infos {
+		if id := products[i].ID; id != "" {
+			if j, ok := byID[id]; ok {
+				clusters.union(i, j)
+			} else {
+				byID[id] = i
+			}
+		}
+
+		if info.canonicalURL == "" {
+			continue
+		}
+		if j, ok := byURL[info.canonicalURL]; ok {
+			clusters.union(i, j)
+		} else {
+			byURL[info.canonicalURL] = i
+		}
+	}
+
+	for i := range infos {
+		for j := i + 1; j < len(infos); j++ {
+			if clusters.find(i) == clusters.find(j) {
+				continue
+			}
+			if infos[i].sameListing(infos[j]) {
+				clusters.union(i, j)
+			}
+		}
+	}
+
+	// Сохраняем порядок: группа занимает место своего первого товара
+	groups := make(map[int][]int)
+	var order []int
+	for i := range products {
+		root := clusters.find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	unique := make([]types.Product, 0, len(order))
+	for _, root := range order {
+		unique = append(unique, mergeProducts(products, groups[root]))
+	}
+
+	return unique
+}
+
+type dedupInfo struct {
+	canonicalURL string
+	store        string
+	tokens       map[string]bool
+	priceKZT     float64
+}
+
+func newDedupInfo(product types.Product, rates money.RateTable) dedupInfo {
+	info := dedupInfo{
+		canonicalURL: CanonicalURL(product.URL),
+		store:        effectiveStore(product),
+		tokens:       titleTokens(product.Title),
+	}
+	if product.URL == "" {
+		info.canonicalURL = ""
+	}
+
+	currency := product.Currency
+	if currency == "" {
+		currency = money.StoreCurrency(product.Store)
+	}
+	if price, err := rates.Convert(product.Price, currency, money.KZT); err == nil {
+		info.priceKZT = price
+	}
+
+	return info
+}
+
+// sameListing сравнивает товары без общей ссылки: магазины должны совпадать
+// (или быть неизвестны), названия - почти совпадать, цены - отличаться не сильно
+func (a dedupInfo) sameListing(b dedupInfo) bool {
+	if a.store != "" && b.store != "" && a.store != b.store {
+		return false
+	}
+	if titleSimilarity(a.tokens, b.tokens) < duplicateTitleSimilarity {
+		return false
+	}
+	return pricesClose(a.priceKZT, b.priceKZT, duplicatePriceTolerance)
+}
+
+// effectiveStore возвращает магазин товара; для записей DynamoDB и товаров
+// без магазина он определяется по ссылке
+func effectiveStore(product types.Product) string {
+	if product.Store != "" && product.Store != "dynamodb" && product.Store != "unknown" {
+		return product.Store
+	}
+	if product.URL == "" {
+		return ""
+	}
+	if store := detectStore(product.URL, ""); store != "unknown" {
+		return store
+	}
+	return ""
+}
+
+var titleStopWords = map[string]bool{
+	"и": true, "в": true, "на": true, "для": true, "с": true, "из": true, "по": true,
+	"the": true, "and": true, "for": true, "with": true, "of": true,
+}
+
+func titleTokens(title string) map[string]bool {
+	tokens := make(map[string]bool)
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, field := range fields {
+		if len([]rune(field)) < 2 && !unicode.IsDigit([]rune(field)[0]) {
+			continue
+		}
+		if titleStopWords[field] {
+			continue
+		}
+		tokens[field] = true
+	}
+	return tokens
+}
+
+// titleSimilarity - коэффициент Жаккара по словам названия
+func titleSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for token := range a {
+		if b[token] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// pricesClose сравнивает цены с относительным допуском; неизвестная цена
+// не мешает объединению
+func pricesClose(a, b, tolerance float64) bool {
+	if a <= 0 || b <= 0 {
+		return true
+	}
+	return math.Abs(a-b)/math.Max(a, b) <= tolerance
+}
+
+// mergeProducts берет за основу самую полную запись группы, дополняет ее
+// пустые поля из остальных и собирает все предложения группы
+func mergeProducts(products []types.Product, indexes []int) types.Product {
+	if len(indexes) == 1 {
+		return products[indexes[0]]
+	}
+
+	best := indexes[0]
+	for _, i := range indexes[1:] {
+		if richness(products[i]) > richness(products[best]) {
+			best = i
+		}
+	}
+
+	merged := products[best]
+	merged.Offers = nil
+	seenOffers := make(map[string]bool)
+
+	for _, i := range indexes {
+		product := products[i]
+		if merged.Description == "" || len(product.Description) > len(merged.Description) {
+			merged.Description = product.Description
+		}
+		if merged.ImageURL == "" {
+			merged.ImageURL = product.ImageURL
+		}
+		if merged.Category == "" {
+			merged.Category = product.Category
+		}
+		if merged.Rating == 0 {
+			merged.Rating = product.Rating
+		}
+		if merged.Price == 0 && product.Price > 0 {
+			merged.Price = product.Price
+			merged.Currency = product.Currency
+		}
+
+		for _, offer := range productOffers(product) {
+			key := offer.Source + "|" + offer.Store + "|" + CanonicalURL(offer.URL)
+			if seenOffers[key] {
+				continue
+			}
+			seenOffers[key] = true
+			merged.Offers = append(merged.Offers, offer)
+		}
+	}
+
+	return merged
+}
+
+// productOffers возвращает предложения товара; у товара без них единственное
+// предложение - он сам
+func productOffers(product types.Product) []types.Offer {
+	if len(product.Offers) > 0 {
+		return product.Offers
+	}
+	return []types.Offer{{
+		ProductID: product.ID,
+		Store:     effectiveStore(product),
+		Source:    product.Store,
+		Price:     product.Price,
+		Currency:  product.Currency,
+		URL:       product.URL,
+	}}
+}
+
+// richness оценивает полноту записи о товаре
+func richness(product types.Product) int {
+	score := 0
+	for _, filled := range []bool{
+		product.Title != "",
+		product.Description != "" && product.Description != product.Title,
+		product.Price > 0,
+		product.Currency != "",
+		product.Rating > 0,
+		product.URL != "",
+		product.ImageURL != "",
+		product.Category != "",
+	} {
+		if filled {
+			score++
+		}
+	}
+	return score
+}
+
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent: parent}
+}
+
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+func (u *unionFind) union(i, j int) {
+	ri, rj := u.find(i), u.find(j)
+	if ri == rj {
+		return
+	}
+	// Корнем остается меньший индекс, чтобы группа сохраняла позицию первого товара
+	if ri < rj {
+		u.parent[rj] = ri
+	} else {
+		u.parent[ri] = rj
+	}
+}
@@ -0,0 +1,70 @@
+package marketplace
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "scheme www and trailing slash",
+			raw:  "https://www.kaspi.kz/shop/p/naushniki-100/",
+			want: "kaspi.kz/shop/p/naushniki-100",
+		},
+		{
+			name: "tracking params dropped and rest sorted",
+			raw:  "https://kaspi.kz/shop/p/1?utm_source=google&ref=ads&c=750000000&b=2&a=1#reviews",
+			want: "kaspi.kz/shop/p/1?a=1&b=2",
+		},
+		{
+			name: "mobile aliexpress ru",
+			raw:  "https://m.aliexpress.ru/item/100.html?spm=a2g0o",
+			want: "aliexpress.com/item/100.html",
+		},
+		{
+			name: "regional aliexpress",
+			raw:  "https://aliexpress.ru/item/100.html",
+			want: "aliexpress.com/item/100.html",
+		},
+		{
+			name: "mobile aliexpress com",
+			raw:  "http://m.aliexpress.com/item/100.html",
+			want: "aliexpress.com/item/100.html",
+		},
+		{
+			name: "global wildberries",
+			raw:  "https://global.wildberries.ru/catalog/123/detail.aspx",
+			want: "wildberries.ru/catalog/123/detail.aspx",
+		},
+		{
+			name: "mobile regional host resolved in several steps",
+			raw:  "https://m.ozon.kz/product/kofemashina-42/",
+			want: "ozon.ru/product/kofemashina-42",
+		},
+		{
+			name: "unknown mobile host",
+			raw:  "https://mobile.example.com/p/1",
+			want: "example.com/p/1",
+		},
+		{
+			name: "host case ignored",
+			raw:  "HTTPS://WWW.OZON.RU/product/1",
+			want: "ozon.ru/product/1",
+		},
+		{
+			name: "not a url",
+			raw:  "  Some-ID ",
+			want: "some-id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanonicalURL(tt.raw); got != tt.want {
+				t.Errorf("CanonicalURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
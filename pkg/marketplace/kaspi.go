@@ -0,0 +1,128 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+const kaspiBaseURL = "https://kaspi.kz"
+
+type KaspiAdapter struct {
+	token   string
+	baseURL string
+	client  httpclient.Doer
+}
+
+type kaspiSearchResponse struct {
+	Data []kaspiProduct `json:"data"`
+}
+
+type kaspiProduct struct {
+	ID               string   `json:"id"`
+	Title            string   `json:"title"`
+	Brand            string   `json:"brand"`
+	UnitPrice        float64  `json:"unitPrice"`
+	Rating           float64  `json:"rating"`
+	ReviewsQuantity  int      `json:"reviewsQuantity"`
+	ShopLink         string   `json:"shopLink"`
+	Category         []string `json:"category"`
+	DeliveryDuration string   `json:"deliveryDuration"` // Например, "завтра" или "2-3 дня"
+	PreviewImages    []struct {
+		Small  string `json:"small"`
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"previewImages"`
+}
+
+func NewKaspiAdapter(client httpclient.Doer, token string) *KaspiAdapter {
+	return &KaspiAdapter{
+		token:   token,
+		baseURL: envOrDefault("KASPI_API_URL", kaspiBaseURL),
+		client:  client,
+	}
+}
+
+func (a *KaspiAdapter) Name() string { return "kaspi" }
+
+func (a *KaspiAdapter) Configured() bool { return a.token != "" }
+
+func (a *KaspiAdapter) Search(ctx context.Context, categories []string, priceRange types.Range) ([]types.Product, error) {
+	params := url.Values{}
+	params.Add("categories", strings.Join(localizedCategories("kaspi", categories), ","))
+	if priceRange.Min > 0 {
+		params.Add("price_from", fmt.Sprintf("%.0f", priceRange.Min))
+	}
+	if priceRange.Max > 0 {
+		params.Add("price_to", fmt.Sprintf("%.0f", priceRange.Max))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", a.baseURL+"/shop/api/products/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("Accept", "application/json")
+
+	var resp kaspiSearchResponse
+	if err := doJSON(a.client, req, a.Name(), &resp); err != nil {
+		return nil, err
+	}
+
+	products := make([]types.Product, 0, len(resp.Data))
+	for _, item := range resp.Data {
+		products = append(products, a.toProduct(item, categories))
+	}
+
+	return products, nil
+}
+
+func (a *KaspiAdapter) toProduct(item kaspiProduct, categories []string) types.Product {
+	link := item.ShopLink
+	if strings.HasPrefix(link, "/") {
+		link = kaspiBaseURL + link
+	}
+
+	var image string
+	if len(item.PreviewImages) > 0 {
+		image = item.PreviewImages[0].Large
+		if image == "" {
+			image = item.PreviewImages[0].Medium
+		}
+	}
+
+	description := item.Title
+	if item.Brand != "" {
+		description = item.Brand + " " + item.Title
+	}
+
+	id := "kaspi-" + item.ID
+
+	return types.Product{
+		ID:          id,
+		Title:       item.Title,
+		Description: description,
+		Price:       item.UnitPrice,
+		Currency:    money.KZT,
+		Rating:      item.Rating,
+		URL:         link,
+		ImageURL:    image,
+		Store:       a.Name(),
+		Category:    inferCategory(strings.Join(append([]string{item.Title}, item.Category...), " "), "", categories),
+		Offers: []types.Offer{{
+			ProductID:        id,
+			Store:            a.Name(),
+			Source:           a.Name(),
+			Price:            item.UnitPrice,
+			Currency:         money.KZT,
+			URL:              link,
+			DeliveryEstimate: item.DeliveryDuration,
+		}},
+	}
+}
@@ -0,0 +1,102 @@
+package marketplace
+
+import (
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/stemmer"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Основы короче этой длины совпадают только целиком, длиннее - и по префиксу
+const minPrefixStem = 4
+
+// Слова запроса весят больше интересов: запрос - то, что ищут прямо сейчас
+const (
+	queryWeight    = 2.0
+	interestWeight = 1.0
+)
+
+// keywordMatcher ищет основы слов запроса и интересов в названии
+// и описании товара
+type keywordMatcher struct {
+	query     []string
+	interests []string
+}
+
+func newKeywordMatcher(intent types.SearchIntent) keywordMatcher {
+	matcher := keywordMatcher{query: stemmer.Tokens(intent.Query)}
+	seen := make(map[string]bool)
+	for _, stem := range matcher.query {
+		seen[stem] = true
+	}
+	for _, interest := range intent.Interests {
+		for _, stem := range stemmer.Tokens(interest) {
+			if !seen[stem] {
+				seen[stem] = true
+				matcher.interests = append(matcher.interests, stem)
+			}
+		}
+	}
+	return matcher
+}
+
+func (m keywordMatcher) empty() bool {
+	return len(m.query) == 0 && len(m.interests) == 0
+}
+
+// matchesQuery требует хотя бы одно слово из текста запроса; без текста
+// запроса подходит любой товар
+func (m keywordMatcher) matchesQuery(product types.Product) bool {
+	if len(m.query) == 0 {
+		return true
+	}
+	return countMatches(m.query, productStems(product)) > 0
+}
+
+// matchesAny - совпало ли хоть одно слово запроса или интересов
+func (m keywordMatcher) matchesAny(product types.Product) bool {
+	stems := productStems(product)
+	return countMatches(m.query, stems)+countMatches(m.interests, stems) > 0
+}
+
+// score - доля совпавших слов с учетом веса запроса и интересов (0..1)
+func (m keywordMatcher) score(product types.Product) float64 {
+	total := queryWeight*float64(len(m.query)) + interestWeight*float64(len(m.interests))
+	if total == 0 {
+		return 0
+	}
+	stems := productStems(product)
+	matched := queryWeight*float64(countMatches(m.query, stems)) +
+		interestWeight*float64(countMatches(m.interests, stems))
+	return matched / total
+}
+
+func productStems(product types.Product) []string {
+	return stemmer.Tokens(product.Title + " " + product.Description)
+}
+
+func countMatches(keywords, stems []string) int {
+	count := 0
+	for _, keyword := range keywords {
+		for _, stem := range stems {
+			if stemsMatch(keyword, stem) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// stemsMatch сравнивает основы; длинные основы могут отличаться хвостом,
+// который стеммер не отрезал ("игрушк" и "игрушечн" не совпадут, а
+// "смартфон" и "смартфонн" - да)
+func stemsMatch(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if len([]rune(a)) > len([]rune(b)) {
+		a, b = b, a
+	}
+	return len([]rune(a)) >= minPrefixStem && strings.HasPrefix(b, a)
+}
@@ -0,0 +1,286 @@
+package marketplace
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Варианты сортировки результатов поиска
+const (
+	SortRelevance = "relevance"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortRating    = "rating"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ErrInvalidCursor возвращается для поврежденного курсора или курсора от другой сортировки
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListOptions - сортировка и страница результатов
+type ListOptions struct {
+	Sort   string
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// Page - страница результатов с фасетами по всей выборке
+type Page struct {
+	Products   []types.Product
+	Groups     []types.ProductGroup
+	Total      int
+	Limit      int
+	Offset     int
+	NextCursor string
+	Facets     types.Facets
+}
+
+type pageCursor struct {
+	Offset int    `json:"o"`
+	Sort   string `json:"s"`
+}
+
+// Границы ценовых корзин в тенге; для других валют пересчитываются по курсу
+var priceBucketBoundsKZT = []float64{5000, 10000, 25000, 50000, 100000}
+
+// NormalizeListOptions проверяет параметры и подставляет значения по умолчанию
+func NormalizeListOptions(options ListOptions) (ListOptions, error) {
+	switch options.Sort {
+	case "":
+		options.Sort = SortRelevance
+	case SortRelevance, SortPriceAsc, SortPriceDesc, SortRating:
+	default:
+		return options, fmt.Errorf("unsupported sort: %s", options.Sort)
+	}
+
+	if options.Limit <= 0 {
+		options.Limit = DefaultPageSize
+	}
+	if options.Limit > MaxPageSize {
+		options.Limit = MaxPageSize
+	}
+	if options.Offset < 0 {
+		return options, fmt.Errorf("offset must not be negative")
+	}
+
+	if options.Cursor != "" {
+		cursor, err := decodeCursor(options.Cursor)
+		if err != nil || cursor.Sort != options.Sort || cursor.Offset < 0 {
+			return options, ErrInvalidCursor
+		}
+		options.Offset = cursor.Offset
+	}
+
+	return options, nil
+}
+
+// List сортирует результат поиска, считает фасеты и вырезает страницу.
+// Группы сравнения цен возвращаются только для товаров текущей страницы.
+func (s *ProductService) List(result *SearchResult, options ListOptions) (*Page, error) {
+	options, err := NormalizeListOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := result.Currency
+	if currency == "" {
+		currency = money.KZT
+	}
+
+	products := make([]types.Product, len(result.Products))
+	copy(products, result.Products)
+	s.sortProducts(products, options.Sort, currency)
+
+	page := &Page{
+		Total:  len(products),
+		Limit:  options.Limit,
+		Offset: options.Offset,
+		Facets: s.facets(products, currency),
+	}
+
+	start := options.Offset
+	if start > len(products) {
+		start = len(products)
+	}
+	end := start + options.Limit
+	if end > len(products) {
+		end = len(products)
+	}
+	page.Products = products[start:end]
+
+	if end < len(products) {
+		page.NextCursor = encodeCursor(pageCursor{Offset: end, Sort: options.Sort})
+	}
+
+	onPage := make(map[string]bool, len(page.Products))
+	for _, product := range page.Products {
+		onPage[product.ID] = true
+	}
+	for _, group := range result.Groups {
+		for _, id := range group.ProductIDs {
+			if onPage[id] {
+				page.Groups = append(page.Groups, group)
+				break
+			}
+		}
+	}
+
+	return page, nil
+}
+
+// sortProducts сортирует стабильно: при равенстве сохраняется порядок
+// релевантности, в котором товары пришли из источников
+func (s *ProductService) sortProducts(products []types.Product, order, currency string) {
+	switch order {
+	case SortRelevance:
+		// Совпадение со словами запроса важнее порядка источников
+		if s.ranker == nil {
+			sort.SliceStable(products, func(i, j int) bool {
+				return products[i].Relevance > products[j].Relevance
+			})
+			return
+		}
+		// CTR категории и магазина меняет вес совпадения, но не обнуляет
+		// товары без совпадений: у них базовая релевантность 1
+		scores := make(map[string]float64, len(products))
+		for _, product := range products {
+			scores[product.ID] = (1 + product.Relevance) * s.ranker.Boost(product)
+		}
+		sort.SliceStable(products, func(i, j int) bool {
+			return scores[products[i].ID] > scores[products[j].ID]
+		})
+	case SortPriceAsc, SortPriceDesc:
+		prices := make(map[string]float64, len(products))
+		for _, product := range products {
+			prices[product.ID] = s.priceIn(product, currency)
+		}
+		sort.SliceStable(products, func(i, j int) bool {
+			pi, pj := prices[products[i].ID], prices[products[j].ID]
+			// Товары без цены всегда в конце списка
+			if pi <= 0 || pj <= 0 {
+				return pi > 0 && pj <= 0
+			}
+			if order == SortPriceAsc {
+				return pi < pj
+			}
+			return pi > pj
+		})
+	case SortRating:
+		sort.SliceStable(products, func(i, j int) bool {
+			return products[i].Rating > products[j].Rating
+		})
+	}
+}
+
+// priceIn возвращает цену товара в валюте запроса или 0, если ее не перевести
+func (s *ProductService) priceIn(product types.Product, currency string) float64 {
+	from := product.Currency
+	if from == "" {
+		from = money.StoreCurrency(product.Store)
+	}
+	price, err := s.rates.Convert(product.Price, from, currency)
+	if err != nil {
+		return 0
+	}
+	return price
+}
+
+func (s *ProductService) facets(products []types.Product, currency string) types.Facets {
+	stores := make(map[string]int)
+	categories := make(map[string]int)
+
+	bounds := make([]float64, len(priceBucketBoundsKZT))
+	for i, bound := range priceBucketBoundsKZT {
+		converted, err := s.rates.Convert(bound, money.KZT, currency)
+		if err != nil {
+			converted = bound
+		}
+		bounds[i] = roundBound(converted)
+	}
+	buckets := make([]types.PriceBucket, len(bounds)+1)
+	for i := range buckets {
+		if i > 0 {
+			buckets[i].Min = bounds[i-1]
+		}
+		if i < len(bounds) {
+			buckets[i].Max = bounds[i]
+		}
+	}
+
+	for _, product := range products {
+		if store := effectiveStore(product); store != "" {
+			stores[store]++
+		}
+		if product.Category != "" {
+			categories[product.Category]++
+		}
+
+		price := s.priceIn(product, currency)
+		if price <= 0 {
+			continue
+		}
+		i := sort.SearchFloat64s(bounds, price)
+		// Граница корзины относится к следующей корзине: [min, max)
+		if i < len(bounds) && bounds[i] == price {
+			i++
+		}
+		buckets[i].Count++
+	}
+
+	return types.Facets{
+		Stores:       facetCounts(stores),
+		Categories:   facetCounts(categories),
+		PriceBuckets: buckets,
+		Currency:     currency,
+	}
+}
+
+func facetCounts(counts map[string]int) []types.FacetCount {
+	facets := make([]types.FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, types.FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	return facets
+}
+
+// roundBound округляет границу корзины до двух значащих цифр
+func roundBound(v float64) float64 {
+	if v <= 0 {
+		return 0
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v))-1)
+	return math.Round(v/magnitude) * magnitude
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (pageCursor, error) {
+	var cursor pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
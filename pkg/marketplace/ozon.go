@@ -0,0 +1,172 @@
+package marketplace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+const ozonBaseURL = "https://api-seller.ozon.ru"
+
+// OzonAdapter работает через Seller API: сначала получает список товаров,
+// затем запрашивает по ним подробную информацию. Публичного API поиска по
+// всему Ozon нет, и /v3/product/list возвращает только каталог продавца с
+// ключом OZON_CLIENT_ID (первые 100 видимых товаров), а не выдачу Ozon.
+// Адаптер полезен для магазина-партнера; остальные товары Ozon находит
+// поиск Serper.
+type OzonAdapter struct {
+	clientID string
+	apiKey   string
+	baseURL  string
+	client   httpclient.Doer
+}
+
+type ozonListRequest struct {
+	Filter struct {
+		Visibility string `json:"visibility"`
+	} `json:"filter"`
+	LastID string `json:"last_id"`
+	Limit  int    `json:"limit"`
+}
+
+type ozonListResponse struct {
+	Result struct {
+		Items []struct {
+			ProductID int64  `json:"product_id"`
+			OfferID   string `json:"offer_id"`
+		} `json:"items"`
+		Total  int    `json:"total"`
+		LastID string `json:"last_id"`
+	} `json:"result"`
+}
+
+type ozonInfoRequest struct {
+	ProductID []int64 `json:"product_id"`
+}
+
+type ozonInfoResponse struct {
+	Items []ozonProduct `json:"items"`
+}
+
+type ozonProduct struct {
+	ID           int64    `json:"id"`
+	Name         string   `json:"name"`
+	OfferID      string   `json:"offer_id"`
+	SKU          int64    `json:"sku"`
+	Price        string   `json:"price"`
+	CurrencyCode string   `json:"currency_code"`
+	PrimaryImage []string `json:"primary_image"`
+	Images       []string `json:"images"`
+}
+
+func NewOzonAdapter(client httpclient.Doer, clientID, apiKey string) *OzonAdapter {
+	return &OzonAdapter{
+		clientID: clientID,
+		apiKey:   apiKey,
+		baseURL:  envOrDefault("OZON_API_URL", ozonBaseURL),
+		client:   client,
+	}
+}
+
+func (a *OzonAdapter) Name() string { return "ozon" }
+
+func (a *OzonAdapter) Configured() bool { return a.clientID != "" && a.apiKey != "" }
+
+func (a *OzonAdapter) Search(ctx context.Context, categories []string, priceRange types.Range) ([]types.Product, error) {
+	listReq := ozonListRequest{Limit: 100}
+	listReq.Filter.Visibility = "VISIBLE"
+
+	var list ozonListResponse
+	if err := a.post(ctx, "/v3/product/list", listReq, &list); err != nil {
+		return nil, err
+	}
+	if len(list.Result.Items) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, 0, len(list.Result.Items))
+	for _, item := range list.Result.Items {
+		ids = append(ids, item.ProductID)
+	}
+
+	var info ozonInfoResponse
+	if err := a.post(ctx, "/v3/product/info/list", ozonInfoRequest{ProductID: ids}, &info); err != nil {
+		return nil, err
+	}
+
+	// Seller API не фильтрует по категориям и цене, поэтому отбираем сами
+	products := make([]types.Product, 0, len(info.Items))
+	for _, item := range info.Items {
+		product := a.toProduct(item, categories)
+		if !containsFold(categories, product.Category) {
+			continue
+		}
+		if priceRange.Min > 0 && product.Price < priceRange.Min {
+			continue
+		}
+		if priceRange.Max > 0 && product.Price > priceRange.Max {
+			continue
+		}
+		products = append(products, product)
+	}
+
+	return products, nil
+}
+
+func (a *OzonAdapter) post(ctx context.Context, path string, body interface{}, out interface{}) error {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", a.baseURL+path, bytes.NewReader(jsonBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Client-Id", a.clientID)
+	req.Header.Set("Api-Key", a.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return doJSON(a.client, req, a.Name(), out)
+}
+
+func (a *OzonAdapter) toProduct(item ozonProduct, categories []string) types.Product {
+	// Seller API отдает цену машинной строкой "3990.000": точка всегда
+	// десятичная, поэтому money.Parse с его эвристикой разрядов не подходит
+	price, err := strconv.ParseFloat(strings.TrimSpace(item.Price), 64)
+	if err != nil {
+		price = 0
+	}
+	currency := money.RUB
+	if item.CurrencyCode != "" {
+		currency = strings.ToUpper(item.CurrencyCode)
+	}
+
+	var image string
+	if len(item.PrimaryImage) > 0 {
+		image = item.PrimaryImage[0]
+	} else if len(item.Images) > 0 {
+		image = item.Images[0]
+	}
+
+	sku := strconv.FormatInt(item.SKU, 10)
+
+	return types.Product{
+		ID:          "ozon-" + sku,
+		Title:       item.Name,
+		Description: item.Name,
+		Price:       price,
+		Currency:    currency,
+		URL:         "https://www.ozon.ru/product/" + sku + "/",
+		ImageURL:    image,
+		Store:       a.Name(),
+		Category:    inferCategory(item.Name, "", categories),
+	}
+}
@@ -7,33 +7,46 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
 )
 
-const serperEndpoint = "https://google.serper.dev/search"
+const serperEndpoint = "https://google.serper.dev/shopping"
 
 type SerperService struct {
-	apiKey string
-	client *http.Client
+	apiKey   string
+	endpoint string
+	client   httpclient.Doer
+	budget   *SerperBudget
 }
 
 type serperRequest struct {
-	Q string `json:"q"`
+	Q   string `json:"q"`
+	GL  string `json:"gl"`  // Геолокация
+	HL  string `json:"hl"`  // Язык результатов
+	Num int    `json:"num"` // Количество результатов
+}
+
+type serperShoppingItem struct {
+	Title       string  `json:"title"`
+	Source      string  `json:"source"`
+	Link        string  `json:"link"`
+	Price       string  `json:"price"`
+	Delivery    string  `json:"delivery,omitempty"`
+	ImageURL    string  `json:"imageUrl,omitempty"`
+	Rating      float64 `json:"rating,omitempty"`
+	RatingCount int     `json:"ratingCount,omitempty"`
+	ProductID   string  `json:"productId,omitempty"`
+	Position    int     `json:"position"`
 }
 
 type serperResponse struct {
-	OrganicResults []struct {
-		Title       string `json:"title"`
-		Link        string `json:"link"`
-		Snippet     string `json:"snippet"`
-		ImageURL    string `json:"imageUrl,omitempty"`
-		Price       string `json:"price,omitempty"`
-		Currency    string `json:"currency,omitempty"`
-		Marketplace string `json:"marketplace,omitempty"`
-	} `json:"organic"`
+	Shopping []serperShoppingItem `json:"shopping"`
 }
 
 func NewSerperService() (*SerperService, error) {
@@ -42,16 +55,37 @@ func NewSerperService() (*SerperService, error) {
 		return nil, fmt.Errorf("SERPER_API_KEY environment variable is not set")
 	}
 
+	endpoint := os.Getenv("SERPER_ENDPOINT")
+	if endpoint == "" {
+		endpoint = serperEndpoint
+	}
+
 	return &SerperService{
-		apiKey: apiKey,
-		client: &http.Client{},
+		apiKey:   apiKey,
+		endpoint: endpoint,
+		client:   httpclient.Default(),
 	}, nil
 }
 
-func (s *SerperService) SearchProducts(ctx context.Context, query string) ([]types.Product, error) {
+// SetBudget включает ограничение частоты и квоту запросов
+func (s *SerperService) SetBudget(budget *SerperBudget) {
+	s.budget = budget
+}
+
+// SearchProducts ищет товары через shopping-выдачу Serper. Категории нужны,
+// чтобы проставить Product.Category, которой в ответе Serper нет.
+func (s *SerperService) SearchProducts(ctx context.Context, query string, categories []string) ([]types.Product, error) {
+	// Каждый запрос платный: сначала проверяем лимиты
+	if err := s.budget.Acquire(ctx); err != nil {
+		return nil, err
+	}
+
 	// Формируем запрос к Serper API
 	reqBody := serperRequest{
-		Q: query,
+		Q:   query,
+		GL:  "kz",
+		HL:  "ru",
+		Num: 20,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -59,7 +93,7 @@ func (s *SerperService) SearchProducts(ctx context.Context, query string) ([]typ
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", serperEndpoint, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -79,7 +113,7 @@ func (s *SerperService) SearchProducts(ctx context.Context, query string) ([]typ
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("serper API error: %s", string(body))
+		return nil, fmt.Errorf("serper API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var serperResp serperResponse
@@ -87,40 +121,79 @@ func (s *SerperService) SearchProducts(ctx context.Context, query string) ([]typ
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Преобразуем результаты в нашу структуру Product
-	var products []types.Product
-	for _, item := range serperResp.OrganicResults {
-		// Извлекаем цену из строки
-		var price float64
-		if item.Price != "" {
-			fmt.Sscanf(strings.TrimSpace(strings.ReplaceAll(item.Price, ",", "")), "%f", &price)
+	return mapSerperShopping(serperResp.Shopping, query, categories), nil
+}
+
+// mapSerperShopping преобразует результаты Serper в нашу структуру Product
+func mapSerperShopping(items []serperShoppingItem, query string, categories []string) []types.Product {
+	products := make([]types.Product, 0, len(items))
+	for _, item := range items {
+		if item.Link == "" || item.Title == "" {
+			continue
 		}
 
-		// Определяем магазин из URL
-		store := "unknown"
-		if strings.Contains(item.Link, "kaspi.kz") {
-			store = "kaspi"
-		} else if strings.Contains(item.Link, "wildberries") {
-			store = "wildberries"
-		} else if strings.Contains(item.Link, "aliexpress") {
-			store = "aliexpress"
-		} else if strings.Contains(item.Link, "ozon") {
-			store = "ozon"
+		store := detectStore(item.Link, item.Source)
+
+		// Не распознанная цена остается нулевой: такие товары отсеет ценовой фильтр
+		price, err := money.Parse(item.Price, money.LocaleKZ)
+		if err != nil {
+			price.Currency = money.StoreCurrency(store)
 		}
 
 		product := types.Product{
 			ID:          item.Link, // Используем URL как ID
 			Title:       item.Title,
-			Description: item.Snippet,
-			Price:       price,
+			Description: item.Title,
+			Price:       price.Value,
+			Currency:    price.Currency,
+			Rating:      item.Rating,
 			URL:         item.Link,
 			ImageURL:    item.ImageURL,
 			Store:       store,
-			Rating:      0, // У Serper нет информации о рейтинге
+			Category:    inferCategory(item.Title, query, categories),
+			Offers: []types.Offer{{
+				ProductID:        item.Link,
+				Store:            store,
+				Source:           "serper",
+				Price:            price.Value,
+				Currency:         price.Currency,
+				URL:              item.Link,
+				DeliveryEstimate: item.Delivery,
+			}},
 		}
 
 		products = append(products, product)
 	}
 
-	return products, nil
+	return products
+}
+
+// detectStore определяет магазин по домену ссылки, а если он не известен -
+// по названию источника из выдачи
+func detectStore(link, source string) string {
+	host := strings.ToLower(link)
+	if u, err := url.Parse(link); err == nil && u.Host != "" {
+		host = strings.ToLower(u.Host)
+	}
+
+	knownStores := []struct {
+		marker string
+		store  string
+	}{
+		{"kaspi", "kaspi"},
+		{"wildberries", "wildberries"},
+		{"wb.ru", "wildberries"},
+		{"aliexpress", "aliexpress"},
+		{"ozon", "ozon"},
+	}
+
+	for _, candidate := range []string{host, strings.ToLower(source)} {
+		for _, known := range knownStores {
+			if strings.Contains(candidate, known.marker) {
+				return known.store
+			}
+		}
+	}
+
+	return "unknown"
 }
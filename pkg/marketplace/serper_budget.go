@@ -0,0 +1,113 @@
+package marketplace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/quota"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// SerperBudget ограничивает частоту и общее число платных запросов к Serper
+// для одного API ключа. Его используют и SerperService, и AISearchService.
+type SerperBudget struct {
+	limiter *ratelimit.TokenBucket
+	tracker *quota.Tracker
+}
+
+// NewSerperBudget создает бюджет для ключа. Лимиты читаются из SERPER_RATE_PER_SECOND,
+// SERPER_BURST, SERPER_DAILY_LIMIT, SERPER_MONTHLY_LIMIT и SERPER_SOFT_LIMIT_RATIO.
+func NewSerperBudget(apiKey string, dynamoClient *dynamodb.Client) *SerperBudget {
+	rate := 5.0
+	if v := os.Getenv("SERPER_RATE_PER_SECOND"); v != "" {
+		if r, err := strconv.ParseFloat(v, 64); err == nil && r > 0 {
+			rate = r
+		} else {
+			log.Printf("Invalid SERPER_RATE_PER_SECOND: %q", v)
+		}
+	}
+
+	burst := 5
+	if v := os.Getenv("SERPER_BURST"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			burst = n
+		} else {
+			log.Printf("Invalid SERPER_BURST: %q", v)
+		}
+	}
+
+	scope := serperQuotaScope(apiKey)
+	budget := &SerperBudget{
+		limiter: ratelimit.ForKey(scope, rate, burst),
+	}
+	if dynamoClient != nil {
+		budget.tracker = quota.NewTracker(dynamoClient, quota.TableName(), scope, quota.LimitsFromEnv("SERPER"))
+	}
+
+	return budget
+}
+
+// NewSerperBudgetFromEnv создает бюджет для SERPER_API_KEY или возвращает nil,
+// если ключ не задан
+func NewSerperBudgetFromEnv(dynamoClient *dynamodb.Client) *SerperBudget {
+	apiKey := os.Getenv("SERPER_API_KEY")
+	if apiKey == "" {
+		return nil
+	}
+	return NewSerperBudget(apiKey, dynamoClient)
+}
+
+// serperQuotaScope не хранит сам ключ: в таблицу попадает только его хеш
+func serperQuotaScope(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "serper#" + hex.EncodeToString(sum[:8])
+}
+
+// Acquire ждет разрешения лимитера и учитывает запрос в квоте.
+// При исчерпанной квоте возвращает quota.ErrExhausted.
+func (b *SerperBudget) Acquire(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	if err := b.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	if b.tracker == nil {
+		return nil
+	}
+
+	usage, err := b.tracker.Consume(ctx)
+	if errors.Is(err, quota.ErrExhausted) {
+		log.Printf("Serper quota exhausted: daily %d/%d, monthly %d/%d",
+			usage.Daily, usage.Limits.Daily, usage.Monthly, usage.Limits.Monthly)
+		return err
+	}
+	if err != nil {
+		// Недоступность таблицы квот не должна останавливать поиск
+		log.Printf("Failed to track Serper quota: %v", err)
+		return nil
+	}
+
+	if usage.SoftLimitReached() {
+		log.Printf("WARNING: Serper quota soft limit reached: daily %d/%d, monthly %d/%d",
+			usage.Daily, usage.Limits.Daily, usage.Monthly, usage.Limits.Monthly)
+	}
+
+	return nil
+}
+
+// Usage возвращает текущее использование квоты
+func (b *SerperBudget) Usage(ctx context.Context) (quota.Usage, error) {
+	if b == nil || b.tracker == nil {
+		return quota.Usage{}, errors.New("serper quota tracking is not configured")
+	}
+	return b.tracker.Usage(ctx)
+}
@@ -0,0 +1,74 @@
+package marketplace
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// go test ./pkg/marketplace -update перезаписывает golden-файлы
+var update = flag.Bool("update", false, "update golden files")
+
+func readFixture(t *testing.T, name string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+}
+
+// assertGolden сравнивает got с testdata/<name> как JSON
+func assertGolden(t *testing.T, name string, got interface{}) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create)", err)
+	}
+	if string(data) != string(want) {
+		t.Errorf("%s mismatch:\ngot:\n%s\nwant:\n%s", name, data, want)
+	}
+}
+
+func TestMapSerperShopping(t *testing.T) {
+	var response serperResponse
+	readFixture(t, "serper_shopping.json", &response)
+
+	products := mapSerperShopping(response.Shopping, "подарок маме наушники", []string{"electronics", "home"})
+	assertGolden(t, "serper_shopping.golden.json", products)
+}
+
+func TestInferCategory(t *testing.T) {
+	var cases []struct {
+		Name      string   `json:"name"`
+		Title     string   `json:"title"`
+		Query     string   `json:"query"`
+		Requested []string `json:"requested"`
+		Want      string   `json:"want"`
+	}
+	readFixture(t, "infer_category.json", &cases)
+
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := inferCategory(tt.Title, tt.Query, tt.Requested); got != tt.Want {
+				t.Errorf("inferCategory(%q, %q, %q) = %q, want %q", tt.Title, tt.Query, tt.Requested, got, tt.Want)
+			}
+		})
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/cache"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/queryplan"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -15,10 +19,33 @@ import (
 type ProductService struct {
 	dynamoClient  *dynamodb.Client
 	serperService *SerperService
+	webSearch     *WebSearchService
 	tableName     string
+	cache         cache.Cache
+	cacheTTL      map[string]time.Duration
+	rates         money.RateTable
+	policy        FailurePolicy
+	planner       *queryplan.Planner
+	ranker        Ranker
 }
 
-func NewProductService(dynamoClient *dynamodb.Client) *ProductService {
+// Ranker корректирует релевантность товара по обратной связи пользователей
+type Ranker interface {
+	Boost(product types.Product) float64
+}
+
+// SearchResult - результат поиска вместе со статусами источников и метаданными кэша
+type SearchResult struct {
+	Products []types.Product
+	Groups   []types.ProductGroup
+	Sources  []types.SourceStatus
+	Cache    *types.CacheInfo
+	Currency string // Валюта запроса, в которой сравниваются цены
+}
+
+// NewProductService создает сервис поиска; resultCache может быть nil,
+// тогда каждый запрос идет напрямую в источники.
+func NewProductService(dynamoClient *dynamodb.Client, resultCache cache.Cache) *ProductService {
 	tableName := os.Getenv("DYNAMODB_TABLE")
 	if tableName == "" {
 		tableName = "products" // значение по умолчанию
@@ -28,138 +55,240 @@ func NewProductService(dynamoClient *dynamodb.Client) *ProductService {
 	if err != nil {
 		// Логируем ошибку, но продолжаем работу только с DynamoDB
 		fmt.Printf("Failed to initialize Serper service: %v\n", err)
+	} else {
+		serperService.SetBudget(NewSerperBudget(serperService.apiKey, dynamoClient))
+	}
+
+	rates, err := money.LoadRates()
+	if err != nil {
+		fmt.Printf("Failed to load currency rates, using defaults: %v\n", err)
+		rates = money.DefaultRates
 	}
+	planner := queryplan.NewDefault()
+	planner.SetRates(rates)
 
 	return &ProductService{
 		dynamoClient:  dynamoClient,
 		serperService: serperService,
+		webSearch:     NewWebSearchServiceFromEnv(),
 		tableName:     tableName,
+		cache:         resultCache,
+		cacheTTL:      loadCacheTTL(),
+		rates:         rates,
+		policy:        ParseFailurePolicy(os.Getenv("SEARCH_FAILURE_POLICY")),
+		planner:       planner,
 	}
 }
 
-func (s *ProductService) SearchProducts(ctx context.Context, categories []string, priceRange types.Range, marketplace string) ([]types.Product, error) {
+// SetRanker включает ранжирование по обратной связи
+func (s *ProductService) SetRanker(ranker Ranker) {
+	s.ranker = ranker
+}
+
+// SearchProducts ищет товары по категориям, свободному тексту и интересам.
+// Текст запроса обязателен к совпадению в DynamoDB и API маркетплейсов,
+// интересы только поднимают подходящие товары выше.
+func (s *ProductService) SearchProducts(ctx context.Context, intent types.SearchIntent) (*SearchResult, error) {
+	if intent.PriceRange.Currency == "" {
+		intent.PriceRange.Currency = money.KZT
+	}
+	// С неизвестной валютой не прошел бы фильтр ни один товар
+	intent.PriceRange.Currency = strings.ToUpper(intent.PriceRange.Currency)
+	if !s.rates.Has(intent.PriceRange.Currency) {
+		return nil, fmt.Errorf("%w: %s", money.ErrUnknownCurrency, intent.PriceRange.Currency)
+	}
+	matcher := newKeywordMatcher(intent)
 	var allProducts []types.Product
+	var sources []types.SourceStatus
+	cacheInfo := &types.CacheInfo{Hit: true}
 
-	// Поиск в DynamoDB
-	dbProducts, err := s.searchInDynamoDB(ctx, categories, priceRange, marketplace)
-	if err != nil {
-		fmt.Printf("DynamoDB search error: %v\n", err)
-		// Продолжаем работу, даже если DynamoDB недоступен
-	} else {
-		allProducts = append(allProducts, dbProducts...)
+	// Поиск в DynamoDB. Ошибка не прерывает поиск, она попадает в статус источника
+	dbProducts, dbStatus := runSource("dynamodb", func() ([]types.Product, error) {
+		key := cacheKey("dynamodb", intent)
+		products, cacheStatus, err := s.cachedSearch(ctx, "dynamodb", key, func() ([]types.Product, error) {
+			return s.searchInDynamoDB(ctx, intent, matcher)
+		})
+		cacheInfo.Sources = append(cacheInfo.Sources, cacheStatus)
+		cacheInfo.Hit = cacheInfo.Hit && cacheStatus.Hit
+		return products, err
+	})
+	if dbStatus.Status != types.SourceOK {
+		fmt.Printf("DynamoDB search error: %s\n", dbStatus.Error)
 	}
+	allProducts = append(allProducts, dbProducts...)
+	sources = append(sources, dbStatus)
 
 	// Если Serper сервис доступен, используем его для поиска
 	if s.serperService != nil {
-		// Формируем поисковый запрос
-		query := s.buildSearchQuery(categories, priceRange, marketplace)
+		serperProducts, serperStatus := runSource("serper", func() ([]types.Product, error) {
+			key := cacheKey("serper", intent)
+			products, cacheStatus, err := s.cachedSearch(ctx, "serper", key, func() ([]types.Product, error) {
+				return s.searchInSerper(ctx, intent)
+			})
+			cacheInfo.Sources = append(cacheInfo.Sources, cacheStatus)
+			cacheInfo.Hit = cacheInfo.Hit && cacheStatus.Hit
+			return products, err
+		})
+		if serperStatus.Status != types.SourceOK {
+			fmt.Printf("Serper search error: %s\n", serperStatus.Error)
+		}
+		allProducts = append(allProducts, serperProducts...)
+		sources = append(sources, serperStatus)
+	} else {
+		sources = append(sources, skippedSource("serper", "SERPER_API_KEY is not set"))
+	}
 
-		serperProducts, err := s.serperService.SearchProducts(ctx, query)
+	// Поиск через API маркетплейсов, если выбранный маркетплейс поддерживается.
+	// API маркетплейсов ищут по категориям, поэтому без категорий их не опрашиваем.
+	if len(intent.Categories) > 0 && (intent.Marketplace == "" || s.webSearch.Supports(intent.Marketplace)) {
+		webResult, err := s.webSearch.SearchProducts(ctx, intent.Categories, intent.PriceRange, intent.Marketplace)
 		if err != nil {
-			fmt.Printf("Serper search error: %v\n", err)
-		} else {
-			// Фильтруем результаты по категориям и ценовому диапазону
-			for _, product := range serperProducts {
-				if s.matchesFilters(product, categories, priceRange, marketplace) {
+			fmt.Printf("Marketplace search error: %v\n", err)
+		}
+		if webResult != nil {
+			for _, product := range webResult.Products {
+				if s.matchesFilters(product, intent) && matcher.matchesQuery(product) {
 					allProducts = append(allProducts, product)
 				}
 			}
+			sources = append(sources, webResult.Sources...)
 		}
 	}
 
-	// Удаляем дубликаты
-	return s.removeDuplicates(allProducts), nil
-}
+	if s.cache == nil {
+		cacheInfo = nil
+	}
 
-func (s *ProductService) buildSearchQuery(categories []string, priceRange types.Range, marketplace string) string {
-	// Базовый запрос
-	query := strings.Join(categories, " OR ")
+	// Объединяем дубликаты из разных источников, затем сравниваем цены между магазинами
+	products := deduplicate(allProducts, s.rates)
+	if !matcher.empty() {
+		for i := range products {
+			products[i].Relevance = matcher.score(products[i])
+		}
+	}
 
-	// Добавляем ценовой диапазон
-	if priceRange.Min > 0 || priceRange.Max > 0 {
-		query += fmt.Sprintf(" price:%d..%d", int(priceRange.Min), int(priceRange.Max))
+	result := &SearchResult{
+		Products: products,
+		Groups:   compareOffers(products, s.rates, intent.PriceRange.Currency),
+		Sources:  sources,
+		Cache:    cacheInfo,
+		Currency: intent.PriceRange.Currency,
 	}
 
-	// Добавляем маркетплейс
-	if marketplace != "" {
-		query += " site:" + marketplace
+	if s.policy == FailWhenAllFail && allSourcesFailed(sources) {
+		return result, ErrAllSourcesFailed
 	}
 
-	return query
+	return result, nil
 }
 
-func (s *ProductService) matchesFilters(product types.Product, categories []string, priceRange types.Range, marketplace string) bool {
-	// Проверка категории
-	categoryMatch := false
-	for _, category := range categories {
-		if strings.Contains(strings.ToLower(product.Category), strings.ToLower(category)) {
-			categoryMatch = true
-			break
+func (s *ProductService) searchInSerper(ctx context.Context, intent types.SearchIntent) ([]types.Product, error) {
+	// Формируем поисковый запрос
+	plan, err := s.planner.Plan(queryplan.ProviderSerper, intent)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.serperService.SearchProducts(ctx, plan.Query, intent.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	// Фильтруем результаты по категориям и ценовому диапазону. Текст запроса
+	// уже учтен самим Serper, поэтому по словам здесь не фильтруем.
+	var filtered []types.Product
+	for _, product := range products {
+		if s.matchesFilters(product, intent) {
+			filtered = append(filtered, product)
 		}
 	}
-	if !categoryMatch {
-		return false
+	return filtered, nil
+}
+
+// matchesFilters проверяет категорию (если категории заданы), цену и маркетплейс
+func (s *ProductService) matchesFilters(product types.Product, intent types.SearchIntent) bool {
+	// Проверка категории
+	if len(intent.Categories) > 0 {
+		categoryMatch := false
+		for _, category := range intent.Categories {
+			if strings.Contains(strings.ToLower(product.Category), strings.ToLower(category)) {
+				categoryMatch = true
+				break
+			}
+		}
+		if !categoryMatch {
+			return false
+		}
 	}
 
 	// Проверка цены
-	if priceRange.Min > 0 && product.Price < priceRange.Min {
-		return false
-	}
-	if priceRange.Max > 0 && product.Price > priceRange.Max {
+	if !s.matchesPrice(product, intent.PriceRange) {
 		return false
 	}
 
 	// Проверка маркетплейса
-	if marketplace != "" && product.Store != marketplace {
+	if intent.Marketplace != "" && product.Store != intent.Marketplace {
 		return false
 	}
 
 	return true
 }
 
-func (s *ProductService) removeDuplicates(products []types.Product) []types.Product {
-	seen := make(map[string]bool)
-	unique := make([]types.Product, 0)
-
-	for _, product := range products {
-		if !seen[product.ID] {
-			seen[product.ID] = true
-			unique = append(unique, product)
-		}
+// matchesPrice сравнивает цену товара с диапазоном в валюте запроса
+func (s *ProductService) matchesPrice(product types.Product, priceRange types.Range) bool {
+	if priceRange.Min <= 0 && priceRange.Max <= 0 {
+		return true
 	}
 
-	return unique
-}
-
-func (s *ProductService) searchInDynamoDB(ctx context.Context, categories []string, priceRange types.Range, marketplace string) ([]types.Product, error) {
-	// Создаем условия фильтрации
-	filterExpr := "category IN (:categories)"
-	if marketplace != "" {
-		filterExpr += " AND marketplace = :marketplace"
+	currency := product.Currency
+	if currency == "" {
+		currency = money.StoreCurrency(product.Store)
 	}
-	filterExpr += " AND price BETWEEN :min_price AND :max_price"
 
-	// Подготавливаем значения для условий
-	categoriesValues := make([]dyntypes.AttributeValue, len(categories))
-	for i, cat := range categories {
-		categoriesValues[i] = &dyntypes.AttributeValueMemberS{Value: cat}
+	price, err := s.rates.Convert(product.Price, currency, priceRange.Currency)
+	if err != nil {
+		fmt.Printf("Price conversion error for %s: %v\n", product.ID, err)
+		return false
 	}
 
-	exprValues := map[string]dyntypes.AttributeValue{
-		":categories": &dyntypes.AttributeValueMemberL{Value: categoriesValues},
-		":min_price":  &dyntypes.AttributeValueMemberN{Value: fmt.Sprintf("%f", priceRange.Min)},
-		":max_price":  &dyntypes.AttributeValueMemberN{Value: fmt.Sprintf("%f", priceRange.Max)},
+	if priceRange.Min > 0 && price < priceRange.Min {
+		return false
+	}
+	if priceRange.Max > 0 && price > priceRange.Max {
+		return false
 	}
 
-	if marketplace != "" {
-		exprValues[":marketplace"] = &dyntypes.AttributeValueMemberS{Value: marketplace}
+	return true
+}
+
+func (s *ProductService) searchInDynamoDB(ctx context.Context, intent types.SearchIntent, matcher keywordMatcher) ([]types.Product, error) {
+	// Создаем условия фильтрации. Цена и слова запроса проверяются уже после
+	// чтения: товары в таблице могут быть в разных валютах, а стемминг
+	// DynamoDB не умеет.
+	var conditions []string
+	exprValues := make(map[string]dyntypes.AttributeValue, len(intent.Categories)+1)
+	if len(intent.Categories) > 0 {
+		placeholders := make([]string, len(intent.Categories))
+		for i, cat := range intent.Categories {
+			placeholder := fmt.Sprintf(":cat%d", i)
+			placeholders[i] = placeholder
+			exprValues[placeholder] = &dyntypes.AttributeValueMemberS{Value: cat}
+		}
+		conditions = append(conditions, "category IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if intent.Marketplace != "" {
+		conditions = append(conditions, "marketplace = :marketplace")
+		exprValues[":marketplace"] = &dyntypes.AttributeValueMemberS{Value: intent.Marketplace}
 	}
 
 	// Выполняем запрос к DynamoDB
 	input := &dynamodb.ScanInput{
-		TableName:                 &s.tableName,
-		FilterExpression:          &filterExpr,
-		ExpressionAttributeValues: exprValues,
+		TableName: &s.tableName,
+	}
+	if len(conditions) > 0 {
+		filterExpr := strings.Join(conditions, " AND ")
+		input.FilterExpression = &filterExpr
+		input.ExpressionAttributeValues = exprValues
 	}
 
 	result, err := s.dynamoClient.Scan(ctx, input)
@@ -174,10 +303,23 @@ func (s *ProductService) searchInDynamoDB(ctx context.Context, categories []stri
 		return nil, fmt.Errorf("failed to unmarshal products: %w", err)
 	}
 
-	// Помечаем продукты как из DynamoDB
-	for i := range products {
-		products[i].Store = "dynamodb"
+	// Помечаем продукты как из DynamoDB и отбрасываем не подходящие по цене
+	// и по словам. Без категорий товар должен совпасть хотя бы с одним
+	// словом запроса или интересов, иначе вернулась бы вся таблица.
+	filtered := products[:0]
+	for _, product := range products {
+		product.Store = "dynamodb"
+		if product.Currency == "" {
+			product.Currency = money.KZT
+		}
+		if !s.matchesPrice(product, intent.PriceRange) || !matcher.matchesQuery(product) {
+			continue
+		}
+		if len(intent.Categories) == 0 && !matcher.matchesAny(product) {
+			continue
+		}
+		filtered = append(filtered, product)
 	}
 
-	return products, nil
+	return filtered, nil
 }
@@ -0,0 +1,88 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/quota"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// FailurePolicy определяет, считать ли поиск неудачным при отказе источников
+type FailurePolicy int
+
+const (
+	// AllowPartial возвращает то, что удалось найти, даже если все источники упали
+	AllowPartial FailurePolicy = iota
+	// FailWhenAllFail возвращает ErrAllSourcesFailed, если ни один источник не ответил
+	FailWhenAllFail
+)
+
+// ErrAllSourcesFailed возвращается при политике FailWhenAllFail
+var ErrAllSourcesFailed = errors.New("all product sources failed")
+
+// ParseFailurePolicy разбирает значение SEARCH_FAILURE_POLICY
+func ParseFailurePolicy(v string) FailurePolicy {
+	if v == "fail-all" {
+		return FailWhenAllFail
+	}
+	return AllowPartial
+}
+
+// runSource выполняет поиск в источнике и заполняет его статус
+func runSource(source string, search func() ([]types.Product, error)) ([]types.Product, types.SourceStatus) {
+	start := time.Now()
+	products, err := search()
+
+	status := types.SourceStatus{
+		Source:    source,
+		Status:    types.SourceOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Count:     len(products),
+	}
+	if err != nil {
+		status.Status = sourceErrorStatus(err)
+		status.Error = err.Error()
+		status.Count = 0
+		return nil, status
+	}
+
+	return products, status
+}
+
+func skippedSource(source, reason string) types.SourceStatus {
+	return types.SourceStatus{
+		Source: source,
+		Status: types.SourceSkippedNoToken,
+		Error:  reason,
+	}
+}
+
+func sourceErrorStatus(err error) string {
+	// Исчерпанная квота - не отказ: источник сознательно пропущен
+	if errors.Is(err, quota.ErrExhausted) {
+		return types.SourceSkippedQuota
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return types.SourceTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return types.SourceTimeout
+	}
+	return types.SourceError
+}
+
+// allSourcesFailed сообщает, что ни один из опрошенных источников не ответил.
+// Пропущенные источники не учитываются, но если опрашивать было некого,
+// поиск тоже считается неудачным.
+func allSourcesFailed(statuses []types.SourceStatus) bool {
+	for _, status := range statuses {
+		if status.Status == types.SourceOK {
+			return false
+		}
+	}
+	return true
+}
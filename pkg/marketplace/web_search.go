@@ -2,301 +2,314 @@ package marketplace
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
-	"net/url"
-	"strings"
-	"sync"
+	"os"
+	"strconv"
+	"time"
 
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
 	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
 )
 
 type WebSearchService struct {
-	kaspiToken string
-	aliToken   string
-	wildToken  string
-	ozonToken  string
-	httpClient *http.Client
+	adapters []MarketplaceAdapter
+	rates    money.RateTable
+	policy   FailurePolicy
+	options  WebSearchOptions
 }
 
-func NewWebSearchService(kaspiToken, aliToken, wildToken, ozonToken string) *WebSearchService {
-	return &WebSearchService{
-		kaspiToken: kaspiToken,
-		aliToken:   aliToken,
-		wildToken:  wildToken,
-		ozonToken:  ozonToken,
-		httpClient: &http.Client{},
-	}
+// WebSearchOptions ограничивает время опроса маркетплейсов
+type WebSearchOptions struct {
+	// SourceTimeout - максимальное время ответа одного маркетплейса
+	SourceTimeout time.Duration
+	// Budget - общее время поиска, после которого возвращаются частичные результаты
+	Budget time.Duration
+	// SafetyMargin резервируется до дедлайна запроса на формирование ответа
+	SafetyMargin time.Duration
+	// HedgeDelay - через сколько повторить запрос к еще не ответившему
+	// маркетплейсу; 0 отключает хеджирование
+	HedgeDelay time.Duration
+	// MaxHedges - сколько самых медленных маркетплейсов можно продублировать
+	MaxHedges int
 }
 
-func (s *WebSearchService) SearchProducts(ctx context.Context, categories []string, priceRange types.Range, marketplace string) ([]types.Product, error) {
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	var allProducts []types.Product
-	errors := make(chan error, 4)
+// DefaultWebSearchOptions укладываются в стандартный таймаут API Gateway (29 с)
+var DefaultWebSearchOptions = WebSearchOptions{
+	SourceTimeout: 8 * time.Second,
+	Budget:        10 * time.Second,
+	SafetyMargin:  500 * time.Millisecond,
+	MaxHedges:     1,
+}
 
-	// Если указан конкретный маркетплейс, ищем только в нем
-	if marketplace != "" {
-		switch marketplace {
-		case "kaspi":
-			return s.searchKaspi(ctx, categories, priceRange)
-		case "aliexpress":
-			return s.searchAliExpress(ctx, categories, priceRange)
-		case "wildberries":
-			return s.searchWildberries(ctx, categories, priceRange)
-		case "ozon":
-			return s.searchOzon(ctx, categories, priceRange)
-		default:
-			return nil, fmt.Errorf("unsupported marketplace: %s", marketplace)
+// LoadWebSearchOptions читает WEB_SEARCH_SOURCE_TIMEOUT, WEB_SEARCH_BUDGET,
+// WEB_SEARCH_HEDGE_DELAY и WEB_SEARCH_MAX_HEDGES поверх значений по умолчанию
+func LoadWebSearchOptions() WebSearchOptions {
+	options := DefaultWebSearchOptions
+
+	durations := map[string]*time.Duration{
+		"WEB_SEARCH_SOURCE_TIMEOUT": &options.SourceTimeout,
+		"WEB_SEARCH_BUDGET":         &options.Budget,
+		"WEB_SEARCH_HEDGE_DELAY":    &options.HedgeDelay,
+	}
+	for key, target := range durations {
+		v := os.Getenv(key)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Printf("Invalid %s: %v", key, err)
+			continue
 		}
+		*target = d
 	}
 
-	// Если маркетплейс не указан, ищем везде параллельно
-	if s.kaspiToken != "" {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			products, err := s.searchKaspi(ctx, categories, priceRange)
-			if err != nil {
-				errors <- fmt.Errorf("kaspi search error: %v", err)
-				return
-			}
-			mu.Lock()
-			allProducts = append(allProducts, products...)
-			mu.Unlock()
-		}()
+	if v := os.Getenv("WEB_SEARCH_MAX_HEDGES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			options.MaxHedges = n
+		} else {
+			log.Printf("Invalid WEB_SEARCH_MAX_HEDGES: %q", v)
+		}
 	}
 
-	if s.aliToken != "" {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			products, err := s.searchAliExpress(ctx, categories, priceRange)
-			if err != nil {
-				errors <- fmt.Errorf("aliexpress search error: %v", err)
-				return
-			}
-			mu.Lock()
-			allProducts = append(allProducts, products...)
-			mu.Unlock()
-		}()
-	}
+	return options
+}
 
-	if s.wildToken != "" {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			products, err := s.searchWildberries(ctx, categories, priceRange)
-			if err != nil {
-				errors <- fmt.Errorf("wildberries search error: %v", err)
-				return
-			}
-			mu.Lock()
-			allProducts = append(allProducts, products...)
-			mu.Unlock()
-		}()
+// WebSearchResult - найденные товары и статус каждого маркетплейса
+type WebSearchResult struct {
+	Products []types.Product
+	Sources  []types.SourceStatus
+}
+
+func NewWebSearchService(adapters ...MarketplaceAdapter) *WebSearchService {
+	rates, err := money.LoadRates()
+	if err != nil {
+		log.Printf("Failed to load currency rates, using defaults: %v", err)
+		rates = money.DefaultRates
 	}
 
-	if s.ozonToken != "" {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			products, err := s.searchOzon(ctx, categories, priceRange)
-			if err != nil {
-				errors <- fmt.Errorf("ozon search error: %v", err)
-				return
-			}
-			mu.Lock()
-			allProducts = append(allProducts, products...)
-			mu.Unlock()
-		}()
+	return &WebSearchService{
+		adapters: adapters,
+		rates:    rates,
+		options:  DefaultWebSearchOptions,
 	}
+}
 
-	// Ждем завершения всех поисков
-	wg.Wait()
-	close(errors)
+// SetFailurePolicy задает поведение при отказе всех маркетплейсов
+func (s *WebSearchService) SetFailurePolicy(policy FailurePolicy) {
+	s.policy = policy
+}
 
-	// Проверяем ошибки
-	var errStrings []string
-	for err := range errors {
-		errStrings = append(errStrings, err.Error())
-	}
-	if len(errStrings) > 0 {
-		log.Printf("Some marketplace searches failed: %v", strings.Join(errStrings, "; "))
-	}
+// SetOptions задает таймауты и хеджирование
+func (s *WebSearchService) SetOptions(options WebSearchOptions) {
+	s.options = options
+}
 
-	return allProducts, nil
+// Supports сообщает, есть ли адаптер для маркетплейса
+func (s *WebSearchService) Supports(marketplace string) bool {
+	return s.adapter(marketplace) != nil
 }
 
-func (s *WebSearchService) searchKaspi(ctx context.Context, categories []string, priceRange types.Range) ([]types.Product, error) {
-	// Преобразуем категории в формат Kaspi
-	kaspiCategories := make([]string, 0)
-	for _, cat := range categories {
-		if kaspiCat, ok := types.CategoryMappings["kaspi"][cat]; ok {
-			kaspiCategories = append(kaspiCategories, kaspiCat)
-		}
-	}
+// sourceOutcome - результат одной попытки запроса к маркетплейсу
+type sourceOutcome struct {
+	index    int
+	products []types.Product
+	status   types.SourceStatus
+}
 
-	// Формируем URL для API Kaspi
-	baseURL := "https://kaspi.kz/shop/api/products/search"
-	params := url.Values{}
-	params.Add("categories", strings.Join(kaspiCategories, ","))
-	params.Add("price_from", fmt.Sprintf("%.0f", priceRange.Min))
-	params.Add("price_to", fmt.Sprintf("%.0f", priceRange.Max))
+func (s *WebSearchService) SearchProducts(ctx context.Context, categories []string, priceRange types.Range, marketplace string) (*WebSearchResult, error) {
+	adapters := s.adapters
 
-	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, err
+	// Если указан конкретный маркетплейс, ищем только в нем
+	if marketplace != "" {
+		adapter := s.adapter(marketplace)
+		if adapter == nil {
+			return nil, fmt.Errorf("unsupported marketplace: %s", marketplace)
+		}
+		adapters = []MarketplaceAdapter{adapter}
 	}
-	req.Header.Set("Authorization", "Bearer "+s.kaspiToken)
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	result := &WebSearchResult{}
+	statuses := make([]types.SourceStatus, len(adapters))
+	deadline := s.searchDeadline(ctx)
+
+	// Каждый маркетплейс получает свой контекст с таймаутом, который не
+	// выходит за общий бюджет поиска
+	sourceCtxs := make([]context.Context, len(adapters))
+	cancels := make([]context.CancelFunc, len(adapters))
+	defer func() {
+		for _, cancel := range cancels {
+			if cancel != nil {
+				cancel()
+			}
+		}
+	}()
 
-	var products []types.Product
-	if err := json.NewDecoder(resp.Body).Decode(&products); err != nil {
-		return nil, err
-	}
+	outcomes := make(chan sourceOutcome, 2*len(adapters))
+	attempts := make([]int, len(adapters))
+	hedged := make([]bool, len(adapters))
+	done := make([]bool, len(adapters))
 
-	// Добавляем маркетплейс к каждому продукту
-	for i := range products {
-		products[i].Store = "kaspi"
+	launch := func(i int) {
+		adapter := adapters[i]
+		attempts[i]++
+		go func() {
+			products, status := runSource(adapter.Name(), func() ([]types.Product, error) {
+				return adapter.Search(sourceCtxs[i], categories, s.rangeFor(adapter, priceRange))
+			})
+			outcomes <- sourceOutcome{index: i, products: products, status: status}
+		}()
 	}
 
-	return products, nil
-}
-
-func (s *WebSearchService) searchAliExpress(ctx context.Context, categories []string, priceRange types.Range) ([]types.Product, error) {
-	// Аналогичная реализация для AliExpress
-	// Используем AliExpress Open API
-	aliCategories := make([]string, 0)
-	for _, cat := range categories {
-		if aliCat, ok := types.CategoryMappings["aliexpress"][cat]; ok {
-			aliCategories = append(aliCategories, aliCat)
+	// Ищем во всех выбранных маркетплейсах параллельно
+	pending := 0
+	for i, adapter := range adapters {
+		if !adapter.Configured() {
+			statuses[i] = skippedSource(adapter.Name(), "credentials are not configured")
+			done[i] = true
+			continue
 		}
-	}
 
-	baseURL := "https://api.aliexpress.com/v2/products/search"
-	params := url.Values{}
-	params.Add("categories", strings.Join(aliCategories, ","))
-	params.Add("price_min", fmt.Sprintf("%.2f", priceRange.Min))
-	params.Add("price_max", fmt.Sprintf("%.2f", priceRange.Max))
+		sourceDeadline := deadline
+		if s.options.SourceTimeout > 0 {
+			if d := time.Now().Add(s.options.SourceTimeout); d.Before(sourceDeadline) {
+				sourceDeadline = d
+			}
+		}
+		sourceCtxs[i], cancels[i] = context.WithDeadline(ctx, sourceDeadline)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, err
+		launch(i)
+		pending++
 	}
-	req.Header.Set("Authorization", "Bearer "+s.aliToken)
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	budget := time.NewTimer(time.Until(deadline))
+	defer budget.Stop()
 
-	var products []types.Product
-	if err := json.NewDecoder(resp.Body).Decode(&products); err != nil {
-		return nil, err
+	var hedgeC <-chan time.Time
+	if s.options.HedgeDelay > 0 && s.options.MaxHedges > 0 && pending > 0 {
+		hedgeTimer := time.NewTimer(s.options.HedgeDelay)
+		defer hedgeTimer.Stop()
+		hedgeC = hedgeTimer.C
 	}
 
-	for i := range products {
-		products[i].Store = "aliexpress"
-	}
+	started := time.Now()
+	for pending > 0 {
+		select {
+		case outcome := <-outcomes:
+			i := outcome.index
+			attempts[i]--
+			if done[i] {
+				continue
+			}
+			// Если неудачная попытка еще дублируется, ждем вторую
+			if outcome.status.Status != types.SourceOK && attempts[i] > 0 {
+				continue
+			}
 
-	return products, nil
-}
+			done[i] = true
+			pending--
+			cancels[i]()
+
+			status := outcome.status
+			status.Hedged = hedged[i]
+			statuses[i] = status
+			if status.Status != types.SourceOK {
+				log.Printf("%s search failed: %s", status.Source, status.Error)
+				continue
+			}
+			result.Products = append(result.Products, outcome.products...)
+
+		case <-hedgeC:
+			// Дублируем запросы к маркетплейсам, которые до сих пор не ответили
+			hedges := 0
+			for i := range adapters {
+				if done[i] || hedges >= s.options.MaxHedges {
+					continue
+				}
+				hedged[i] = true
+				hedges++
+				launch(i)
+			}
 
-func (s *WebSearchService) searchWildberries(ctx context.Context, categories []string, priceRange types.Range) ([]types.Product, error) {
-	// Реализация для Wildberries
-	wbCategories := make([]string, 0)
-	for _, cat := range categories {
-		if wbCat, ok := types.CategoryMappings["wildberries"][cat]; ok {
-			wbCategories = append(wbCategories, wbCat)
+		case <-budget.C:
+			s.expirePending(adapters, statuses, done, hedged, cancels, time.Since(started))
+			pending = 0
 		}
 	}
 
-	baseURL := "https://suppliers-api.wildberries.ru/api/v3/products"
-	params := url.Values{}
-	params.Add("subjects", strings.Join(wbCategories, ","))
-	params.Add("priceFrom", fmt.Sprintf("%.0f", priceRange.Min))
-	params.Add("priceTo", fmt.Sprintf("%.0f", priceRange.Max))
+	result.Sources = statuses
 
-	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, err
+	if s.policy == FailWhenAllFail && allSourcesFailed(statuses) {
+		return result, ErrAllSourcesFailed
 	}
-	req.Header.Set("Authorization", s.wildToken)
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return result, nil
+}
 
-	var products []types.Product
-	if err := json.NewDecoder(resp.Body).Decode(&products); err != nil {
-		return nil, err
+// searchDeadline возвращает момент, к которому нужно вернуть ответ: бюджет
+// поиска, но не позже дедлайна запроса за вычетом запаса на формирование ответа
+func (s *WebSearchService) searchDeadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(s.options.Budget)
+	if s.options.Budget <= 0 {
+		deadline = time.Now().Add(DefaultWebSearchOptions.Budget)
 	}
 
-	for i := range products {
-		products[i].Store = "wildberries"
+	if ctxDeadline, ok := ctx.Deadline(); ok {
+		if d := ctxDeadline.Add(-s.options.SafetyMargin); d.Before(deadline) {
+			deadline = d
+		}
 	}
 
-	return products, nil
+	return deadline
 }
 
-func (s *WebSearchService) searchOzon(ctx context.Context, categories []string, priceRange types.Range) ([]types.Product, error) {
-	// Реализация для Ozon
-	ozonCategories := make([]string, 0)
-	for _, cat := range categories {
-		if ozonCat, ok := types.CategoryMappings["ozon"][cat]; ok {
-			ozonCategories = append(ozonCategories, ozonCat)
+// expirePending помечает не ответившие маркетплейсы как превысившие бюджет
+func (s *WebSearchService) expirePending(adapters []MarketplaceAdapter, statuses []types.SourceStatus, done, hedged []bool, cancels []context.CancelFunc, elapsed time.Duration) {
+	for i, adapter := range adapters {
+		if done[i] {
+			continue
 		}
+		done[i] = true
+		cancels[i]()
+		statuses[i] = types.SourceStatus{
+			Source:    adapter.Name(),
+			Status:    types.SourceTimeout,
+			Error:     "search budget exhausted",
+			LatencyMs: elapsed.Milliseconds(),
+			Hedged:    hedged[i],
+		}
+		log.Printf("%s search did not finish within budget", adapter.Name())
 	}
+}
 
-	baseURL := "https://api-seller.ozon.ru/v3/product/list"
-
-	// Формируем тело запроса
-	requestBody := map[string]interface{}{
-		"categories": ozonCategories,
-		"price": map[string]float64{
-			"from": priceRange.Min,
-			"to":   priceRange.Max,
-		},
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", baseURL, strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return nil, err
+func (s *WebSearchService) adapter(name string) MarketplaceAdapter {
+	for _, adapter := range s.adapters {
+		if adapter.Name() == name {
+			return adapter
+		}
 	}
-	req.Header.Set("Client-Id", s.ozonToken)
-	req.Header.Set("Api-Key", s.ozonToken)
-	req.Header.Set("Content-Type", "application/json")
+	return nil
+}
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+// rangeFor переводит ценовой диапазон в валюту маркетплейса
+func (s *WebSearchService) rangeFor(adapter MarketplaceAdapter, priceRange types.Range) types.Range {
+	from := priceRange.Currency
+	if from == "" {
+		from = money.KZT
 	}
-	defer resp.Body.Close()
+	to := money.StoreCurrency(adapter.Name())
 
-	var products []types.Product
-	if err := json.NewDecoder(resp.Body).Decode(&products); err != nil {
-		return nil, err
+	converted := types.Range{Currency: to}
+	var err error
+	if converted.Min, err = s.rates.Convert(priceRange.Min, from, to); err != nil {
+		log.Printf("Failed to convert price range for %s: %v", adapter.Name(), err)
+		return priceRange
 	}
-
-	for i := range products {
-		products[i].Store = "ozon"
+	if converted.Max, err = s.rates.Convert(priceRange.Max, from, to); err != nil {
+		log.Printf("Failed to convert price range for %s: %v", adapter.Name(), err)
+		return priceRange
 	}
 
-	return products, nil
+	return converted
 }
@@ -0,0 +1,127 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+const wildberriesBaseURL = "https://search.wb.ru"
+
+type WildberriesAdapter struct {
+	token   string
+	baseURL string
+	client  httpclient.Doer
+}
+
+type wildberriesSearchResponse struct {
+	Data struct {
+		Products []wildberriesProduct `json:"products"`
+	} `json:"data"`
+}
+
+type wildberriesProduct struct {
+	ID           int64   `json:"id"`
+	Name         string  `json:"name"`
+	Brand        string  `json:"brand"`
+	Entity       string  `json:"entity"`     // Тип товара, например "наушники"
+	PriceU       int64   `json:"priceU"`     // Цена без скидки в копейках
+	SalePriceU   int64   `json:"salePriceU"` // Цена со скидкой в копейках
+	ReviewRating float64 `json:"reviewRating"`
+	Feedbacks    int     `json:"feedbacks"`
+	Time1        int     `json:"time1"` // Время сборки заказа в часах
+	Time2        int     `json:"time2"` // Время доставки со склада в часах
+}
+
+func NewWildberriesAdapter(client httpclient.Doer, token string) *WildberriesAdapter {
+	return &WildberriesAdapter{
+		token:   token,
+		baseURL: envOrDefault("WILDBERRIES_API_URL", wildberriesBaseURL),
+		client:  client,
+	}
+}
+
+func (a *WildberriesAdapter) Name() string { return "wildberries" }
+
+func (a *WildberriesAdapter) Configured() bool { return a.token != "" }
+
+func (a *WildberriesAdapter) Search(ctx context.Context, categories []string, priceRange types.Range) ([]types.Product, error) {
+	params := url.Values{}
+	params.Add("query", strings.Join(localizedCategories("wildberries", categories), " "))
+	params.Add("resultset", "catalog")
+	params.Add("curr", "rub")
+	// Фильтр цены Wildberries задается в копейках: priceU=min;max
+	if priceRange.Min > 0 || priceRange.Max > 0 {
+		params.Add("priceU", fmt.Sprintf("%.0f;%.0f", priceRange.Min*100, priceRange.Max*100))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", a.baseURL+"/exactmatch/ru/common/v4/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", a.token)
+
+	var resp wildberriesSearchResponse
+	if err := doJSON(a.client, req, a.Name(), &resp); err != nil {
+		return nil, err
+	}
+
+	products := make([]types.Product, 0, len(resp.Data.Products))
+	for _, item := range resp.Data.Products {
+		products = append(products, a.toProduct(item, categories))
+	}
+
+	return products, nil
+}
+
+func (a *WildberriesAdapter) toProduct(item wildberriesProduct, categories []string) types.Product {
+	price := item.SalePriceU
+	if price == 0 {
+		price = item.PriceU
+	}
+
+	id := strconv.FormatInt(item.ID, 10)
+	title := item.Name
+	if item.Brand != "" {
+		title = item.Brand + " / " + item.Name
+	}
+
+	productURL := "https://www.wildberries.ru/catalog/" + id + "/detail.aspx"
+
+	return types.Product{
+		ID:          "wildberries-" + id,
+		Title:       title,
+		Description: item.Name,
+		Price:       float64(price) / 100,
+		Currency:    money.RUB,
+		Rating:      item.ReviewRating,
+		URL:         productURL,
+		Store:       a.Name(),
+		Category:    inferCategory(item.Entity+" "+item.Name, "", categories),
+		Offers: []types.Offer{{
+			ProductID:        "wildberries-" + id,
+			Store:            a.Name(),
+			Source:           a.Name(),
+			Price:            float64(price) / 100,
+			Currency:         money.RUB,
+			URL:              productURL,
+			DeliveryEstimate: wildberriesDelivery(item.Time1, item.Time2),
+		}},
+	}
+}
+
+// wildberriesDelivery переводит срок сборки и доставки из часов в дни
+func wildberriesDelivery(assemblyHours, deliveryHours int) string {
+	hours := assemblyHours + deliveryHours
+	if hours <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d дн.", (hours+23)/24)
+}
@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Значения по умолчанию: любые источники без cookie, заголовки авторизации
+// разрешены, Retry-After и имя файла выгрузки доступны скриптам
+const (
+	defaultCORSOrigins = "*"
+	defaultCORSMethods = "GET,POST,PUT,DELETE,OPTIONS"
+	defaultCORSHeaders = "Content-Type,Authorization,X-Api-Key"
+	defaultCORSExposed = "Retry-After,Content-Disposition"
+	defaultCORSMaxAge  = 10 * time.Minute
+)
+
+// CORSPolicy - какие сайты могут вызывать API из браузера
+type CORSPolicy struct {
+	AllowedOrigins   []string // Точные источники или шаблоны: https://*.example.com, http://localhost:*
+	AllowedMethods   []string
+	AllowedHeaders   []string // "*" - любые заголовки из запроса браузера
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // Сколько браузер кэширует ответ на preflight
+}
+
+// LoadCORSPolicy читает политику из CORS_ALLOWED_ORIGINS,
+// CORS_ALLOWED_METHODS, CORS_ALLOWED_HEADERS, CORS_EXPOSED_HEADERS (списки
+// через запятую), CORS_ALLOW_CREDENTIALS и CORS_MAX_AGE (например, 10m)
+func LoadCORSPolicy() (CORSPolicy, error) {
+	policy := CORSPolicy{
+		AllowedOrigins: splitList(envOr("CORS_ALLOWED_ORIGINS", defaultCORSOrigins)),
+		AllowedMethods: splitList(envOr("CORS_ALLOWED_METHODS", defaultCORSMethods)),
+		AllowedHeaders: splitList(envOr("CORS_ALLOWED_HEADERS", defaultCORSHeaders)),
+		ExposedHeaders: splitList(envOr("CORS_EXPOSED_HEADERS", defaultCORSExposed)),
+		MaxAge:         defaultCORSMaxAge,
+	}
+
+	for _, origin := range policy.AllowedOrigins {
+		if _, err := path.Match(origin, ""); err != nil {
+			return CORSPolicy{}, fmt.Errorf("invalid CORS_ALLOWED_ORIGINS pattern %q: %w", origin, err)
+		}
+	}
+	for i, method := range policy.AllowedMethods {
+		policy.AllowedMethods[i] = strings.ToUpper(method)
+	}
+
+	if v := os.Getenv("CORS_ALLOW_CREDENTIALS"); v != "" {
+		credentials, err := strconv.ParseBool(v)
+		if err != nil {
+			return CORSPolicy{}, fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS: %w", err)
+		}
+		policy.AllowCredentials = credentials
+	}
+	// С cookie любой сайт мог бы действовать от имени пользователя
+	if policy.AllowCredentials && policy.allowsAnyOrigin() {
+		return CORSPolicy{}, errors.New("CORS_ALLOW_CREDENTIALS requires explicit CORS_ALLOWED_ORIGINS")
+	}
+
+	if v := os.Getenv("CORS_MAX_AGE"); v != "" {
+		maxAge, err := time.ParseDuration(v)
+		if err != nil || maxAge < 0 {
+			return CORSPolicy{}, fmt.Errorf("invalid CORS_MAX_AGE: %q", v)
+		}
+		policy.MaxAge = maxAge
+	}
+	return policy, nil
+}
+
+// AllowOrigin проверяет источник по списку; регистр не учитывается
+func (p CORSPolicy) AllowOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	origin = strings.ToLower(origin)
+	for _, pattern := range p.AllowedOrigins {
+		// В path.Match "*" не совпадает с "/" из схемы источника
+		if pattern == "*" {
+			return true
+		}
+		if ok, _ := path.Match(strings.ToLower(pattern), origin); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CORSPolicy) allowsAnyOrigin() bool {
+	for _, origin := range p.AllowedOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSFromEnv применяет политику из LoadCORSPolicy
+func CORSFromEnv() (Middleware, error) {
+	policy, err := LoadCORSPolicy()
+	if err != nil {
+		return nil, err
+	}
+	return CORS(policy), nil
+}
+
+// CORS отвечает на OPTIONS без вызова обработчика и добавляет заголовки CORS
+// к его ответам. Запросы с чужих источников не отклоняются: браузер сам не
+// отдаст ответ без Access-Control-Allow-Origin.
+func CORS(policy CORSPolicy) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			origin := header(request.Headers, "Origin")
+
+			if request.HTTPMethod == "OPTIONS" {
+				if origin != "" && !policy.AllowOrigin(origin) {
+					headers := map[string]string{"Content-Type": "application/json"}
+					policy.varyOrigin(headers)
+					return events.APIGatewayProxyResponse{
+						StatusCode: 403,
+						Body:       `{"error":"Origin not allowed"}`,
+						Headers:    headers,
+					}, nil
+				}
+				headers := map[string]string{}
+				policy.varyOrigin(headers)
+				policy.allowOriginHeaders(headers, origin)
+				headers["Access-Control-Allow-Methods"] = strings.Join(policy.AllowedMethods, ", ")
+				if allowed := policy.allowedHeaders(request); allowed != "" {
+					headers["Access-Control-Allow-Headers"] = allowed
+				}
+				if policy.MaxAge > 0 {
+					headers["Access-Control-Max-Age"] = strconv.Itoa(int(policy.MaxAge.Seconds()))
+				}
+				return events.APIGatewayProxyResponse{
+					StatusCode: 204,
+					Headers:    headers,
+				}, nil
+			}
+
+			response, err := next(ctx, request)
+			if err != nil {
+				return response, err
+			}
+			if response.Headers == nil {
+				response.Headers = map[string]string{}
+			}
+			policy.varyOrigin(response.Headers)
+			if origin != "" && !policy.AllowOrigin(origin) {
+				return response, nil
+			}
+			policy.allowOriginHeaders(response.Headers, origin)
+			if len(policy.ExposedHeaders) > 0 {
+				response.Headers["Access-Control-Expose-Headers"] = strings.Join(policy.ExposedHeaders, ", ")
+			}
+			return response, nil
+		}
+	}
+}
+
+// allowOriginHeaders разрешает источник запроса. "*" отдается только без
+// cookie, иначе источник повторяется.
+func (p CORSPolicy) allowOriginHeaders(headers map[string]string, origin string) {
+	switch {
+	case p.allowsAnyOrigin() && !p.AllowCredentials:
+		headers["Access-Control-Allow-Origin"] = "*"
+	case origin != "":
+		headers["Access-Control-Allow-Origin"] = origin
+		if p.AllowCredentials {
+			headers["Access-Control-Allow-Credentials"] = "true"
+		}
+	}
+}
+
+// varyOrigin добавляет Origin к Vary, если ответ зависит от источника:
+// иначе кэш отдал бы ответ с заголовками одного сайта другому. Vary
+// обработчика сохраняется.
+func (p CORSPolicy) varyOrigin(headers map[string]string) {
+	if p.allowsAnyOrigin() && !p.AllowCredentials {
+		return
+	}
+	key, vary := "Vary", ""
+	for name, value := range headers {
+		if strings.EqualFold(name, "Vary") {
+			key, vary = name, value
+			break
+		}
+	}
+	for _, value := range splitList(vary) {
+		if value == "*" || strings.EqualFold(value, "Origin") {
+			return
+		}
+	}
+	if vary != "" {
+		vary += ", "
+	}
+	headers[key] = vary + "Origin"
+}
+
+// allowedHeaders - заголовки для preflight; при "*" разрешаются те, что
+// запросил браузер
+func (p CORSPolicy) allowedHeaders(request events.APIGatewayProxyRequest) string {
+	for _, name := range p.AllowedHeaders {
+		if name == "*" {
+			return header(request.Headers, "Access-Control-Request-Headers")
+		}
+	}
+	return strings.Join(p.AllowedHeaders, ", ")
+}
+
+// header ищет заголовок без учета регистра имени
+func header(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestCORSVary(t *testing.T) {
+	handler := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{
+			StatusCode: 200,
+			Headers:    map[string]string{"vary": "Accept-Language"},
+		}, nil
+	}
+	explicit := CORSPolicy{AllowedOrigins: []string{"https://*.example.com"}}
+	any := CORSPolicy{AllowedOrigins: []string{"*"}}
+
+	tests := []struct {
+		name       string
+		policy     CORSPolicy
+		origin     string
+		wantVary   string
+		wantOrigin string
+	}{
+		{name: "allowed origin", policy: explicit, origin: "https://shop.example.com", wantVary: "Accept-Language, Origin", wantOrigin: "https://shop.example.com"},
+		{name: "disallowed origin", policy: explicit, origin: "https://evil.test", wantVary: "Accept-Language, Origin"},
+		{name: "no origin", policy: explicit, wantVary: "Accept-Language, Origin"},
+		{name: "any origin", policy: any, origin: "https://evil.test", wantVary: "Accept-Language", wantOrigin: "*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{HTTPMethod: "GET", Headers: map[string]string{}}
+			if tt.origin != "" {
+				request.Headers["Origin"] = tt.origin
+			}
+			response, err := CORS(tt.policy)(handler)(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := header(response.Headers, "Vary"); got != tt.wantVary {
+				t.Errorf("Vary = %q, want %q", got, tt.wantVary)
+			}
+			if got := response.Headers["Access-Control-Allow-Origin"]; got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	policy := CORSPolicy{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type"},
+	}
+	next := func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		t.Fatal("handler must not be called for preflight")
+		return events.APIGatewayProxyResponse{}, nil
+	}
+
+	for origin, status := range map[string]int{"https://app.example.com": 204, "https://evil.test": 403} {
+		request := events.APIGatewayProxyRequest{HTTPMethod: "OPTIONS", Headers: map[string]string{"origin": origin}}
+		response, _ := CORS(policy)(next)(context.Background(), request)
+		if response.StatusCode != status {
+			t.Errorf("%s: status = %d, want %d", origin, response.StatusCode, status)
+		}
+		if response.Headers["Vary"] != "Origin" {
+			t.Errorf("%s: Vary = %q, want Origin", origin, response.Headers["Vary"])
+		}
+	}
+}
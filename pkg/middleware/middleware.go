@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Handler - обработчик запроса API Gateway, как в cmd/*
+type Handler func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+// Middleware оборачивает обработчик общей логикой
+type Middleware func(next Handler) Handler
+
+// Wrap оборачивает обработчик эндпоинта общими для всех API Lambda
+// middleware: ограничением частоты (RateLimitFromEnv) и CORS (CORSFromEnv).
+// CORS снаружи, чтобы ответ 429 тоже получал заголовки CORS.
+func Wrap(dynamoClient *dynamodb.Client, endpoint string, handler Handler) (Handler, error) {
+	rateLimit, err := RateLimitFromEnv(dynamoClient, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init rate limiter: %w", err)
+	}
+	cors, err := CORSFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CORS policy: %w", err)
+	}
+	return cors(rateLimit(handler)), nil
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/ratelimit"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// RateLimitFromEnv ограничивает частоту запросов одного клиента к
+// эндпоинту: лимит из ratelimit.LoadLimit, бакеты из
+// ratelimit.NewLimiterFromEnv. Если лимит выключен, обработчик не меняется.
+func RateLimitFromEnv(dynamoClient *dynamodb.Client, endpoint string) (Middleware, error) {
+	limit, enabled, err := ratelimit.LoadLimit(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return func(next Handler) Handler { return next }, nil
+	}
+
+	limiter, err := ratelimit.NewLimiterFromEnv(dynamoClient)
+	if err != nil {
+		return nil, err
+	}
+	return RateLimit(limiter, endpoint, limit), nil
+}
+
+// RateLimit отвечает 429 с Retry-After, когда клиент исчерпал лимит. Если
+// хранилище бакетов недоступно, запрос пропускается.
+func RateLimit(limiter ratelimit.Limiter, endpoint string, limit ratelimit.Limit) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			if request.HTTPMethod == "OPTIONS" {
+				return next(ctx, request)
+			}
+
+			decision, err := limiter.Allow(ctx, endpoint+"#"+ClientKey(request), limit)
+			if err != nil {
+				log.Printf("Rate limiter failed, allowing request: %v", err)
+				return next(ctx, request)
+			}
+			if decision.Allowed {
+				return next(ctx, request)
+			}
+
+			// Retry-After - целые секунды, не меньше одной
+			retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			return events.APIGatewayProxyResponse{
+				StatusCode: 429,
+				Body:       `{"error":"Too many requests"}`,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+					"Retry-After":  strconv.Itoa(retryAfter),
+				},
+			}, nil
+		}
+	}
+}
+
+// ClientKey определяет клиента: ключ API, затем пользователь из
+// авторизатора, затем IP-адрес
+func ClientKey(request events.APIGatewayProxyRequest) string {
+	if keyID, ok := request.RequestContext.Authorizer["key_id"].(string); ok && keyID != "" {
+		return "key:" + keyID
+	}
+	if principal, ok := request.RequestContext.Authorizer["principalId"].(string); ok && principal != "" {
+		return "user:" + principal
+	}
+	return "ip:" + request.RequestContext.Identity.SourceIP
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/ratelimit"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// stubLimiter возвращает заданное решение и запоминает ключи
+type stubLimiter struct {
+	decision ratelimit.Decision
+	err      error
+	keys     []string
+}
+
+func (l *stubLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Decision, error) {
+	l.keys = append(l.keys, key)
+	return l.decision, l.err
+}
+
+func okHandler(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+}
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		limiter        *stubLimiter
+		wantStatus     int
+		wantRetryAfter string
+		wantChecked    bool
+	}{
+		{name: "allowed", method: "GET", limiter: &stubLimiter{decision: ratelimit.Decision{Allowed: true}}, wantStatus: 200, wantChecked: true},
+		{name: "rejected", method: "POST", limiter: &stubLimiter{decision: ratelimit.Decision{RetryAfter: 6 * time.Second}}, wantStatus: 429, wantRetryAfter: "6", wantChecked: true},
+		{name: "retry after rounded up", method: "POST", limiter: &stubLimiter{decision: ratelimit.Decision{RetryAfter: 1200 * time.Millisecond}}, wantStatus: 429, wantRetryAfter: "2", wantChecked: true},
+		{name: "retry after at least one second", method: "POST", limiter: &stubLimiter{decision: ratelimit.Decision{}}, wantStatus: 429, wantRetryAfter: "1", wantChecked: true},
+		{name: "limiter failure lets request through", method: "GET", limiter: &stubLimiter{err: errors.New("throttled")}, wantStatus: 200, wantChecked: true},
+		{name: "preflight not limited", method: "OPTIONS", limiter: &stubLimiter{}, wantStatus: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := events.APIGatewayProxyRequest{HTTPMethod: tt.method}
+			request.RequestContext.Identity.SourceIP = "1.2.3.4"
+
+			handler := RateLimit(tt.limiter, "search-products", ratelimit.Limit{Rate: 1, Burst: 1})(okHandler)
+			response, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if got := response.Headers["Retry-After"]; got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			if checked := len(tt.limiter.keys) > 0; checked != tt.wantChecked {
+				t.Fatalf("limiter checked = %v, want %v", checked, tt.wantChecked)
+			}
+			if tt.wantChecked && tt.limiter.keys[0] != "search-products#ip:1.2.3.4" {
+				t.Errorf("key = %q", tt.limiter.keys[0])
+			}
+		})
+	}
+}
+
+func TestRateLimitMemoryLimiter(t *testing.T) {
+	limit, err := ratelimit.ParseLimit("2/1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := RateLimit(ratelimit.NewMemoryLimiter(), "translate", limit)(okHandler)
+	request := events.APIGatewayProxyRequest{HTTPMethod: "POST"}
+	request.RequestContext.Authorizer = map[string]interface{}{"principalId": "u1"}
+
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		response, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statuses = append(statuses, response.StatusCode)
+		if response.StatusCode == 429 && response.Headers["Retry-After"] != "30" {
+			t.Errorf("Retry-After = %q, want 30", response.Headers["Retry-After"])
+		}
+	}
+	if statuses[0] != 200 || statuses[1] != 200 || statuses[2] != 429 {
+		t.Errorf("statuses = %v, want two allowed and one rejected", statuses)
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	request := events.APIGatewayProxyRequest{}
+	request.RequestContext.Identity.SourceIP = "1.2.3.4"
+	if got := ClientKey(request); got != "ip:1.2.3.4" {
+		t.Errorf("ClientKey() = %q", got)
+	}
+	request.RequestContext.Authorizer = map[string]interface{}{"principalId": "u1"}
+	if got := ClientKey(request); got != "user:u1" {
+		t.Errorf("ClientKey() = %q", got)
+	}
+	request.RequestContext.Authorizer["key_id"] = "k1"
+	if got := ClientKey(request); got != "key:k1" {
+		t.Errorf("ClientKey() = %q", got)
+	}
+}
@@ -0,0 +1,150 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Коды валют ISO 4217, с которыми работают маркетплейсы
+const (
+	KZT = "KZT"
+	RUB = "RUB"
+	USD = "USD"
+	EUR = "EUR"
+)
+
+// ErrUnknownCurrency - валюты нет в таблице курсов
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Amount - сумма в конкретной валюте
+type Amount struct {
+	Value    float64
+	Currency string
+}
+
+// Locale - особенности цен источника: дополнительный разделитель разрядов
+// и валюта по умолчанию. Десятичный разделитель определяется по самой
+// строке (см. Parse), а не по локали.
+type Locale struct {
+	Group           rune   // Разделитель разрядов
+	DefaultCurrency string // Валюта, если в строке она не указана
+}
+
+var (
+	LocaleKZ = Locale{Group: ' ', DefaultCurrency: KZT}
+	LocaleRU = Locale{Group: ' ', DefaultCurrency: RUB}
+	LocaleEN = Locale{Group: ',', DefaultCurrency: USD}
+)
+
+// Обозначения валют в ценах. Порядок важен: более длинные обозначения
+// проверяются раньше коротких ("us$" раньше "$").
+var currencyMarkers = []struct {
+	marker   string
+	currency string
+}{
+	{"₸", KZT}, {"kzt", KZT}, {"тенге", KZT}, {"тг", KZT},
+	{"₽", RUB}, {"rub", RUB}, {"руб", RUB},
+	{"us$", USD}, {"usd", USD}, {"$", USD},
+	{"€", EUR}, {"eur", EUR},
+}
+
+// DetectCurrency ищет обозначение валюты в строке
+func DetectCurrency(s string) (string, bool) {
+	lower := strings.ToLower(s)
+	for _, m := range currencyMarkers {
+		if strings.Contains(lower, m.marker) {
+			return m.currency, true
+		}
+	}
+	return "", false
+}
+
+// Parse разбирает цену вида "15 990 ₸", "1.299,00 ₽", "$12.99", "от 2 490 тг."
+// или диапазон "15 990 – 20 000 ₸" (берется нижняя граница).
+//
+// Правила для разделителей:
+//   - если есть и точка, и запятая, десятичный - тот, что стоит последним;
+//   - повторяющийся разделитель ("1.299.000") - разделитель разрядов;
+//   - одиночный разделитель с тремя цифрами после него ("15,990") - разряды,
+//     с другим числом цифр ("12,99") - десятичный;
+//   - перед разделителем разрядов не бывает больше трех цифр, поэтому
+//     "3990.000" - это 3990.
+//
+// Локаль задает только валюту, если в строке она не указана, и
+// дополнительный разделитель разрядов.
+func Parse(s string, locale Locale) (Amount, error) {
+	currency, ok := DetectCurrency(s)
+	if !ok {
+		currency = locale.DefaultCurrency
+	}
+
+	raw := firstNumber(s, locale)
+	if raw == "" {
+		return Amount{}, fmt.Errorf("no amount in %q", s)
+	}
+
+	value, err := strconv.ParseFloat(normalizeNumber(raw), 64)
+	if err != nil {
+		return Amount{}, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+
+	return Amount{Value: value, Currency: currency}, nil
+}
+
+// firstNumber вырезает первое число вместе с разделителями. Пробелы
+// (включая неразрывные) и разделитель разрядов локали внутри числа пропускаются.
+func firstNumber(s string, locale Locale) string {
+	var number strings.Builder
+	started := false
+
+	for _, r := range s {
+		switch {
+		case unicode.IsDigit(r):
+			number.WriteRune(r)
+			started = true
+		case r == '.' || r == ',':
+			if started {
+				number.WriteRune(r)
+			}
+		case unicode.IsSpace(r) || r == '\'' || r == locale.Group:
+		default:
+			if started {
+				return strings.TrimRight(number.String(), ".,")
+			}
+		}
+	}
+
+	return strings.TrimRight(number.String(), ".,")
+}
+
+func normalizeNumber(raw string) string {
+	lastDot := strings.LastIndex(raw, ".")
+	lastComma := strings.LastIndex(raw, ",")
+
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			raw = strings.ReplaceAll(raw, ".", "")
+			return strings.Replace(raw, ",", ".", 1)
+		}
+		return strings.ReplaceAll(raw, ",", "")
+	case lastComma >= 0:
+		return normalizeSingleSeparator(raw, ",")
+	case lastDot >= 0:
+		return normalizeSingleSeparator(raw, ".")
+	}
+
+	return raw
+}
+
+func normalizeSingleSeparator(raw, sep string) string {
+	parts := strings.Split(raw, sep)
+	last := parts[len(parts)-1]
+	if len(parts) == 2 && (len(last) != 3 || len(parts[0]) > 3) {
+		return parts[0] + "." + last
+	}
+	return strings.ReplaceAll(raw, sep, "")
+}
@@ -0,0 +1,111 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input  string
+		locale Locale
+		want   Amount
+	}{
+		{"1.299,00 ₽", LocaleKZ, Amount{1299, RUB}},
+		{"15 990 ₸", LocaleRU, Amount{15990, KZT}},
+		{"15 990 ₸", LocaleKZ, Amount{15990, KZT}},
+		{"3990.000", LocaleRU, Amount{3990, RUB}},
+		{"15 990.000", LocaleKZ, Amount{15990, KZT}},
+		{"$12.99", LocaleKZ, Amount{12.99, USD}},
+		{"от 2 490 тг.", LocaleRU, Amount{2490, KZT}},
+		{"15 990 – 20 000 ₸", LocaleKZ, Amount{15990, KZT}},
+		{"15,990", LocaleKZ, Amount{15990, KZT}},
+		{"12,99", LocaleKZ, Amount{12.99, KZT}},
+		{"1.299.000", LocaleRU, Amount{1299000, RUB}},
+		{"1,299.50 USD", LocaleKZ, Amount{1299.5, USD}},
+		{"1,299", LocaleEN, Amount{1299, USD}},
+		{"€ 49", LocaleKZ, Amount{49, EUR}},
+		{"1 299 руб", LocaleKZ, Amount{1299, RUB}},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.input, tt.locale)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "цена по запросу", "₸"} {
+		if _, err := Parse(input, LocaleKZ); err == nil {
+			t.Errorf("Parse(%q) must fail", input)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{1000, KZT, KZT, 1000},
+		{1000, RUB, KZT, 5600},
+		{5600, KZT, RUB, 1000},
+		{10, USD, RUB, 10 * 480 / 5.6},
+		{10, "usd", "kzt", 4800},
+		{7, "GBP", "GBP", 7},
+	}
+	for _, tt := range tests {
+		got, err := DefaultRates.Convert(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("Convert(%v, %s, %s) error = %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("Convert(%v, %s, %s) = %v, want %v", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	for _, pair := range [][2]string{{"GBP", KZT}, {KZT, "GBP"}, {"", KZT}} {
+		if _, err := DefaultRates.Convert(1, pair[0], pair[1]); !errors.Is(err, ErrUnknownCurrency) {
+			t.Errorf("Convert(%s, %s) error = %v, want ErrUnknownCurrency", pair[0], pair[1], err)
+		}
+	}
+}
+
+func TestHas(t *testing.T) {
+	for currency, want := range map[string]bool{"KZT": true, "rub": true, "Usd": true, "GBP": false, "": false} {
+		if got := DefaultRates.Has(currency); got != want {
+			t.Errorf("Has(%q) = %v, want %v", currency, got, want)
+		}
+	}
+}
+
+func TestLoadRates(t *testing.T) {
+	t.Setenv("CURRENCY_RATES_FILE", "")
+
+	t.Setenv("CURRENCY_RATES", `{"base": "rub", "rates": {"kzt": 0.18, "usd": 90}}`)
+	table, err := LoadRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Base != RUB || table.Rates[RUB] != 1 || !table.Has(KZT) || table.Has(EUR) {
+		t.Errorf("LoadRates() = %+v", table)
+	}
+
+	for _, invalid := range []string{`{"rates": {"KZT": 1}}`, `{"base": "KZT", "rates": {"RUB": 0}}`, `not json`} {
+		t.Setenv("CURRENCY_RATES", invalid)
+		if _, err := LoadRates(); err == nil {
+			t.Errorf("LoadRates(%s) must fail", invalid)
+		}
+	}
+
+	t.Setenv("CURRENCY_RATES", "")
+	if table, err := LoadRates(); err != nil || table.Base != DefaultRates.Base {
+		t.Errorf("LoadRates() without settings = %+v, %v; want defaults", table, err)
+	}
+}
@@ -0,0 +1,103 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RateTable хранит стоимость единицы каждой валюты в базовой валюте
+type RateTable struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// DefaultRates - ориентировочные курсы на случай, если таблица не настроена
+var DefaultRates = RateTable{
+	Base: KZT,
+	Rates: map[string]float64{
+		KZT: 1,
+		RUB: 5.6,
+		USD: 480,
+		EUR: 520,
+	},
+}
+
+// LoadRates читает таблицу курсов из CURRENCY_RATES (JSON) или из файла
+// CURRENCY_RATES_FILE. Без настроек возвращаются DefaultRates.
+func LoadRates() (RateTable, error) {
+	data := []byte(os.Getenv("CURRENCY_RATES"))
+	if len(data) == 0 {
+		path := os.Getenv("CURRENCY_RATES_FILE")
+		if path == "" {
+			return DefaultRates, nil
+		}
+
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return RateTable{}, fmt.Errorf("failed to read rates file: %w", err)
+		}
+	}
+
+	var table RateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return RateTable{}, fmt.Errorf("failed to parse currency rates: %w", err)
+	}
+
+	if table.Base == "" {
+		return RateTable{}, fmt.Errorf("currency rates: base currency is required")
+	}
+	table.Base = strings.ToUpper(table.Base)
+
+	rates := make(map[string]float64, len(table.Rates)+1)
+	for currency, rate := range table.Rates {
+		if rate <= 0 {
+			return RateTable{}, fmt.Errorf("currency rates: invalid rate for %s", currency)
+		}
+		rates[strings.ToUpper(currency)] = rate
+	}
+	rates[table.Base] = 1
+	table.Rates = rates
+
+	return table, nil
+}
+
+// Has сообщает, известен ли курс валюты
+func (t RateTable) Has(currency string) bool {
+	_, ok := t.Rates[strings.ToUpper(currency)]
+	return ok
+}
+
+// Convert переводит сумму из одной валюты в другую через базовую
+func (t RateTable) Convert(value float64, from, to string) (float64, error) {
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+	if from == to {
+		return value, nil
+	}
+
+	fromRate, ok := t.Rates[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, from)
+	}
+	toRate, ok := t.Rates[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, to)
+	}
+
+	return value * fromRate / toRate, nil
+}
+
+// StoreCurrency возвращает валюту, в которой маркетплейс указывает цены
+func StoreCurrency(store string) string {
+	switch store {
+	case "ozon", "wildberries":
+		return RUB
+	case "aliexpress":
+		return USD
+	default:
+		return KZT
+	}
+}
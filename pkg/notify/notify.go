@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	sestypes "github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// Message - оповещение пользователя. Письмо собирается из Subject и Text,
+// вебхук и файл получают Payload как JSON.
+type Message struct {
+	Email   string      // Адрес для письма; пусто - письмо не отправляется
+	Subject string      // Тема письма
+	Text    string      // Текст письма
+	Payload interface{} // Данные оповещения для вебхука и файла
+}
+
+// Notifier доставляет оповещения
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// NewFromEnv собирает доставку по <prefix>_NOTIFIERS - списку через
+// запятую из webhook (<prefix>_WEBHOOK_URL), ses (<prefix>_FROM) и file
+// (<prefix>_FILE, по умолчанию fileName во временной папке). Без списка -
+// только file.
+func NewFromEnv(prefix, fileName string, sesClient *sesv2.Client) (Notifier, error) {
+	names := os.Getenv(prefix + "_NOTIFIERS")
+	if names == "" {
+		names = "file"
+	}
+
+	var notifiers Multi
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "webhook":
+			url := os.Getenv(prefix + "_WEBHOOK_URL")
+			if url == "" {
+				return nil, fmt.Errorf("%s_WEBHOOK_URL is required for webhook notifier", prefix)
+			}
+			notifiers = append(notifiers, NewWebhook(httpclient.Default(), url))
+		case "ses":
+			from := os.Getenv(prefix + "_FROM")
+			if from == "" {
+				return nil, fmt.Errorf("%s_FROM is required for ses notifier", prefix)
+			}
+			notifiers = append(notifiers, NewSES(sesClient, from))
+		case "file":
+			path := os.Getenv(prefix + "_FILE")
+			if path == "" {
+				path = filepath.Join(os.TempDir(), fileName)
+			}
+			notifiers = append(notifiers, NewFile(path))
+		default:
+			return nil, fmt.Errorf("unknown %s notifier: %s", strings.ToLower(prefix), name)
+		}
+	}
+
+	if len(notifiers) == 1 {
+		return notifiers[0], nil
+	}
+	return notifiers, nil
+}
+
+// Multi отправляет оповещение во все каналы; ошибка одного канала не мешает
+// остальным. Оповещение считается доставленным, если его принял хотя бы
+// один канал: иначе повторная попытка снова отправила бы его в каналы, где
+// оно уже дошло. Ошибки остальных каналов только логируются.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, message Message) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, message); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", notifier, err))
+		}
+	}
+	if len(errs) == len(m) {
+		return errors.Join(errs...)
+	}
+	for _, err := range errs {
+		log.Printf("Notification partially delivered, channel failed: %v", err)
+	}
+	return nil
+}
+
+// Webhook отправляет Payload POST запросом с JSON телом
+type Webhook struct {
+	client httpclient.Doer
+	url    string
+}
+
+func NewWebhook(client httpclient.Doer, url string) *Webhook {
+	return &Webhook{client: client, url: url}
+}
+
+func (n *Webhook) Notify(ctx context.Context, message Message) error {
+	body, err := json.Marshal(message.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SES отправляет письмо через Amazon SES. Оповещения без адреса
+// пропускаются.
+type SES struct {
+	client *sesv2.Client
+	from   string
+}
+
+func NewSES(client *sesv2.Client, from string) *SES {
+	return &SES{client: client, from: from}
+}
+
+func (n *SES) Notify(ctx context.Context, message Message) error {
+	if message.Email == "" {
+		return nil
+	}
+
+	_, err := n.client.SendEmail(ctx, &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(n.from),
+		Destination:      &sestypes.Destination{ToAddresses: []string{message.Email}},
+		Content: &sestypes.EmailContent{
+			Simple: &sestypes.Message{
+				Subject: &sestypes.Content{Data: aws.String(message.Subject), Charset: aws.String("UTF-8")},
+				Body: &sestypes.Body{
+					Text: &sestypes.Content{Data: aws.String(message.Text), Charset: aws.String("UTF-8")},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// File дописывает Payload в файл по одному JSON на строку - для локального
+// запуска и проверки без внешних сервисов
+type File struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+func (n *File) Notify(ctx context.Context, message Message) error {
+	line, err := json.Marshal(message.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open notifications file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+	return nil
+}
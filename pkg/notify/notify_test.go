@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type notifierFunc func(ctx context.Context, message Message) error
+
+func (f notifierFunc) Notify(ctx context.Context, message Message) error {
+	return f(ctx, message)
+}
+
+func TestMultiNotify(t *testing.T) {
+	ok := notifierFunc(func(context.Context, Message) error { return nil })
+	failed := notifierFunc(func(context.Context, Message) error { return errors.New("down") })
+
+	tests := []struct {
+		name    string
+		multi   Multi
+		wantErr bool
+	}{
+		{name: "all delivered", multi: Multi{ok, ok}},
+		{name: "partially delivered", multi: Multi{failed, ok}},
+		{name: "all failed", multi: Multi{failed, failed}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.multi.Notify(context.Background(), Message{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
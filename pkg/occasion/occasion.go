@@ -0,0 +1,209 @@
+package occasion
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Форматы дат: конкретный день или ежегодная дата без года
+const (
+	dateLayout       = "2006-01-02"
+	annualDateLayout = "01-02"
+)
+
+// Значения по умолчанию для напоминаний
+const (
+	defaultTimezone   = "Asia/Almaty"
+	defaultRemindDays = 7
+	maxRemindDays     = 60
+)
+
+var (
+	ErrNotFound = errors.New("occasion not found")
+	// ErrInvalid - некорректный повод, дата, часовой пояс или адрес
+	ErrInvalid = errors.New("invalid occasion")
+	// ErrClaimed - напоминание об этой дате уже отправлено или отправляется
+	ErrClaimed = errors.New("reminder already claimed")
+)
+
+// Entry - повод в календаре пользователя: кому, что и когда
+type Entry struct {
+	UserID      string `dynamodbav:"user_id" json:"-"`
+	ID          string `dynamodbav:"occasion_id" json:"id"`
+	RecipientID string `dynamodbav:"recipient_id,omitempty" json:"recipient_id,omitempty"` // Получатель из адресной книги
+	Occasion    string `dynamodbav:"occasion" json:"occasion"`                             // Ключ повода из таксономии
+	Date        string `dynamodbav:"date" json:"date"`                                     // YYYY-MM-DD или MM-DD
+	Yearly      bool   `dynamodbav:"yearly" json:"yearly"`                                 // Повторять каждый год; для MM-DD всегда
+	RemindDays  int    `dynamodbav:"remind_days" json:"remind_days"`                       // За сколько дней напомнить
+	Timezone    string `dynamodbav:"timezone" json:"timezone"`                             // IANA, например Asia/Almaty или Europe/Moscow
+	// Письма-напоминания уходят только на подтвержденный адрес пользователя
+	// (auth.Email); адрес из запроса не принимается
+	EmailReminders bool   `dynamodbav:"email_reminders" json:"email_reminders"`
+	Email          string `dynamodbav:"email,omitempty" json:"email,omitempty"`
+	Language       string `dynamodbav:"language,omitempty" json:"language,omitempty"`
+	Note           string `dynamodbav:"note,omitempty" json:"note,omitempty"`
+
+	// Состояние напоминания о ближайшей дате; заполняет планировщик
+	RemindedFor    string                    `dynamodbav:"reminded_for,omitempty" json:"reminded_for,omitempty"` // Дата, о которой уже напомнили
+	RemindedAt     time.Time                 `dynamodbav:"reminded_at" json:"reminded_at,omitzero"`
+	Recommendation *types.GiftRecommendation `dynamodbav:"recommendation,omitempty" json:"recommendation,omitempty"`
+
+	CreatedAt time.Time `dynamodbav:"created_at" json:"created_at"`
+	UpdatedAt time.Time `dynamodbav:"updated_at" json:"updated_at"`
+}
+
+// Normalize проверяет повод и заполняет значения по умолчанию
+func (e *Entry) Normalize() error {
+	var problems []string
+
+	if _, ok := taxonomy.Current().Occasions[e.Occasion]; !ok {
+		problems = append(problems, fmt.Sprintf("unknown occasion %q", e.Occasion))
+	}
+
+	if _, err := time.Parse(annualDateLayout, e.Date); err == nil {
+		e.Yearly = true
+	} else if _, err := time.Parse(dateLayout, e.Date); err != nil {
+		problems = append(problems, "date must be YYYY-MM-DD or MM-DD")
+	}
+
+	if e.RemindDays == 0 {
+		e.RemindDays = DefaultRemindDays()
+	}
+	if e.RemindDays < 0 || e.RemindDays > maxRemindDays {
+		problems = append(problems, fmt.Sprintf("remind_days must be between 1 and %d", maxRemindDays))
+	}
+
+	if e.Timezone == "" {
+		e.Timezone = DefaultTimezone()
+	}
+	if _, err := time.LoadLocation(e.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("unknown timezone %q", e.Timezone))
+	}
+
+	e.Email = strings.TrimSpace(e.Email)
+	if e.Email != "" {
+		if _, err := mail.ParseAddress(e.Email); err != nil {
+			problems = append(problems, "invalid email")
+		}
+	}
+	switch e.Language {
+	case "", "ru", "en", "kk":
+	default:
+		problems = append(problems, "language must be ru, en or kk")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Next возвращает ближайшую дату повода не раньше сегодняшнего дня в
+// часовом поясе повода; false - разовый повод уже прошел
+func (e *Entry) Next(now time.Time) (time.Time, bool) {
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	if !e.Yearly {
+		day, err := time.ParseInLocation(dateLayout, e.Date, loc)
+		if err != nil || day.Before(today) {
+			return time.Time{}, false
+		}
+		return day, true
+	}
+
+	day, err := time.Parse(annualDateLayout, e.Date)
+	if err != nil {
+		day, err = time.Parse(dateLayout, e.Date)
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+	next := annualDate(today.Year(), day.Month(), day.Day(), loc)
+	if next.Before(today) {
+		next = annualDate(today.Year()+1, day.Month(), day.Day(), loc)
+	}
+	return next, true
+}
+
+// DaysLeft - сколько дней осталось до ближайшей даты
+func (e *Entry) DaysLeft(now time.Time) (int, bool) {
+	next, ok := e.Next(now)
+	if !ok {
+		return 0, false
+	}
+	local := now.In(next.Location())
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, next.Location())
+	// Считаем по календарным дням: сутки при переходе на летнее время
+	// бывают не 24 часа
+	days := 0
+	for d := today; d.Before(next); d = d.AddDate(0, 0, 1) {
+		days++
+	}
+	return days, true
+}
+
+// annualDate - дата в году year; 29 февраля в невисокосный год - 28-е
+func annualDate(year int, month time.Month, day int, loc *time.Location) time.Time {
+	date := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if date.Month() != month {
+		date = time.Date(year, month+1, 0, 0, 0, 0, 0, loc)
+	}
+	return date
+}
+
+// View - повод вместе с ближайшей датой для календаря
+type View struct {
+	*Entry
+	NextDate string `json:"next_date,omitempty"` // YYYY-MM-DD; пусто - разовый повод прошел
+	DaysLeft *int   `json:"days_left,omitempty"`
+}
+
+func (e *Entry) View(now time.Time) View {
+	view := View{Entry: e}
+	if next, ok := e.Next(now); ok {
+		days, _ := e.DaysLeft(now)
+		view.NextDate = next.Format(dateLayout)
+		view.DaysLeft = &days
+	}
+	return view
+}
+
+// DefaultTimezone читает OCCASION_TIMEZONE (по умолчанию Asia/Almaty)
+func DefaultTimezone() string {
+	if v := os.Getenv("OCCASION_TIMEZONE"); v != "" {
+		return v
+	}
+	return defaultTimezone
+}
+
+// DefaultRemindDays читает OCCASION_REMIND_DAYS (по умолчанию 7)
+func DefaultRemindDays() int {
+	if v, err := strconv.Atoi(os.Getenv("OCCASION_REMIND_DAYS")); err == nil && v > 0 {
+		return v
+	}
+	return defaultRemindDays
+}
+
+// NewID возвращает случайный идентификатор повода
+func NewID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate occasion id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,150 @@
+package occasion
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestEntryNext(t *testing.T) {
+	// 20:00 UTC: в Алматы уже 15 марта, в Москве еще 14-е
+	evening := time.Date(2026, 3, 14, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		entry    Entry
+		now      time.Time
+		want     string
+		wantDays int
+		wantOK   bool
+	}{
+		{
+			name:     "almaty is already on the next day",
+			entry:    Entry{Date: "03-15", Yearly: true, Timezone: "Asia/Almaty"},
+			now:      evening,
+			want:     "2026-03-15",
+			wantDays: 0,
+			wantOK:   true,
+		},
+		{
+			name:     "moscow is still on the utc day",
+			entry:    Entry{Date: "03-15", Yearly: true, Timezone: "Europe/Moscow"},
+			now:      evening,
+			want:     "2026-03-15",
+			wantDays: 1,
+			wantOK:   true,
+		},
+		{
+			name:     "yearly date passed in almaty moves to next year",
+			entry:    Entry{Date: "03-14", Yearly: true, Timezone: "Asia/Almaty"},
+			now:      evening,
+			want:     "2027-03-14",
+			wantDays: 364,
+			wantOK:   true,
+		},
+		{
+			name:     "yearly date is today in moscow",
+			entry:    Entry{Date: "03-14", Yearly: true, Timezone: "Europe/Moscow"},
+			now:      evening,
+			want:     "2026-03-14",
+			wantDays: 0,
+			wantOK:   true,
+		},
+		{
+			name:   "one-off date passed in almaty",
+			entry:  Entry{Date: "2026-03-14", Timezone: "Asia/Almaty"},
+			now:    evening,
+			wantOK: false,
+		},
+		{
+			name:     "one-off date is today in moscow",
+			entry:    Entry{Date: "2026-03-14", Timezone: "Europe/Moscow"},
+			now:      evening,
+			want:     "2026-03-14",
+			wantDays: 0,
+			wantOK:   true,
+		},
+		{
+			name:     "yearly full date repeats",
+			entry:    Entry{Date: "2020-05-10", Yearly: true, Timezone: "Asia/Almaty"},
+			now:      evening,
+			want:     "2026-05-10",
+			wantDays: 56,
+			wantOK:   true,
+		},
+		{
+			name:     "feb 29 in non-leap year",
+			entry:    Entry{Date: "02-29", Yearly: true, Timezone: "Asia/Almaty"},
+			now:      time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC),
+			want:     "2026-02-28",
+			wantDays: 49,
+			wantOK:   true,
+		},
+		{
+			name:     "feb 29 after feb 28 of non-leap year",
+			entry:    Entry{Date: "02-29", Yearly: true, Timezone: "Europe/Moscow"},
+			now:      time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+			want:     "2027-02-28",
+			wantDays: 364,
+			wantOK:   true,
+		},
+		{
+			name:     "feb 29 in leap year",
+			entry:    Entry{Date: "02-29", Yearly: true, Timezone: "Europe/Moscow"},
+			now:      time.Date(2027, 12, 31, 12, 0, 0, 0, time.UTC),
+			want:     "2028-02-29",
+			wantDays: 60,
+			wantOK:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok := tt.entry.Next(tt.now)
+			if ok != tt.wantOK {
+				t.Fatalf("Next() ok = %v, want %v", ok, tt.wantOK)
+			}
+			days, daysOK := tt.entry.DaysLeft(tt.now)
+			if daysOK != tt.wantOK {
+				t.Fatalf("DaysLeft() ok = %v, want %v", daysOK, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got := next.Format(dateLayout); got != tt.want {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+			if next.Location().String() != tt.entry.Timezone {
+				t.Errorf("Next() location = %s, want %s", next.Location(), tt.entry.Timezone)
+			}
+			if days != tt.wantDays {
+				t.Errorf("DaysLeft() = %d, want %d", days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestAnnualDate(t *testing.T) {
+	almaty, err := time.LoadLocation("Asia/Almaty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		year  int
+		month time.Month
+		day   int
+		want  string
+	}{
+		{year: 2026, month: time.February, day: 29, want: "2026-02-28"},
+		{year: 2028, month: time.February, day: 29, want: "2028-02-29"},
+		{year: 2026, month: time.March, day: 15, want: "2026-03-15"},
+		{year: 2026, month: time.December, day: 31, want: "2026-12-31"},
+	}
+	for _, tt := range tests {
+		got := annualDate(tt.year, tt.month, tt.day, almaty)
+		if got.Format(dateLayout) != tt.want || got.Location() != almaty {
+			t.Errorf("annualDate(%d, %s, %d) = %s, want %s", tt.year, tt.month, tt.day, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package occasion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/notify"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recipient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+const (
+	defaultConcurrency = 4
+	// Сколько товаров подборки попадает в текст письма
+	reminderProducts = 3
+)
+
+// Recommender подбирает подарки; его реализует recommend.Service
+type Recommender interface {
+	Recommend(ctx context.Context, request types.GiftRequest, exclude []string) (*types.GiftRecommendation, error)
+}
+
+// Options настраивает прогон планировщика
+type Options struct {
+	Concurrency int  // Сколько напоминаний готовится одновременно
+	DryRun      bool // Только записать в лог, кому пора напомнить
+}
+
+// LoadOptions читает OCCASION_SCHEDULER_CONCURRENCY (4)
+func LoadOptions() (Options, error) {
+	options := Options{Concurrency: defaultConcurrency}
+	if v := os.Getenv("OCCASION_SCHEDULER_CONCURRENCY"); v != "" {
+		concurrency, err := strconv.Atoi(v)
+		if err != nil || concurrency <= 0 {
+			return Options{}, fmt.Errorf("invalid OCCASION_SCHEDULER_CONCURRENCY: %q", v)
+		}
+		options.Concurrency = concurrency
+	}
+	return options, nil
+}
+
+// Report - итог одного прогона
+type Report struct {
+	Occasions    int `json:"occasions"`     // Всего поводов в календарях
+	Due          int `json:"due"`           // Поводов, о которых пора напомнить
+	Reminders    int `json:"reminders"`     // Отправленных напоминаний
+	WithoutGifts int `json:"without_gifts"` // Напоминаний, к которым не удалось подобрать подарки
+	NotifyFailed int `json:"notify_failed"` // Напоминаний, которые не удалось доставить
+}
+
+// Reminder - напоминание о поводе; вебхук и файл получают его целиком
+type Reminder struct {
+	UserID         string                    `json:"user_id"`
+	OccasionID     string                    `json:"occasion_id"`
+	Occasion       string                    `json:"occasion"`
+	RecipientID    string                    `json:"recipient_id,omitempty"`
+	RecipientName  string                    `json:"recipient_name,omitempty"`
+	Date           string                    `json:"date"` // YYYY-MM-DD в часовом поясе повода
+	DaysLeft       int                       `json:"days_left"`
+	Email          string                    `json:"email,omitempty"`
+	Language       string                    `json:"language,omitempty"`
+	Note           string                    `json:"note,omitempty"`
+	Recommendation *types.GiftRecommendation `json:"recommendation,omitempty"`
+}
+
+// Scheduler за RemindDays дней до повода подбирает подарки и отправляет
+// напоминание
+type Scheduler struct {
+	store       Store
+	recipients  recipient.Store
+	recommender Recommender
+	notifier    notify.Notifier
+	options     Options
+	now         func() time.Time
+}
+
+func NewScheduler(store Store, recipients recipient.Store, recommender Recommender, notifier notify.Notifier, options Options) *Scheduler {
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	return &Scheduler{
+		store:       store,
+		recipients:  recipients,
+		recommender: recommender,
+		notifier:    notifier,
+		options:     options,
+		now:         time.Now,
+	}
+}
+
+// Run отправляет напоминания обо всех поводах, до которых осталось не
+// больше RemindDays дней. Ошибка возвращается, если не удалось прочитать
+// календари или не удалось доставить ни одно напоминание.
+func (s *Scheduler) Run(ctx context.Context) (Report, error) {
+	now := s.now()
+
+	var report Report
+	var due []Entry
+	err := s.store.Scan(ctx, func(entry Entry) error {
+		report.Occasions++
+		days, ok := entry.DaysLeft(now)
+		if !ok || days > entry.RemindDays {
+			return nil
+		}
+		next, _ := entry.Next(now)
+		if entry.RemindedFor == next.Format(dateLayout) {
+			return nil
+		}
+		due = append(due, entry)
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+	report.Due = len(due)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, s.options.Concurrency)
+
+	for _, entry := range due {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(entry Entry) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result := s.remind(ctx, entry, now)
+			mu.Lock()
+			report.Reminders += result.Reminders
+			report.WithoutGifts += result.WithoutGifts
+			report.NotifyFailed += result.NotifyFailed
+			mu.Unlock()
+		}(entry)
+	}
+	wg.Wait()
+
+	if report.NotifyFailed > 0 && report.Reminders == 0 {
+		return report, fmt.Errorf("failed to deliver all %d reminders", report.NotifyFailed)
+	}
+	return report, nil
+}
+
+// remind готовит и отправляет одно напоминание
+func (s *Scheduler) remind(ctx context.Context, entry Entry, now time.Time) Report {
+	var report Report
+	next, _ := entry.Next(now)
+	days, _ := entry.DaysLeft(now)
+	date := next.Format(dateLayout)
+
+	if s.options.DryRun {
+		log.Printf("Dry run: remind user %s about %s %s on %s (%d days left)",
+			entry.UserID, entry.Occasion, entry.ID, date, days)
+		return report
+	}
+
+	// Отметка до отправки: параллельный или повторный запуск пропустит повод
+	if err := s.store.Claim(ctx, entry.UserID, entry.ID, date, now.UTC()); err != nil {
+		if !errors.Is(err, ErrClaimed) {
+			log.Printf("Failed to claim reminder %s of user %s: %v", entry.ID, entry.UserID, err)
+		}
+		return report
+	}
+
+	reminder := Reminder{
+		UserID:      entry.UserID,
+		OccasionID:  entry.ID,
+		Occasion:    entry.Occasion,
+		RecipientID: entry.RecipientID,
+		Date:        date,
+		DaysLeft:    days,
+		Email:       entry.Email,
+		Language:    entry.Language,
+		Note:        entry.Note,
+	}
+
+	request := types.GiftRequest{Occasion: entry.Occasion, Language: entry.Language}
+	var exclude []string
+	if entry.RecipientID != "" {
+		person, err := s.recipients.Get(ctx, entry.UserID, entry.RecipientID)
+		switch {
+		case err == nil:
+			reminder.RecipientName = person.Name
+			// Возраст считаем на день повода
+			request = person.GiftRequest(request, next)
+			exclude = person.GiftedProducts()
+		case errors.Is(err, recipient.ErrNotFound):
+			log.Printf("Recipient %s of occasion %s was deleted", entry.RecipientID, entry.ID)
+		default:
+			log.Printf("Failed to load recipient %s: %v", entry.RecipientID, err)
+		}
+	}
+
+	// Без подборки напоминание все равно полезно
+	recommendation, err := s.recommender.Recommend(ctx, request, exclude)
+	if err != nil {
+		log.Printf("Failed to recommend gifts for occasion %s: %v", entry.ID, err)
+		report.WithoutGifts++
+	}
+	reminder.Recommendation = recommendation
+
+	if err := s.notifier.Notify(ctx, reminder.Message()); err != nil {
+		// Снимаем отметку - напомним при следующем запуске
+		log.Printf("Failed to notify user %s about occasion %s: %v", entry.UserID, entry.ID, err)
+		report.NotifyFailed++
+		if err := s.store.Release(ctx, entry.UserID, entry.ID, date); err != nil && !errors.Is(err, ErrClaimed) {
+			log.Printf("Failed to release reminder %s: %v", entry.ID, err)
+		}
+		return report
+	}
+	report.Reminders++
+
+	if err := s.store.Complete(ctx, entry.UserID, entry.ID, date, recommendation); err != nil && !errors.Is(err, ErrClaimed) {
+		log.Printf("Failed to save recommendation for occasion %s: %v", entry.ID, err)
+	}
+	return report
+}
+
+// Тексты напоминания по языку повода
+var reminderTemplates = map[string]struct{ subject, body, forWhom, gifts string }{
+	"ru": {
+		subject: "%s через %d дн.",
+		body:    "%s%s - %s, осталось дней: %d.\n",
+		forWhom: " (%s)",
+		gifts:   "\nИдеи подарков:\n",
+	},
+	"en": {
+		subject: "%s in %d days",
+		body:    "%s%s is on %s, %d days left.\n",
+		forWhom: " for %s",
+		gifts:   "\nGift ideas:\n",
+	},
+	"kk": {
+		subject: "%s - %d күннен кейін",
+		body:    "%s%s - %s, %d күн қалды.\n",
+		forWhom: " (%s)",
+		gifts:   "\nСыйлық идеялары:\n",
+	},
+}
+
+// Message превращает напоминание в сообщение для доставки
+func (r Reminder) Message() notify.Message {
+	lang := r.Language
+	template, ok := reminderTemplates[lang]
+	if !ok {
+		lang = "ru"
+		template = reminderTemplates[lang]
+	}
+
+	name := r.Occasion
+	if occasion, ok := taxonomy.Current().Occasions[r.Occasion]; ok && occasion.Names[lang] != "" {
+		name = occasion.Names[lang]
+	}
+	forWhom := ""
+	if r.RecipientName != "" {
+		forWhom = fmt.Sprintf(template.forWhom, r.RecipientName)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, template.body, name, forWhom, r.Date, r.DaysLeft)
+	if r.Note != "" {
+		fmt.Fprintf(&text, "%s\n", r.Note)
+	}
+	if r.Recommendation != nil && len(r.Recommendation.Products) > 0 {
+		text.WriteString(template.gifts)
+		for i, product := range r.Recommendation.Products {
+			if i == reminderProducts {
+				break
+			}
+			fmt.Fprintf(&text, "- %s, %.0f %s: %s\n", product.Title, product.Price, product.Currency, product.URL)
+		}
+	}
+
+	return notify.Message{
+		Email:   r.Email,
+		Subject: fmt.Sprintf(template.subject, name, r.DaysLeft),
+		Text:    text.String(),
+		Payload: r,
+	}
+}
@@ -0,0 +1,177 @@
+package occasion
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/notify"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recipient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+type stubRecommender struct{}
+
+func (stubRecommender) Recommend(ctx context.Context, request types.GiftRequest, exclude []string) (*types.GiftRecommendation, error) {
+	return &types.GiftRecommendation{
+		Products: []types.Product{{Title: "Плед", Price: 12500, Currency: "KZT", URL: "https://kaspi.kz/shop/p/1"}},
+	}, nil
+}
+
+// stubNotifier запоминает сообщения; err - ошибка доставки
+type stubNotifier struct {
+	mu       sync.Mutex
+	err      error
+	messages []notify.Message
+}
+
+func (n *stubNotifier) Notify(ctx context.Context, message notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return n.err
+	}
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func (n *stubNotifier) sent() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.messages)
+}
+
+func newTestScheduler(t *testing.T, store Store, notifier notify.Notifier, now time.Time) *Scheduler {
+	t.Helper()
+	scheduler := NewScheduler(store, recipient.NewMemoryStore(), stubRecommender{}, notifier, Options{Concurrency: 2})
+	scheduler.now = func() time.Time { return now }
+	return scheduler
+}
+
+func putEntries(t *testing.T, store Store, entries ...Entry) {
+	t.Helper()
+	for i := range entries {
+		if err := store.Put(context.Background(), &entries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var schedulerNow = time.Date(2026, 3, 10, 6, 0, 0, 0, time.UTC)
+
+func TestSchedulerRun(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	putEntries(t, store,
+		Entry{UserID: "u1", ID: "due", Occasion: "birthday", Date: "03-15", Yearly: true, RemindDays: 7, Timezone: "Asia/Almaty", Email: "u1@example.com"},
+		Entry{UserID: "u1", ID: "later", Occasion: "birthday", Date: "05-01", Yearly: true, RemindDays: 7, Timezone: "Asia/Almaty"},
+		Entry{UserID: "u2", ID: "reminded", Occasion: "birthday", Date: "2026-03-12", RemindDays: 7, Timezone: "Europe/Moscow", RemindedFor: "2026-03-12"},
+	)
+	notifier := &stubNotifier{}
+	scheduler := newTestScheduler(t, store, notifier, schedulerNow)
+
+	report, err := scheduler.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report != (Report{Occasions: 3, Due: 1, Reminders: 1}) {
+		t.Errorf("report = %+v", report)
+	}
+	if notifier.sent() != 1 || notifier.messages[0].Email != "u1@example.com" {
+		t.Fatalf("messages = %+v", notifier.messages)
+	}
+
+	entry, err := store.Get(ctx, "u1", "due")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.RemindedFor != "2026-03-15" || entry.Recommendation == nil {
+		t.Errorf("claim not completed: reminded_for = %q, recommendation = %v", entry.RemindedFor, entry.Recommendation)
+	}
+
+	// Повторный запуск в тот же день ничего не отправляет
+	report, err = scheduler.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Due != 0 || notifier.sent() != 1 {
+		t.Errorf("second run: report = %+v, sent = %d", report, notifier.sent())
+	}
+}
+
+func TestSchedulerRunReleasesFailedReminder(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	putEntries(t, store, Entry{UserID: "u1", ID: "due", Occasion: "birthday", Date: "03-15", Yearly: true, RemindDays: 7, Timezone: "Asia/Almaty"})
+
+	failing := &stubNotifier{err: errors.New("smtp down")}
+	report, err := newTestScheduler(t, store, failing, schedulerNow).Run(ctx)
+	if err == nil || report.NotifyFailed != 1 || report.Reminders != 0 {
+		t.Fatalf("report = %+v, err = %v; want failed delivery", report, err)
+	}
+	entry, _ := store.Get(ctx, "u1", "due")
+	if entry.RemindedFor != "" {
+		t.Fatalf("reminded_for = %q, want released claim", entry.RemindedFor)
+	}
+
+	// Отметка снята - следующий запуск напоминает снова
+	notifier := &stubNotifier{}
+	if _, err := newTestScheduler(t, store, notifier, schedulerNow).Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if notifier.sent() != 1 {
+		t.Errorf("sent = %d, want retried reminder", notifier.sent())
+	}
+}
+
+func TestSchedulerRunSkipsClaimedReminder(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	putEntries(t, store, Entry{UserID: "u1", ID: "due", Occasion: "birthday", Date: "03-15", Yearly: true, RemindDays: 7, Timezone: "Asia/Almaty"})
+
+	// Другой запуск успел отметить дату после чтения календаря
+	notifier := &stubNotifier{}
+	scheduler := newTestScheduler(t, store, notifier, schedulerNow)
+	if err := store.Claim(ctx, "u1", "due", "2026-03-15", schedulerNow); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Claim(ctx, "u1", "due", "2026-03-15", schedulerNow); !errors.Is(err, ErrClaimed) {
+		t.Fatalf("second Claim() error = %v, want ErrClaimed", err)
+	}
+
+	report, err := scheduler.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Due != 0 || notifier.sent() != 0 {
+		t.Errorf("report = %+v, sent = %d; claimed date must not be sent again", report, notifier.sent())
+	}
+}
+
+func TestMemoryStoreUpdateKeepsClaim(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	putEntries(t, store, Entry{UserID: "u1", ID: "due", Occasion: "birthday", Date: "03-15", Note: "old", CreatedAt: created})
+
+	// Пользователь прочитал повод, планировщик отметил дату, затем пришла правка
+	if err := store.Claim(ctx, "u1", "due", "2026-03-15", schedulerNow); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := store.Update(ctx, &Entry{UserID: "u1", ID: "due", Occasion: "birthday", Date: "03-15", Note: "new"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Note != "new" || updated.RemindedFor != "2026-03-15" || !updated.CreatedAt.Equal(created) {
+		t.Errorf("Update() = %+v, want new note with claim and created_at kept", updated)
+	}
+	if err := store.Claim(ctx, "u1", "due", "2026-03-15", schedulerNow); !errors.Is(err, ErrClaimed) {
+		t.Errorf("Claim() after Update error = %v, want ErrClaimed", err)
+	}
+
+	if _, err := store.Update(ctx, &Entry{UserID: "u1", ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update() missing error = %v, want ErrNotFound", err)
+	}
+}
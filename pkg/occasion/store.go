@@ -0,0 +1,432 @@
+package occasion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Store хранит календари поводов
+type Store interface {
+	List(ctx context.Context, userID string) ([]Entry, error)
+	Get(ctx context.Context, userID, id string) (*Entry, error)
+	Put(ctx context.Context, entry *Entry) error
+	// Update меняет поля повода, которые задает пользователь, и возвращает
+	// повод целиком. Состояние напоминания (reminded_for, reminded_at,
+	// recommendation) не трогается, чтобы правка не затерла отметку Claim.
+	Update(ctx context.Context, entry *Entry) (*Entry, error)
+	Delete(ctx context.Context, userID, id string) error
+	// Scan перебирает поводы всех пользователей
+	Scan(ctx context.Context, fn func(Entry) error) error
+
+	// Claim отмечает, что о дате date напоминание отправляется, и сбрасывает
+	// прошлую подборку. Если о date уже напомнили - ErrClaimed: так
+	// повторный запуск планировщика не шлет напоминание дважды.
+	Claim(ctx context.Context, userID, id, date string, now time.Time) error
+	// Release снимает отметку, если напоминание не удалось доставить
+	Release(ctx context.Context, userID, id, date string) error
+	// Complete сохраняет подборку, отправленную в напоминании о date
+	Complete(ctx context.Context, userID, id, date string, recommendation *types.GiftRecommendation) error
+}
+
+// NewStoreFromEnv выбирает хранилище по OCCASIONS_BACKEND: dynamodb (по
+// умолчанию, таблица OCCASIONS_TABLE) или memory для локального запуска
+func NewStoreFromEnv(dynamoClient *dynamodb.Client) (Store, error) {
+	switch backend := os.Getenv("OCCASIONS_BACKEND"); backend {
+	case "", "dynamodb":
+		table := os.Getenv("OCCASIONS_TABLE")
+		if table == "" {
+			table = "occasions"
+		}
+		return NewDynamoStore(dynamoClient, table), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown OCCASIONS_BACKEND: %s", backend)
+	}
+}
+
+// DynamoStore хранит поводы в таблице с ключом user_id и ключом сортировки
+// occasion_id
+type DynamoStore struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewDynamoStore(client *dynamodb.Client, tableName string) *DynamoStore {
+	return &DynamoStore{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (s *DynamoStore) List(ctx context.Context, userID string) ([]Entry, error) {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		KeyConditionExpression: aws.String("user_id = :user"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":user": &dyntypes.AttributeValueMemberS{Value: userID},
+		},
+	})
+
+	entries := []Entry{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query occasions: %w", err)
+		}
+		var items []Entry
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal occasions: %w", err)
+		}
+		entries = append(entries, items...)
+	}
+	return entries, nil
+}
+
+func (s *DynamoStore) Get(ctx context.Context, userID, id string) (*Entry, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       key(userID, id),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get occasion: %w", err)
+	}
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var entry Entry
+	if err := attributevalue.UnmarshalMap(result.Item, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal occasion: %w", err)
+	}
+	return &entry, nil
+}
+
+func (s *DynamoStore) Put(ctx context.Context, entry *Entry) error {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal occasion: %w", err)
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put occasion: %w", err)
+	}
+	return nil
+}
+
+// editableAttributes - атрибуты, которые меняет Update
+var editableAttributes = []string{
+	"recipient_id", "occasion", "date", "yearly", "remind_days", "timezone",
+	"email_reminders", "email", "language", "note", "updated_at",
+}
+
+func (s *DynamoStore) Update(ctx context.Context, entry *Entry) (*Entry, error) {
+	item, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal occasion: %w", err)
+	}
+
+	// Пустые omitempty-поля не попадают в item - их удаляем
+	var set, remove []string
+	names := make(map[string]string, len(editableAttributes))
+	values := make(map[string]dyntypes.AttributeValue, len(editableAttributes))
+	for i, attribute := range editableAttributes {
+		name := "#a" + strconv.Itoa(i)
+		names[name] = attribute
+		if value, ok := item[attribute]; ok {
+			values[":v"+strconv.Itoa(i)] = value
+			set = append(set, name+" = :v"+strconv.Itoa(i))
+		} else {
+			remove = append(remove, name)
+		}
+	}
+	expression := "SET " + strings.Join(set, ", ")
+	if len(remove) > 0 {
+		expression += " REMOVE " + strings.Join(remove, ", ")
+	}
+
+	result, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.tableName),
+		Key:                       key(entry.UserID, entry.ID),
+		UpdateExpression:          aws.String(expression),
+		ConditionExpression:       aws.String("attribute_exists(occasion_id)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              dyntypes.ReturnValueAllNew,
+	})
+	if err != nil {
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update occasion: %w", err)
+	}
+
+	var updated Entry
+	if err := attributevalue.UnmarshalMap(result.Attributes, &updated); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal occasion: %w", err)
+	}
+	return &updated, nil
+}
+
+func (s *DynamoStore) Delete(ctx context.Context, userID, id string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.tableName),
+		Key:                 key(userID, id),
+		ConditionExpression: aws.String("attribute_exists(occasion_id)"),
+	})
+	if err != nil {
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete occasion: %w", err)
+	}
+	return nil
+}
+
+func (s *DynamoStore) Scan(ctx context.Context, fn func(Entry) error) error {
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName: aws.String(s.tableName),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to scan occasions: %w", err)
+		}
+
+		var entries []Entry
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &entries); err != nil {
+			return fmt.Errorf("failed to unmarshal occasions: %w", err)
+		}
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *DynamoStore) Claim(ctx context.Context, userID, id, date string, now time.Time) error {
+	remindedAt, err := attributevalue.Marshal(now)
+	if err != nil {
+		return fmt.Errorf("failed to marshal time: %w", err)
+	}
+	return s.update(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.tableName),
+		Key:                 key(userID, id),
+		UpdateExpression:    aws.String("SET reminded_for = :date, reminded_at = :now REMOVE recommendation"),
+		ConditionExpression: aws.String("attribute_exists(occasion_id) AND (attribute_not_exists(reminded_for) OR reminded_for <> :date)"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":date": &dyntypes.AttributeValueMemberS{Value: date},
+			":now":  remindedAt,
+		},
+	})
+}
+
+func (s *DynamoStore) Release(ctx context.Context, userID, id, date string) error {
+	return s.update(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.tableName),
+		Key:                 key(userID, id),
+		UpdateExpression:    aws.String("REMOVE reminded_for"),
+		ConditionExpression: aws.String("reminded_for = :date"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":date": &dyntypes.AttributeValueMemberS{Value: date},
+		},
+	})
+}
+
+func (s *DynamoStore) Complete(ctx context.Context, userID, id, date string, recommendation *types.GiftRecommendation) error {
+	if recommendation == nil {
+		return nil
+	}
+	value, err := attributevalue.Marshal(recommendation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recommendation: %w", err)
+	}
+	return s.update(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.tableName),
+		Key:                 key(userID, id),
+		UpdateExpression:    aws.String("SET recommendation = :recommendation"),
+		ConditionExpression: aws.String("reminded_for = :date"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":date":           &dyntypes.AttributeValueMemberS{Value: date},
+			":recommendation": value,
+		},
+	})
+}
+
+// update выполняет условное обновление; невыполненное условие - ErrClaimed
+func (s *DynamoStore) update(ctx context.Context, input *dynamodb.UpdateItemInput) error {
+	if _, err := s.client.UpdateItem(ctx, input); err != nil {
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrClaimed
+		}
+		return fmt.Errorf("failed to update occasion reminder: %w", err)
+	}
+	return nil
+}
+
+func key(userID, id string) map[string]dyntypes.AttributeValue {
+	return map[string]dyntypes.AttributeValue{
+		"user_id":     &dyntypes.AttributeValueMemberS{Value: userID},
+		"occasion_id": &dyntypes.AttributeValueMemberS{Value: id},
+	}
+}
+
+// MemoryStore хранит поводы в памяти процесса - для локального запуска
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]map[string]Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]map[string]Entry)}
+}
+
+func (s *MemoryStore) List(ctx context.Context, userID string) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, 0, len(s.entries[userID]))
+	for _, entry := range s.entries[userID] {
+		entries = append(entries, entry)
+	}
+	// Как в DynamoDB - по ключу сортировки
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, userID, id string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[userID][id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &entry, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries[entry.UserID] == nil {
+		s.entries[entry.UserID] = make(map[string]Entry)
+	}
+	s.entries[entry.UserID][entry.ID] = *entry
+	return nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, entry *Entry) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.entries[entry.UserID][entry.ID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	updated := *entry
+	updated.RemindedFor = current.RemindedFor
+	updated.RemindedAt = current.RemindedAt
+	updated.Recommendation = current.Recommendation
+	updated.CreatedAt = current.CreatedAt
+	s.entries[entry.UserID][entry.ID] = updated
+	return &updated, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[userID][id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.entries[userID], id)
+	return nil
+}
+
+func (s *MemoryStore) Scan(ctx context.Context, fn func(Entry) error) error {
+	s.mu.Lock()
+	var entries []Entry
+	for _, userEntries := range s.entries {
+		for _, entry := range userEntries {
+			entries = append(entries, entry)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, entry := range entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Claim(ctx context.Context, userID, id, date string, now time.Time) error {
+	return s.update(userID, id, func(entry *Entry) bool {
+		if entry.RemindedFor == date {
+			return false
+		}
+		entry.RemindedFor = date
+		entry.RemindedAt = now
+		entry.Recommendation = nil
+		return true
+	})
+}
+
+func (s *MemoryStore) Release(ctx context.Context, userID, id, date string) error {
+	return s.update(userID, id, func(entry *Entry) bool {
+		if entry.RemindedFor != date {
+			return false
+		}
+		entry.RemindedFor = ""
+		return true
+	})
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, userID, id, date string, recommendation *types.GiftRecommendation) error {
+	if recommendation == nil {
+		return nil
+	}
+	return s.update(userID, id, func(entry *Entry) bool {
+		if entry.RemindedFor != date {
+			return false
+		}
+		entry.Recommendation = recommendation
+		return true
+	})
+}
+
+// update применяет change, если повод есть и условие change выполнено
+func (s *MemoryStore) update(userID, id string, change func(*Entry) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[userID][id]
+	if !ok || !change(&entry) {
+		return ErrClaimed
+	}
+	s.entries[userID][id] = entry
+	return nil
+}
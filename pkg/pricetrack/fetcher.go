@@ -0,0 +1,72 @@
+package pricetrack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/marketplace"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Fetcher получает актуальную версию сохраненного товара. Если товар
+// больше не продается - ErrProductGone.
+type Fetcher interface {
+	Fetch(ctx context.Context, product types.Product) (*types.Product, error)
+}
+
+// Searcher - поиск товаров по источникам (marketplace.ProductService)
+type Searcher interface {
+	SearchProducts(ctx context.Context, intent types.SearchIntent) (*marketplace.SearchResult, error)
+}
+
+// SearchFetcher находит товар повторным поиском по его названию и категории
+// во всех источниках. У маркетплейсов нет общего API получения товара по
+// ID, поэтому товар ищется среди результатов по ID предложения или ссылке.
+type SearchFetcher struct {
+	searcher Searcher
+}
+
+func NewSearchFetcher(searcher Searcher) *SearchFetcher {
+	return &SearchFetcher{searcher: searcher}
+}
+
+func (f *SearchFetcher) Fetch(ctx context.Context, product types.Product) (*types.Product, error) {
+	intent := types.SearchIntent{
+		Query:      product.Title,
+		PriceRange: types.Range{Currency: product.Currency},
+	}
+	if product.Category != "" {
+		intent.Categories = []string{product.Category}
+	}
+
+	result, err := f.searcher.SearchProducts(ctx, intent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search product %s: %w", product.ID, err)
+	}
+
+	url := marketplace.CanonicalURL(product.URL)
+	for _, found := range result.Products {
+		// После объединения дубликатов нужное предложение может оказаться
+		// внутри другого товара - цену берем именно из него
+		for _, offer := range found.Offers {
+			if offer.ProductID == product.ID || (url != "" && marketplace.CanonicalURL(offer.URL) == url) {
+				current := found
+				current.ID = product.ID
+				current.Price = offer.Price
+				if offer.Currency != "" {
+					current.Currency = offer.Currency
+				}
+				current.Store = offer.Store
+				if offer.URL != "" {
+					current.URL = offer.URL
+				}
+				return &current, nil
+			}
+		}
+		if found.ID == product.ID || (url != "" && marketplace.CanonicalURL(found.URL) == url) {
+			current := found
+			return &current, nil
+		}
+	}
+	return nil, ErrProductGone
+}
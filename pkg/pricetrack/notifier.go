@@ -0,0 +1,40 @@
+package pricetrack
+
+import (
+	"fmt"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/notify"
+)
+
+// Тексты письма по языку подписки
+var emailTemplates = map[string]struct{ subject, body string }{
+	"ru": {
+		subject: "Подешевело: %s",
+		body:    "Цена на «%s» снизилась с %s до %s %s (-%.0f%%).\n\n%s\n",
+	},
+	"en": {
+		subject: "Price drop: %s",
+		body:    "The price of \"%s\" dropped from %s to %s %s (-%.0f%%).\n\n%s\n",
+	},
+	"kk": {
+		subject: "Арзандады: %s",
+		body:    "«%s» бағасы төмендеді: %s → %s %s (-%.0f%%).\n\n%s\n",
+	},
+}
+
+// Message превращает оповещение в сообщение для доставки: письмо на языке
+// подписки, а вебхук и файл получают Alert целиком
+func (a Alert) Message() notify.Message {
+	template, ok := emailTemplates[a.Language]
+	if !ok {
+		template = emailTemplates["ru"]
+	}
+	title := a.Product.Title
+	return notify.Message{
+		Email:   a.Email,
+		Subject: fmt.Sprintf(template.subject, title),
+		Text: fmt.Sprintf(template.body, title, formatNumber(a.OldPrice), formatNumber(a.NewPrice),
+			a.Currency, a.DropPercent(), a.Product.URL),
+		Payload: a,
+	}
+}
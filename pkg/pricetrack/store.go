@@ -0,0 +1,398 @@
+package pricetrack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Сколько хранится история цен
+const defaultHistoryTTL = 365 * 24 * time.Hour
+
+// Check - результат проверки цены для одной подписки
+type Check struct {
+	Price         float64
+	CheckedAt     time.Time
+	NotifiedPrice float64 // 0 - оповещения не было, прежнее значение не меняется
+}
+
+// Store хранит подписки на цены и историю цен товаров
+type Store interface {
+	List(ctx context.Context, userID string) ([]Watch, error)
+	Get(ctx context.Context, userID, productID string) (*Watch, error)
+	Put(ctx context.Context, watch *Watch) error
+	Delete(ctx context.Context, userID, productID string) error
+	// Scan перебирает подписки всех пользователей
+	Scan(ctx context.Context, fn func(Watch) error) error
+	// RecordCheck сохраняет результат проверки, не трогая остальные поля;
+	// удаленную подписку не воскрешает - ErrNotFound
+	RecordCheck(ctx context.Context, userID, productID string, check Check) error
+
+	AppendHistory(ctx context.Context, point Point) error
+	// History возвращает цены товара не старше since по возрастанию времени
+	History(ctx context.Context, productID string, since time.Time) ([]Point, error)
+}
+
+// NewStoreFromEnv выбирает хранилище по PRICE_WATCH_BACKEND: dynamodb (по
+// умолчанию, таблицы PRICE_WATCH_TABLE и PRICE_HISTORY_TABLE) или memory
+// для локального запуска
+func NewStoreFromEnv(dynamoClient *dynamodb.Client) (Store, error) {
+	switch backend := os.Getenv("PRICE_WATCH_BACKEND"); backend {
+	case "", "dynamodb":
+		watchTable := os.Getenv("PRICE_WATCH_TABLE")
+		if watchTable == "" {
+			watchTable = "price_watches"
+		}
+		historyTable := os.Getenv("PRICE_HISTORY_TABLE")
+		if historyTable == "" {
+			historyTable = "price_history"
+		}
+		return NewDynamoStore(dynamoClient, watchTable, historyTable), nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown PRICE_WATCH_BACKEND: %s", backend)
+	}
+}
+
+// LoadHistoryTTL читает PRICE_HISTORY_TTL (по умолчанию год)
+func LoadHistoryTTL() (time.Duration, error) {
+	v := os.Getenv("PRICE_HISTORY_TTL")
+	if v == "" {
+		return defaultHistoryTTL, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid PRICE_HISTORY_TTL: %w", err)
+	}
+	return d, nil
+}
+
+// DynamoStore хранит подписки в таблице с ключом user_id и ключом
+// сортировки product_id, а историю - в таблице с ключом product_id,
+// ключом сортировки checked_at и TTL по expires_at
+type DynamoStore struct {
+	client       *dynamodb.Client
+	watchTable   string
+	historyTable string
+}
+
+func NewDynamoStore(client *dynamodb.Client, watchTable, historyTable string) *DynamoStore {
+	return &DynamoStore{
+		client:       client,
+		watchTable:   watchTable,
+		historyTable: historyTable,
+	}
+}
+
+func (s *DynamoStore) List(ctx context.Context, userID string) ([]Watch, error) {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.watchTable),
+		KeyConditionExpression: aws.String("user_id = :user"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":user": &dyntypes.AttributeValueMemberS{Value: userID},
+		},
+	})
+
+	watches := []Watch{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query price watches: %w", err)
+		}
+		var items []Watch
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal price watches: %w", err)
+		}
+		watches = append(watches, items...)
+	}
+	return watches, nil
+}
+
+func (s *DynamoStore) Get(ctx context.Context, userID, productID string) (*Watch, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.watchTable),
+		Key:       watchKey(userID, productID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price watch: %w", err)
+	}
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var watch Watch
+	if err := attributevalue.UnmarshalMap(result.Item, &watch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal price watch: %w", err)
+	}
+	return &watch, nil
+}
+
+func (s *DynamoStore) Put(ctx context.Context, watch *Watch) error {
+	item, err := attributevalue.MarshalMap(watch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal price watch: %w", err)
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.watchTable),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put price watch: %w", err)
+	}
+	return nil
+}
+
+func (s *DynamoStore) Delete(ctx context.Context, userID, productID string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.watchTable),
+		Key:                 watchKey(userID, productID),
+		ConditionExpression: aws.String("attribute_exists(product_id)"),
+	})
+	if err != nil {
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete price watch: %w", err)
+	}
+	return nil
+}
+
+func (s *DynamoStore) Scan(ctx context.Context, fn func(Watch) error) error {
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName: aws.String(s.watchTable),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to scan price watches: %w", err)
+		}
+
+		var watches []Watch
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &watches); err != nil {
+			return fmt.Errorf("failed to unmarshal price watches: %w", err)
+		}
+		for _, watch := range watches {
+			if err := fn(watch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *DynamoStore) RecordCheck(ctx context.Context, userID, productID string, check Check) error {
+	checkedAt, err := attributevalue.Marshal(check.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("failed to marshal time: %w", err)
+	}
+
+	update := "SET current_price = :price, checked_at = :checked"
+	values := map[string]dyntypes.AttributeValue{
+		":price":   &dyntypes.AttributeValueMemberN{Value: formatNumber(check.Price)},
+		":checked": checkedAt,
+	}
+	if check.NotifiedPrice > 0 {
+		update += ", notified_price = :notified"
+		values[":notified"] = &dyntypes.AttributeValueMemberN{Value: formatNumber(check.NotifiedPrice)}
+	}
+
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.watchTable),
+		Key:                       watchKey(userID, productID),
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("attribute_exists(product_id)"),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to record price check: %w", err)
+	}
+	return nil
+}
+
+func (s *DynamoStore) AppendHistory(ctx context.Context, point Point) error {
+	item, err := attributevalue.MarshalMap(point)
+	if err != nil {
+		return fmt.Errorf("failed to marshal price point: %w", err)
+	}
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.historyTable),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put price point: %w", err)
+	}
+	return nil
+}
+
+func (s *DynamoStore) History(ctx context.Context, productID string, since time.Time) ([]Point, error) {
+	sinceValue, err := attributevalue.Marshal(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal time: %w", err)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.historyTable),
+		KeyConditionExpression: aws.String("product_id = :product AND checked_at >= :since"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":product": &dyntypes.AttributeValueMemberS{Value: productID},
+			":since":   sinceValue,
+		},
+	})
+
+	points := []Point{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query price history: %w", err)
+		}
+		var items []Point
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal price history: %w", err)
+		}
+		points = append(points, items...)
+	}
+	return points, nil
+}
+
+func watchKey(userID, productID string) map[string]dyntypes.AttributeValue {
+	return map[string]dyntypes.AttributeValue{
+		"user_id":    &dyntypes.AttributeValueMemberS{Value: userID},
+		"product_id": &dyntypes.AttributeValueMemberS{Value: productID},
+	}
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// MemoryStore хранит подписки и историю в памяти процесса - для локального
+// запуска
+type MemoryStore struct {
+	mu      sync.Mutex
+	watches map[string]map[string]Watch
+	history map[string][]Point
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		watches: make(map[string]map[string]Watch),
+		history: make(map[string][]Point),
+	}
+}
+
+func (s *MemoryStore) List(ctx context.Context, userID string) ([]Watch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watches := make([]Watch, 0, len(s.watches[userID]))
+	for _, watch := range s.watches[userID] {
+		watches = append(watches, watch)
+	}
+	// Как в DynamoDB - по ключу сортировки
+	sort.Slice(watches, func(i, j int) bool { return watches[i].ProductID < watches[j].ProductID })
+	return watches, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, userID, productID string) (*Watch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watch, ok := s.watches[userID][productID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &watch, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, watch *Watch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.watches[watch.UserID] == nil {
+		s.watches[watch.UserID] = make(map[string]Watch)
+	}
+	s.watches[watch.UserID][watch.ProductID] = *watch
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, userID, productID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.watches[userID][productID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.watches[userID], productID)
+	return nil
+}
+
+func (s *MemoryStore) Scan(ctx context.Context, fn func(Watch) error) error {
+	s.mu.Lock()
+	var watches []Watch
+	for _, userWatches := range s.watches {
+		for _, watch := range userWatches {
+			watches = append(watches, watch)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, watch := range watches {
+		if err := fn(watch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) RecordCheck(ctx context.Context, userID, productID string, check Check) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watch, ok := s.watches[userID][productID]
+	if !ok {
+		return ErrNotFound
+	}
+	watch.CurrentPrice = check.Price
+	watch.CheckedAt = check.CheckedAt
+	if check.NotifiedPrice > 0 {
+		watch.NotifiedPrice = check.NotifiedPrice
+	}
+	s.watches[userID][productID] = watch
+	return nil
+}
+
+func (s *MemoryStore) AppendHistory(ctx context.Context, point Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history[point.ProductID] = append(s.history[point.ProductID], point)
+	return nil
+}
+
+func (s *MemoryStore) History(ctx context.Context, productID string, since time.Time) ([]Point, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	points := []Point{}
+	for _, point := range s.history[productID] {
+		if !point.CheckedAt.Before(since) {
+			points = append(points, point)
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,232 @@
+package pricetrack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/notify"
+)
+
+// Значения по умолчанию для Options
+const (
+	defaultDropThreshold = 0.05
+	defaultConcurrency   = 4
+)
+
+// Options настраивает проверку цен
+type Options struct {
+	DropThreshold float64       // Доля снижения цены, о которой стоит сообщить (без целевой цены)
+	Concurrency   int           // Сколько товаров проверяется одновременно
+	HistoryTTL    time.Duration // Сколько хранить точки истории
+	DryRun        bool          // Только проверить и записать в лог, ничего не сохранять и не отправлять
+}
+
+// LoadOptions читает PRICE_DROP_THRESHOLD (0.05), PRICE_TRACKER_CONCURRENCY (4)
+// и PRICE_HISTORY_TTL
+func LoadOptions() (Options, error) {
+	options := Options{
+		DropThreshold: defaultDropThreshold,
+		Concurrency:   defaultConcurrency,
+	}
+
+	if v := os.Getenv("PRICE_DROP_THRESHOLD"); v != "" {
+		threshold, err := strconv.ParseFloat(v, 64)
+		if err != nil || threshold <= 0 || threshold >= 1 {
+			return Options{}, fmt.Errorf("invalid PRICE_DROP_THRESHOLD: %q", v)
+		}
+		options.DropThreshold = threshold
+	}
+	if v := os.Getenv("PRICE_TRACKER_CONCURRENCY"); v != "" {
+		concurrency, err := strconv.Atoi(v)
+		if err != nil || concurrency <= 0 {
+			return Options{}, fmt.Errorf("invalid PRICE_TRACKER_CONCURRENCY: %q", v)
+		}
+		options.Concurrency = concurrency
+	}
+
+	ttl, err := LoadHistoryTTL()
+	if err != nil {
+		return Options{}, err
+	}
+	options.HistoryTTL = ttl
+	return options, nil
+}
+
+// Report - итог одного прогона
+type Report struct {
+	Products     int `json:"products"`      // Уникальных отслеживаемых товаров
+	Checked      int `json:"checked"`       // Товаров с полученной ценой
+	Gone         int `json:"gone"`          // Товаров, которых больше нет в источниках
+	Failed       int `json:"failed"`        // Ошибок получения цены
+	Alerts       int `json:"alerts"`        // Отправленных оповещений
+	NotifyFailed int `json:"notify_failed"` // Оповещений, которые не удалось доставить
+}
+
+// Tracker перепроверяет цены отслеживаемых товаров, ведет историю и
+// оповещает подписчиков о снижении
+type Tracker struct {
+	store    Store
+	fetcher  Fetcher
+	notifier notify.Notifier
+	rates    money.RateTable
+	options  Options
+	now      func() time.Time
+}
+
+func NewTracker(store Store, fetcher Fetcher, notifier notify.Notifier, options Options) *Tracker {
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	if options.DropThreshold <= 0 {
+		options.DropThreshold = defaultDropThreshold
+	}
+
+	rates, err := money.LoadRates()
+	if err != nil {
+		fmt.Printf("Failed to load currency rates, using defaults: %v\n", err)
+		rates = money.DefaultRates
+	}
+
+	return &Tracker{
+		store:    store,
+		fetcher:  fetcher,
+		notifier: notifier,
+		rates:    rates,
+		options:  options,
+		now:      time.Now,
+	}
+}
+
+// Run проверяет все отслеживаемые товары. Ошибки отдельных товаров
+// попадают в отчет; ошибка возвращается, если не удалось прочитать
+// подписки или не удалось проверить ни один товар.
+func (t *Tracker) Run(ctx context.Context) (Report, error) {
+	// Один товар могут отслеживать несколько пользователей - цену
+	// запрашиваем один раз
+	byProduct := make(map[string][]Watch)
+	err := t.store.Scan(ctx, func(watch Watch) error {
+		byProduct[watch.ProductID] = append(byProduct[watch.ProductID], watch)
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Products: len(byProduct)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, t.options.Concurrency)
+
+	for _, watches := range byProduct {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(watches []Watch) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result := t.check(ctx, watches)
+			mu.Lock()
+			report.Checked += result.Checked
+			report.Gone += result.Gone
+			report.Failed += result.Failed
+			report.Alerts += result.Alerts
+			report.NotifyFailed += result.NotifyFailed
+			mu.Unlock()
+		}(watches)
+	}
+	wg.Wait()
+
+	if report.Failed > 0 && report.Checked == 0 && report.Gone == 0 {
+		return report, fmt.Errorf("failed to check prices of all %d products", report.Failed)
+	}
+	return report, nil
+}
+
+// check получает цену одного товара и обрабатывает всех его подписчиков
+func (t *Tracker) check(ctx context.Context, watches []Watch) Report {
+	var report Report
+	saved := watches[0].Product
+
+	current, err := t.fetcher.Fetch(ctx, saved)
+	if errors.Is(err, ErrProductGone) {
+		log.Printf("Product %s is no longer listed", saved.ID)
+		report.Gone++
+		return report
+	}
+	if err != nil {
+		log.Printf("Failed to fetch price of %s: %v", saved.ID, err)
+		report.Failed++
+		return report
+	}
+	report.Checked++
+
+	now := t.now().UTC()
+	if !t.options.DryRun {
+		point := Point{
+			ProductID: saved.ID,
+			CheckedAt: now,
+			Price:     current.Price,
+			Currency:  current.Currency,
+			Store:     current.Store,
+		}
+		if t.options.HistoryTTL > 0 {
+			point.ExpiresAt = now.Add(t.options.HistoryTTL).Unix()
+		}
+		if err := t.store.AppendHistory(ctx, point); err != nil {
+			log.Printf("Failed to save price history of %s: %v", saved.ID, err)
+		}
+	}
+
+	for _, watch := range watches {
+		// Сравниваем в валюте, в которой товар был сохранен
+		price, err := t.rates.Convert(current.Price, current.Currency, watch.Product.Currency)
+		if err != nil {
+			log.Printf("Price conversion error for %s: %v", saved.ID, err)
+			continue
+		}
+
+		check := Check{Price: price, CheckedAt: now}
+		if reason, oldPrice, ok := watch.evaluate(price, t.options.DropThreshold); ok {
+			alert := Alert{
+				UserID:      watch.UserID,
+				Email:       watch.Email,
+				Language:    watch.Language,
+				Reason:      reason,
+				Product:     *current,
+				OldPrice:    oldPrice,
+				NewPrice:    price,
+				Currency:    watch.Product.Currency,
+				TargetPrice: watch.TargetPrice,
+				DetectedAt:  now,
+			}
+
+			if t.options.DryRun {
+				log.Printf("Dry run: alert for user %s, %s %.2f -> %.2f %s",
+					watch.UserID, saved.ID, oldPrice, price, alert.Currency)
+			} else if err := t.notifier.Notify(ctx, alert.Message()); err != nil {
+				// Цену оповещения не запоминаем - повторим в следующий раз
+				log.Printf("Failed to notify user %s about %s: %v", watch.UserID, saved.ID, err)
+				report.NotifyFailed++
+			} else {
+				check.NotifiedPrice = price
+				report.Alerts++
+			}
+		}
+
+		if t.options.DryRun {
+			continue
+		}
+		err = t.store.RecordCheck(ctx, watch.UserID, watch.ProductID, check)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			log.Printf("Failed to record price check for user %s: %v", watch.UserID, err)
+		}
+	}
+	return report
+}
@@ -0,0 +1,162 @@
+package pricetrack
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/notify"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// stubFetcher отдает заданные цены и считает запросы по товарам
+type stubFetcher struct {
+	mu     sync.Mutex
+	prices map[string]float64
+	calls  map[string]int
+}
+
+func (f *stubFetcher) Fetch(ctx context.Context, product types.Product) (*types.Product, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[product.ID]++
+
+	price, ok := f.prices[product.ID]
+	if !ok {
+		return nil, ErrProductGone
+	}
+	current := product
+	current.Price = price
+	return &current, nil
+}
+
+// stubNotifier запоминает сообщения; адресатам из failFor доставка не удается
+type stubNotifier struct {
+	mu       sync.Mutex
+	failFor  map[string]bool
+	messages []notify.Message
+}
+
+func (n *stubNotifier) Notify(ctx context.Context, message notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.failFor[message.Email] {
+		return errors.New("delivery failed")
+	}
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func newTestTracker(t *testing.T, store Store, fetcher Fetcher, notifier notify.Notifier) *Tracker {
+	t.Helper()
+	t.Setenv("CURRENCY_RATES", "")
+	t.Setenv("CURRENCY_RATES_FILE", "")
+	tracker := NewTracker(store, fetcher, notifier, Options{DropThreshold: 0.05, Concurrency: 2})
+	tracker.now = func() time.Time { return time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC) }
+	return tracker
+}
+
+func putWatch(t *testing.T, store Store, userID string, product types.Product) {
+	t.Helper()
+	watch := &Watch{UserID: userID, Product: product, Email: userID + "@example.com"}
+	if err := watch.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(context.Background(), watch); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTrackerRun(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	headphones := types.Product{ID: "kaspi-1", Title: "Наушники", Price: 10000, Currency: "KZT", Store: "kaspi"}
+	kettle := types.Product{ID: "ozon-2", Title: "Чайник", Price: 3000, Currency: "RUB", Store: "ozon"}
+	putWatch(t, store, "u1", headphones)
+	putWatch(t, store, "u2", headphones)
+	putWatch(t, store, "u3", headphones)
+	putWatch(t, store, "u1", kettle)
+
+	fetcher := &stubFetcher{
+		prices: map[string]float64{"kaspi-1": 9000},
+		calls:  make(map[string]int),
+	}
+	notifier := &stubNotifier{failFor: map[string]bool{"u2@example.com": true}}
+
+	report, err := newTestTracker(t, store, fetcher, notifier).Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Товар трех подписчиков запрашивается один раз
+	if fetcher.calls["kaspi-1"] != 1 || fetcher.calls["ozon-2"] != 1 {
+		t.Errorf("fetch calls = %v, want one per product", fetcher.calls)
+	}
+	want := Report{Products: 2, Checked: 1, Gone: 1, Alerts: 2, NotifyFailed: 1}
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	if len(notifier.messages) != 2 {
+		t.Fatalf("messages = %d, want 2", len(notifier.messages))
+	}
+
+	for _, tt := range []struct {
+		userID       string
+		wantNotified float64
+	}{
+		{"u1", 9000},
+		{"u2", 0}, // Доставка не удалась - оповестим при следующей проверке
+		{"u3", 9000},
+	} {
+		watch, err := store.Get(ctx, tt.userID, "kaspi-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if watch.CurrentPrice != 9000 || watch.NotifiedPrice != tt.wantNotified {
+			t.Errorf("%s: current %v, notified %v; want 9000, %v",
+				tt.userID, watch.CurrentPrice, watch.NotifiedPrice, tt.wantNotified)
+		}
+	}
+
+	history, err := store.History(ctx, "kaspi-1", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Price != 9000 {
+		t.Errorf("history = %+v, want one point at 9000", history)
+	}
+
+	// Повторный прогон по той же цене оповещает только того, до кого
+	// письмо не дошло
+	notifier.failFor = nil
+	notifier.messages = nil
+	report, err = newTestTracker(t, store, fetcher, notifier).Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Alerts != 1 || len(notifier.messages) != 1 || notifier.messages[0].Email != "u2@example.com" {
+		t.Errorf("second run: report %+v, messages %+v", report, notifier.messages)
+	}
+}
+
+func TestTrackerRunAllFailed(t *testing.T) {
+	store := NewMemoryStore()
+	putWatch(t, store, "u1", types.Product{ID: "kaspi-1", Title: "Наушники", Price: 10000, Currency: "KZT"})
+
+	tracker := newTestTracker(t, store, failingFetcher{}, &stubNotifier{})
+	report, err := tracker.Run(context.Background())
+	if err == nil {
+		t.Fatal("Run() must fail when no product was checked")
+	}
+	if report.Failed != 1 {
+		t.Errorf("report = %+v, want one failed product", report)
+	}
+}
+
+type failingFetcher struct{}
+
+func (failingFetcher) Fetch(ctx context.Context, product types.Product) (*types.Product, error) {
+	return nil, errors.New("source unavailable")
+}
@@ -0,0 +1,127 @@
+package pricetrack
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Сколько товаров может отслеживать один пользователь
+const MaxWatches = 50
+
+var (
+	ErrNotFound = errors.New("price watch not found")
+	// ErrInvalid - некорректный товар, целевая цена или адрес
+	ErrInvalid = errors.New("invalid price watch")
+	// ErrNoVerifiedEmail - письма запрошены, но у пользователя нет
+	// подтвержденного адреса
+	ErrNoVerifiedEmail = errors.New("no verified email for price alerts")
+	// ErrProductGone - товар больше не находится в источниках
+	ErrProductGone = errors.New("product is no longer listed")
+)
+
+// Причины оповещения
+const (
+	ReasonDrop   = "drop"   // Цена упала на порог и больше
+	ReasonTarget = "target" // Цена дошла до целевой
+)
+
+// Watch - подписка пользователя на цену товара
+type Watch struct {
+	UserID      string        `dynamodbav:"user_id" json:"-"`
+	ProductID   string        `dynamodbav:"product_id" json:"product_id"`
+	Product     types.Product `dynamodbav:"product" json:"product"`                               // Товар из источника на момент подписки - от его цены считается снижение
+	TargetPrice float64       `dynamodbav:"target_price,omitempty" json:"target_price,omitempty"` // В валюте товара; 0 - оповещать о любом заметном снижении
+	Email       string        `dynamodbav:"email,omitempty" json:"email,omitempty"`               // Подтвержденный адрес пользователя (auth.Email), не из запроса
+	Language    string        `dynamodbav:"language,omitempty" json:"language,omitempty"`
+	CreatedAt   time.Time     `dynamodbav:"created_at" json:"created_at"`
+
+	// Заполняет трекер
+	CurrentPrice  float64   `dynamodbav:"current_price,omitempty" json:"current_price,omitempty"`
+	CheckedAt     time.Time `dynamodbav:"checked_at" json:"checked_at,omitzero"`
+	NotifiedPrice float64   `dynamodbav:"notified_price,omitempty" json:"notified_price,omitempty"` // Цена в последнем оповещении
+}
+
+// Normalize проверяет подписку перед сохранением
+func (w *Watch) Normalize() error {
+	if w.Product.ID == "" || strings.TrimSpace(w.Product.Title) == "" {
+		return fmt.Errorf("%w: product id and title are required", ErrInvalid)
+	}
+	if w.Product.Price <= 0 || w.Product.Currency == "" {
+		return fmt.Errorf("%w: product price and currency are required", ErrInvalid)
+	}
+	if w.TargetPrice < 0 {
+		return fmt.Errorf("%w: target_price must not be negative", ErrInvalid)
+	}
+	w.Email = strings.TrimSpace(w.Email)
+	if w.Email != "" {
+		if _, err := mail.ParseAddress(w.Email); err != nil {
+			return fmt.Errorf("%w: invalid email", ErrInvalid)
+		}
+	}
+	switch w.Language {
+	case "", "ru", "en", "kk":
+	default:
+		return fmt.Errorf("%w: language must be ru, en or kk", ErrInvalid)
+	}
+	w.ProductID = w.Product.ID
+	return nil
+}
+
+// Point - цена товара в момент проверки
+type Point struct {
+	ProductID string    `dynamodbav:"product_id" json:"-"`
+	CheckedAt time.Time `dynamodbav:"checked_at" json:"checked_at"`
+	Price     float64   `dynamodbav:"price" json:"price"`
+	Currency  string    `dynamodbav:"currency" json:"currency"`
+	Store     string    `dynamodbav:"store,omitempty" json:"store,omitempty"`
+	ExpiresAt int64     `dynamodbav:"expires_at,omitempty" json:"-"` // TTL DynamoDB, unix-время
+}
+
+// Alert - оповещение о снижении цены
+type Alert struct {
+	UserID      string        `json:"user_id"`
+	Email       string        `json:"email,omitempty"`
+	Language    string        `json:"language,omitempty"`
+	Reason      string        `json:"reason"`
+	Product     types.Product `json:"product"` // С актуальной ценой и ссылкой
+	OldPrice    float64       `json:"old_price"`
+	NewPrice    float64       `json:"new_price"`
+	Currency    string        `json:"currency"`
+	TargetPrice float64       `json:"target_price,omitempty"`
+	DetectedAt  time.Time     `json:"detected_at"`
+}
+
+// DropPercent - на сколько процентов снизилась цена
+func (a Alert) DropPercent() float64 {
+	if a.OldPrice <= 0 {
+		return 0
+	}
+	return (a.OldPrice - a.NewPrice) / a.OldPrice * 100
+}
+
+// evaluate решает, нужно ли оповещение при цене price (в валюте товара).
+// С целевой ценой первое оповещение - когда цена до нее дошла; дальше, как
+// и без целевой цены, - только если цена упала на порог ниже предыдущего
+// оповещения.
+func (w *Watch) evaluate(price, dropThreshold float64) (string, float64, bool) {
+	if w.TargetPrice > 0 && w.NotifiedPrice == 0 {
+		if price <= w.TargetPrice && price < w.Product.Price {
+			return ReasonTarget, w.Product.Price, true
+		}
+		return "", 0, false
+	}
+
+	reference := w.Product.Price
+	if w.NotifiedPrice > 0 {
+		reference = w.NotifiedPrice
+	}
+	if price <= reference*(1-dropThreshold) {
+		return ReasonDrop, reference, true
+	}
+	return "", 0, false
+}
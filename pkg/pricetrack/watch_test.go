@@ -0,0 +1,45 @@
+package pricetrack
+
+import (
+	"testing"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+func TestWatchEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     float64
+		notified   float64
+		price      float64
+		wantReason string
+		wantOld    float64
+		wantAlert  bool
+	}{
+		{name: "small drop", price: 9600},
+		{name: "drop by threshold", price: 9500, wantReason: ReasonDrop, wantOld: 10000, wantAlert: true},
+		{name: "price rise", price: 11000},
+		{name: "target not reached", target: 8000, price: 8500},
+		{name: "target reached", target: 8000, price: 8000, wantReason: ReasonTarget, wantOld: 10000, wantAlert: true},
+		{name: "target above saved price", target: 12000, price: 10000},
+		{name: "small drop after alert", target: 8000, notified: 8000, price: 7800},
+		{name: "drop after alert", target: 8000, notified: 8000, price: 7600, wantReason: ReasonDrop, wantOld: 8000, wantAlert: true},
+		{name: "drop after drop alert", notified: 9000, price: 8550, wantReason: ReasonDrop, wantOld: 9000, wantAlert: true},
+		{name: "same price after alert", notified: 9000, price: 9000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watch := Watch{
+				Product:       types.Product{ID: "p1", Price: 10000, Currency: "KZT"},
+				TargetPrice:   tt.target,
+				NotifiedPrice: tt.notified,
+			}
+			reason, old, ok := watch.evaluate(tt.price, 0.05)
+			if reason != tt.wantReason || old != tt.wantOld || ok != tt.wantAlert {
+				t.Errorf("evaluate(%v) = %q, %v, %v; want %q, %v, %v",
+					tt.price, reason, old, ok, tt.wantReason, tt.wantOld, tt.wantAlert)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package queryplan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Провайдеры, для которых строятся текстовые запросы
+const (
+	ProviderSerper   = "serper"    // shopping-поиск ProductService
+	ProviderAISearch = "ai-search" // поиск AISearchService
+)
+
+// ErrUnknownProvider возвращается, если для провайдера нет профиля
+var ErrUnknownProvider = errors.New("unknown query provider")
+
+// PriceStyle - как провайдер понимает ценовой фильтр в тексте запроса
+type PriceStyle int
+
+const (
+	PriceNone     PriceStyle = iota
+	PriceOperator            // price:5000..20000 в валюте Profile.Currency, открытые границы: price:5000.. и price:..20000
+	PriceWords               // от 5000 до 20000 тенге
+)
+
+// Profile - правила построения запроса для одного провайдера
+type Profile struct {
+	Mapping  string     // Маркетплейс, чьи названия категорий используются по умолчанию
+	UseOR    bool       // Объединять альтернативы через OR, а не пробелом
+	Price    PriceStyle // Синтаксис ценового фильтра
+	Currency string     // Валюта цен выдачи: price: не принимает валюту, и границы переводятся в нее
+	Site     bool       // Ограничивать выдачу сайтом маркетплейса (site:)
+	Suffix   string     // Добавляется в конец запроса для релевантности
+}
+
+// DefaultProfiles - профили провайдеров. Google в Казахстане лучше понимает
+// русские названия категорий, поэтому по умолчанию берем названия Kaspi.
+var DefaultProfiles = map[string]Profile{
+	ProviderSerper:   {Mapping: "kaspi", UseOR: true, Price: PriceOperator, Currency: money.KZT, Site: true},
+	ProviderAISearch: {Mapping: "kaspi", Price: PriceWords, Site: true, Suffix: "купить в Казахстане"},
+}
+
+// Домены маркетплейсов для оператора site:
+var siteDomains = map[string]string{
+	"kaspi":       "kaspi.kz",
+	"wildberries": "wildberries.ru",
+	"ozon":        "ozon.ru",
+	"aliexpress":  "aliexpress.com",
+}
+
+// Названия валют для ценового фильтра словами
+var currencyWords = map[string]string{
+	money.KZT: "тенге",
+	money.RUB: "рублей",
+	money.USD: "долларов",
+	money.EUR: "евро",
+}
+
+// Plan - запрос для конкретного провайдера
+type Plan struct {
+	Provider string
+	Query    string
+	Terms    []string // Текст запроса, локализованные категории и интересы
+}
+
+// Catalog - источник названий категорий в каталогах маркетплейсов
+type Catalog interface {
+	CategoryName(marketplace, category string) (string, bool)
+	HasMarketplace(marketplace string) bool
+}
+
+type Planner struct {
+	profiles map[string]Profile
+	catalog  Catalog
+	rates    money.RateTable
+}
+
+// New создает планировщик с заданными профилями и названиями категорий;
+// цены переводятся по money.DefaultRates, пока не вызван SetRates
+func New(profiles map[string]Profile, catalog Catalog) *Planner {
+	return &Planner{profiles: profiles, catalog: catalog, rates: money.DefaultRates}
+}
+
+// SetRates задает курсы для перевода ценовых границ в валюту провайдера
+func (p *Planner) SetRates(rates money.RateTable) {
+	p.rates = rates
+}
+
+// NewDefault создает планировщик с профилями по умолчанию; названия
+// категорий берутся из текущей таксономии с учетом перезагрузки
+func NewDefault() *Planner {
+	return New(DefaultProfiles, taxonomy.Default())
+}
+
+// Plan строит запрос провайдера из намерения пользователя
+func (p *Planner) Plan(provider string, intent types.SearchIntent) (Plan, error) {
+	profile, ok := p.profiles[provider]
+	if !ok {
+		return Plan{}, fmt.Errorf("%w: %s", ErrUnknownProvider, provider)
+	}
+
+	plan := Plan{Provider: provider}
+	var parts []string
+
+	// Свободный текст идет первым, категории и интересы уточняют его
+	if query := strings.Join(strings.Fields(intent.Query), " "); query != "" {
+		parts = append(parts, query)
+		plan.Terms = append(plan.Terms, query)
+	}
+
+	categories := p.LocalizeCategories(p.mappingFor(profile, intent.Marketplace), intent.Categories)
+	if group := alternatives(categories, profile.UseOR, len(parts) > 0); group != "" {
+		parts = append(parts, group)
+		plan.Terms = append(plan.Terms, categories...)
+	}
+
+	interests := compact(intent.Interests)
+	if group := alternatives(interests, profile.UseOR, len(parts) > 0); group != "" {
+		parts = append(parts, group)
+		plan.Terms = append(plan.Terms, interests...)
+	}
+
+	if price := p.priceFilter(profile, intent.PriceRange); price != "" {
+		parts = append(parts, price)
+	}
+
+	if profile.Site && intent.Marketplace != "" {
+		parts = append(parts, "site:"+SiteDomain(intent.Marketplace))
+	}
+
+	if profile.Suffix != "" {
+		parts = append(parts, profile.Suffix)
+	}
+
+	plan.Query = strings.Join(parts, " ")
+	return plan, nil
+}
+
+// LocalizeCategories переводит ключи категорий в названия из набора mapping.
+// Категории без перевода остаются как есть, повторы отбрасываются.
+func (p *Planner) LocalizeCategories(mapping string, categories []string) []string {
+	localized := make([]string, 0, len(categories))
+	seen := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		category = strings.TrimSpace(category)
+		if category == "" {
+			continue
+		}
+		name := category
+		if translated, ok := p.catalog.CategoryName(mapping, strings.ToLower(category)); ok {
+			name = translated
+		}
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			localized = append(localized, name)
+		}
+	}
+	return localized
+}
+
+// mappingFor выбирает названия категорий: если задан маркетплейс с
+// собственным каталогом, используем его названия
+func (p *Planner) mappingFor(profile Profile, marketplace string) string {
+	if p.catalog.HasMarketplace(marketplace) {
+		return marketplace
+	}
+	return profile.Mapping
+}
+
+// SiteDomain возвращает домен маркетплейса для оператора site:
+func SiteDomain(marketplace string) string {
+	marketplace = strings.ToLower(strings.TrimSpace(marketplace))
+	if domain, ok := siteDomains[marketplace]; ok {
+		return domain
+	}
+	return marketplace
+}
+
+// alternatives объединяет варианты через OR или пробел. Фразы из нескольких
+// слов берутся в кавычки, чтобы OR относился ко всей фразе, а группа - в
+// скобки, если перед ней уже есть другие слова запроса.
+func alternatives(terms []string, useOR, nested bool) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	if !useOR {
+		return strings.Join(terms, " ")
+	}
+
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		if strings.ContainsAny(term, " \t") {
+			term = strconv.Quote(term)
+		}
+		quoted[i] = term
+	}
+	group := strings.Join(quoted, " OR ")
+	if nested && len(terms) > 1 {
+		group = "(" + group + ")"
+	}
+	return group
+}
+
+// priceFilter строит ценовой фильтр; любая из границ может отсутствовать
+func (p *Planner) priceFilter(profile Profile, priceRange types.Range) string {
+	hasMin, hasMax := priceRange.Min > 0, priceRange.Max > 0
+	if !hasMin && !hasMax {
+		return ""
+	}
+
+	switch profile.Price {
+	case PriceOperator:
+		converted, ok := p.convertRange(priceRange, profile.Currency)
+		if !ok {
+			// Без курса фильтр в запросе отфильтровал бы не те цены; цены
+			// выдачи все равно проверяются после поиска
+			return ""
+		}
+		priceRange = converted
+		bound := func(v float64, ok bool) string {
+			if !ok {
+				return ""
+			}
+			return formatPrice(v)
+		}
+		return "price:" + bound(priceRange.Min, hasMin) + ".." + bound(priceRange.Max, hasMax)
+	case PriceWords:
+		currency := strings.ToUpper(priceRange.Currency)
+		if currency == "" {
+			currency = money.KZT
+		}
+		word, ok := currencyWords[currency]
+		if !ok {
+			word = currency
+		}
+
+		var words []string
+		if hasMin {
+			words = append(words, "от "+formatPrice(priceRange.Min))
+		}
+		if hasMax {
+			words = append(words, "до "+formatPrice(priceRange.Max))
+		}
+		return strings.Join(words, " ") + " " + word
+	}
+	return ""
+}
+
+// convertRange переводит границы в currency. Границы округляются наружу,
+// чтобы перевод не сузил диапазон.
+func (p *Planner) convertRange(priceRange types.Range, currency string) (types.Range, bool) {
+	from := strings.ToUpper(priceRange.Currency)
+	if from == "" {
+		from = money.KZT
+	}
+	if currency == "" || currency == from {
+		return priceRange, true
+	}
+
+	converted := types.Range{Currency: currency}
+	if priceRange.Min > 0 {
+		v, err := p.rates.Convert(priceRange.Min, from, currency)
+		if err != nil {
+			return types.Range{}, false
+		}
+		converted.Min = math.Floor(v)
+	}
+	if priceRange.Max > 0 {
+		v, err := p.rates.Convert(priceRange.Max, from, currency)
+		if err != nil {
+			return types.Range{}, false
+		}
+		converted.Max = math.Ceil(v)
+	}
+	return converted, true
+}
+
+func formatPrice(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// compact убирает пустые значения и повторы без учета регистра
+func compact(terms []string) []string {
+	result := make([]string, 0, len(terms))
+	seen := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		term = strings.Join(strings.Fields(term), " ")
+		key := strings.ToLower(term)
+		if term == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, term)
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package queryplan
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// stubCatalog - названия категорий по маркетплейсам без таксономии
+type stubCatalog map[string]map[string]string
+
+func (c stubCatalog) CategoryName(marketplace, category string) (string, bool) {
+	name, ok := c[marketplace][category]
+	return name, ok
+}
+
+func (c stubCatalog) HasMarketplace(marketplace string) bool {
+	_, ok := c[marketplace]
+	return ok
+}
+
+var testCatalog = stubCatalog{
+	"kaspi":      {"electronics": "Электроника", "home": "Товары для дома"},
+	"aliexpress": {"electronics": "Electronics"},
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		intent   types.SearchIntent
+		want     string
+	}{
+		{
+			name:     "closed range",
+			provider: ProviderSerper,
+			intent:   types.SearchIntent{Categories: []string{"electronics"}, PriceRange: types.Range{Min: 5000, Max: 20000}},
+			want:     "Электроника price:5000..20000",
+		},
+		{
+			name:     "open upper bound",
+			provider: ProviderSerper,
+			intent:   types.SearchIntent{Categories: []string{"electronics"}, PriceRange: types.Range{Min: 5000}},
+			want:     "Электроника price:5000..",
+		},
+		{
+			name:     "open lower bound",
+			provider: ProviderSerper,
+			intent:   types.SearchIntent{Categories: []string{"electronics"}, PriceRange: types.Range{Max: 20000}},
+			want:     "Электроника price:..20000",
+		},
+		{
+			name:     "operator converts to provider currency",
+			provider: ProviderSerper,
+			intent:   types.SearchIntent{Categories: []string{"electronics"}, PriceRange: types.Range{Min: 10.5, Max: 50, Currency: "usd"}},
+			want:     "Электроника price:5040..24000",
+		},
+		{
+			name:     "operator rounds converted bounds outward",
+			provider: ProviderSerper,
+			intent:   types.SearchIntent{Categories: []string{"electronics"}, PriceRange: types.Range{Min: 1001, Max: 1001, Currency: money.RUB}},
+			want:     "Электроника price:5605..5606",
+		},
+		{
+			name:     "operator omitted for unknown currency",
+			provider: ProviderSerper,
+			intent:   types.SearchIntent{Categories: []string{"electronics"}, PriceRange: types.Range{Max: 100, Currency: "GBP"}},
+			want:     "Электроника",
+		},
+		{
+			name:     "words keep request currency",
+			provider: ProviderAISearch,
+			intent:   types.SearchIntent{Categories: []string{"electronics"}, PriceRange: types.Range{Max: 50, Currency: money.USD}},
+			want:     "Электроника до 50 долларов купить в Казахстане",
+		},
+		{
+			name:     "words open upper bound default currency",
+			provider: ProviderAISearch,
+			intent:   types.SearchIntent{Categories: []string{"home"}, PriceRange: types.Range{Min: 5000}},
+			want:     "Товары для дома от 5000 тенге купить в Казахстане",
+		},
+		{
+			name:     "OR groups quoted and nested",
+			provider: ProviderSerper,
+			intent:   types.SearchIntent{Query: "подарок  маме", Categories: []string{"electronics", "home"}, Interests: []string{"чай", "Чай", "йога"}},
+			want:     `подарок маме (Электроника OR "Товары для дома") (чай OR йога)`,
+		},
+		{
+			name:     "marketplace catalog and site",
+			provider: ProviderSerper,
+			intent:   types.SearchIntent{Categories: []string{"electronics", "toys"}, Marketplace: "aliexpress"},
+			want:     "Electronics OR toys site:aliexpress.com",
+		},
+		{
+			name:     "words join without OR",
+			provider: ProviderAISearch,
+			intent:   types.SearchIntent{Categories: []string{"electronics"}, Interests: []string{"игры"}, Marketplace: "ozon"},
+			want:     "Электроника игры site:ozon.ru купить в Казахстане",
+		},
+	}
+
+	planner := New(DefaultProfiles, testCatalog)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planner.Plan(tt.provider, tt.intent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Query != tt.want {
+				t.Errorf("Query = %q, want %q", plan.Query, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanUnknownProvider(t *testing.T) {
+	_, err := New(DefaultProfiles, testCatalog).Plan("bing", types.SearchIntent{Query: "подарок"})
+	if !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("err = %v, want ErrUnknownProvider", err)
+	}
+}
+
+func TestPlanSetRates(t *testing.T) {
+	planner := New(DefaultProfiles, testCatalog)
+	planner.SetRates(money.RateTable{Base: money.KZT, Rates: map[string]float64{money.KZT: 1, money.USD: 500}})
+
+	plan, err := planner.Plan(ProviderSerper, types.SearchIntent{Query: "часы", PriceRange: types.Range{Max: 100, Currency: money.USD}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "часы price:..50000"; plan.Query != want {
+		t.Errorf("Query = %q, want %q", plan.Query, want)
+	}
+}
@@ -0,0 +1,215 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrExhausted возвращается, когда дневной или месячный лимит исчерпан
+var ErrExhausted = errors.New("quota exhausted")
+
+// Limits - жесткие лимиты запросов; 0 означает отсутствие лимита
+type Limits struct {
+	Daily   int64
+	Monthly int64
+	// SoftRatio - доля лимита, после которой пишется предупреждение
+	SoftRatio float64
+}
+
+// Usage - использование квоты в текущих сутках и месяце (UTC)
+type Usage struct {
+	Daily   int64
+	Monthly int64
+	Limits  Limits
+}
+
+// DailyRemaining возвращает остаток на сутки или -1, если лимита нет
+func (u Usage) DailyRemaining() int64 {
+	return remaining(u.Daily, u.Limits.Daily)
+}
+
+// MonthlyRemaining возвращает остаток на месяц или -1, если лимита нет
+func (u Usage) MonthlyRemaining() int64 {
+	return remaining(u.Monthly, u.Limits.Monthly)
+}
+
+// SoftLimitReached сообщает, что использование перешло порог предупреждения
+func (u Usage) SoftLimitReached() bool {
+	if u.Limits.SoftRatio <= 0 {
+		return false
+	}
+	return over(u.Daily, u.Limits.Daily, u.Limits.SoftRatio) || over(u.Monthly, u.Limits.Monthly, u.Limits.SoftRatio)
+}
+
+func remaining(used, limit int64) int64 {
+	if limit <= 0 {
+		return -1
+	}
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+func over(used, limit int64, ratio float64) bool {
+	return limit > 0 && float64(used) >= float64(limit)*ratio
+}
+
+// Tracker считает запросы к платному API в таблице DynamoDB. Счетчики
+// хранятся отдельными записями на сутки и на месяц и удаляются по TTL.
+type Tracker struct {
+	client    *dynamodb.Client
+	tableName string
+	scope     string
+	limits    Limits
+	now       func() time.Time
+}
+
+// NewTracker создает счетчик для scope (например, "serper#<хеш ключа>")
+func NewTracker(client *dynamodb.Client, tableName, scope string, limits Limits) *Tracker {
+	return &Tracker{
+		client:    client,
+		tableName: tableName,
+		scope:     scope,
+		limits:    limits,
+		now:       time.Now,
+	}
+}
+
+// Consume атомарно учитывает один запрос. Если любой из лимитов исчерпан,
+// счетчики не меняются и возвращается ErrExhausted.
+func (t *Tracker) Consume(ctx context.Context) (Usage, error) {
+	now := t.now().UTC()
+	dayKey, monthKey := t.keys(now)
+
+	_, err := t.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []dyntypes.TransactWriteItem{
+			{Update: t.increment(dayKey, t.limits.Daily, now.AddDate(0, 0, 2))},
+			{Update: t.increment(monthKey, t.limits.Monthly, now.AddDate(0, 2, 0))},
+		},
+	})
+	if err != nil {
+		var canceled *dyntypes.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			for _, reason := range canceled.CancellationReasons {
+				if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+					usage, _ := t.Usage(ctx)
+					return usage, ErrExhausted
+				}
+			}
+		}
+		return Usage{}, fmt.Errorf("failed to consume quota: %w", err)
+	}
+
+	return t.Usage(ctx)
+}
+
+// Usage читает текущие счетчики без их изменения
+func (t *Tracker) Usage(ctx context.Context) (Usage, error) {
+	dayKey, monthKey := t.keys(t.now().UTC())
+	usage := Usage{Limits: t.limits}
+
+	daily, err := t.read(ctx, dayKey)
+	if err != nil {
+		return usage, err
+	}
+	monthly, err := t.read(ctx, monthKey)
+	if err != nil {
+		return usage, err
+	}
+
+	usage.Daily = daily
+	usage.Monthly = monthly
+	return usage, nil
+}
+
+func (t *Tracker) keys(now time.Time) (string, string) {
+	return t.scope + "#day#" + now.Format("2006-01-02"), t.scope + "#month#" + now.Format("2006-01")
+}
+
+func (t *Tracker) increment(key string, limit int64, expiresAt time.Time) *dyntypes.Update {
+	update := &dyntypes.Update{
+		TableName: aws.String(t.tableName),
+		Key: map[string]dyntypes.AttributeValue{
+			"quota_key": &dyntypes.AttributeValueMemberS{Value: key},
+		},
+		UpdateExpression: aws.String("ADD used :one SET expires_at = :expires_at"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":one":        &dyntypes.AttributeValueMemberN{Value: "1"},
+			":expires_at": &dyntypes.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+		},
+	}
+
+	if limit > 0 {
+		update.ConditionExpression = aws.String("attribute_not_exists(used) OR used < :limit")
+		update.ExpressionAttributeValues[":limit"] = &dyntypes.AttributeValueMemberN{Value: strconv.FormatInt(limit, 10)}
+	}
+
+	return update
+}
+
+func (t *Tracker) read(ctx context.Context, key string) (int64, error) {
+	result, err := t.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.tableName),
+		Key: map[string]dyntypes.AttributeValue{
+			"quota_key": &dyntypes.AttributeValueMemberS{Value: key},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read quota: %w", err)
+	}
+
+	used, ok := result.Item["used"].(*dyntypes.AttributeValueMemberN)
+	if !ok {
+		return 0, nil
+	}
+	return strconv.ParseInt(used.Value, 10, 64)
+}
+
+// LimitsFromEnv читает <PREFIX>_DAILY_LIMIT, <PREFIX>_MONTHLY_LIMIT
+// и <PREFIX>_SOFT_LIMIT_RATIO (по умолчанию 0.8)
+func LimitsFromEnv(prefix string) Limits {
+	limits := Limits{SoftRatio: 0.8}
+
+	if v := os.Getenv(prefix + "_DAILY_LIMIT"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			limits.Daily = n
+		} else {
+			log.Printf("Invalid %s_DAILY_LIMIT: %q", prefix, v)
+		}
+	}
+	if v := os.Getenv(prefix + "_MONTHLY_LIMIT"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			limits.Monthly = n
+		} else {
+			log.Printf("Invalid %s_MONTHLY_LIMIT: %q", prefix, v)
+		}
+	}
+	if v := os.Getenv(prefix + "_SOFT_LIMIT_RATIO"); v != "" {
+		if r, err := strconv.ParseFloat(v, 64); err == nil && r > 0 && r <= 1 {
+			limits.SoftRatio = r
+		} else {
+			log.Printf("Invalid %s_SOFT_LIMIT_RATIO: %q", prefix, v)
+		}
+	}
+
+	return limits
+}
+
+// TableName возвращает таблицу квот из QUOTA_TABLE
+func TableName() string {
+	if v := os.Getenv("QUOTA_TABLE"); v != "" {
+		return v
+	}
+	return "api_quota" // значение по умолчанию
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket - классический токен-бакет: пополняется со скоростью rate
+// токенов в секунду и вмещает не больше burst токенов
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// Allow забирает токен, если он есть, не блокируя вызывающего
+func (b *TokenBucket) Allow() bool {
+	ok, _ := b.reserve()
+	return ok
+}
+
+// Wait ждет свободного токена или отмены контекста
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		ok, wait := b.reserve()
+		if ok {
+			return nil
+		}
+
+		// Не ждем, если токен все равно не появится до дедлайна
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return context.DeadlineExceeded
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve забирает токен или возвращает время до появления следующего
+func (b *TokenBucket) reserve() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	if b.rate <= 0 {
+		return false, time.Hour
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+var (
+	bucketsMu sync.Mutex
+	buckets   = make(map[string]*TokenBucket)
+)
+
+// ForKey возвращает общий для процесса бакет для ключа (например, API ключа),
+// чтобы все сервисы, использующие один ключ, делили один лимит
+func ForKey(key string, rate float64, burst int) *TokenBucket {
+	bucketsMu.Lock()
+	defer bucketsMu.Unlock()
+
+	bucket, ok := buckets[key]
+	if !ok {
+		bucket = NewTokenBucket(rate, burst)
+		buckets[key] = bucket
+	}
+	return bucket
+}
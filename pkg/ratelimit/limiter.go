@@ -0,0 +1,264 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Limit - лимит токен-бакета: Rate токенов в секунду, не больше Burst подряд
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Decision - решение по одному запросу
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration // Когда появится следующий токен, если запрос отклонен
+}
+
+// Limiter делит запросы по ключам (например, клиент и эндпоинт) и
+// отклоняет те, что превышают лимит
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Decision, error)
+}
+
+// NewLimiterFromEnv выбирает хранилище бакетов по RATE_LIMIT_BACKEND:
+// dynamodb (по умолчанию, таблица RATE_LIMIT_TABLE) - общий лимит для всех
+// экземпляров Lambda, memory - лимит в пределах процесса для локального запуска
+func NewLimiterFromEnv(dynamoClient *dynamodb.Client) (Limiter, error) {
+	switch backend := os.Getenv("RATE_LIMIT_BACKEND"); backend {
+	case "", "dynamodb":
+		table := os.Getenv("RATE_LIMIT_TABLE")
+		if table == "" {
+			table = "rate_limits"
+		}
+		return NewDynamoLimiter(dynamoClient, table), nil
+	case "memory":
+		return NewMemoryLimiter(), nil
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_BACKEND: %s", backend)
+	}
+}
+
+// MemoryLimiter держит бакеты в памяти процесса
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*TokenBucket
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*TokenBucket)}
+}
+
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (Decision, error) {
+	l.mu.Lock()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = NewTokenBucket(limit.Rate, limit.Burst)
+		l.buckets[key] = bucket
+	}
+	l.mu.Unlock()
+
+	allowed, wait := bucket.reserve()
+	return Decision{Allowed: allowed, RetryAfter: wait}, nil
+}
+
+// Сколько раз перечитывать бакет, если его параллельно изменил другой запрос
+const dynamoRetries = 3
+
+// DynamoLimiter хранит бакеты в таблице с ключом bucket_key: остаток токенов
+// и время последнего изменения. Списание - условное обновление по времени
+// изменения, поэтому параллельные Lambda не тратят один токен дважды.
+type DynamoLimiter struct {
+	client    *dynamodb.Client
+	tableName string
+	now       func() time.Time
+}
+
+func NewDynamoLimiter(client *dynamodb.Client, tableName string) *DynamoLimiter {
+	return &DynamoLimiter{
+		client:    client,
+		tableName: tableName,
+		now:       time.Now,
+	}
+}
+
+func (l *DynamoLimiter) Allow(ctx context.Context, key string, limit Limit) (Decision, error) {
+	for attempt := 0; attempt < dynamoRetries; attempt++ {
+		tokens, updatedAt, err := l.read(ctx, key, limit)
+		if err != nil {
+			return Decision{}, err
+		}
+
+		// Время изменения всегда растет - по нему проверяется, что бакет не
+		// изменился с момента чтения
+		now := l.now().UnixMilli()
+		if now <= updatedAt {
+			now = updatedAt + 1
+		}
+		if updatedAt > 0 {
+			tokens = math.Min(float64(limit.Burst), tokens+float64(now-updatedAt)/1000*limit.Rate)
+		}
+		if tokens < 1 {
+			return Decision{RetryAfter: refillTime(tokens, limit.Rate)}, nil
+		}
+
+		err = l.write(ctx, key, limit, tokens-1, now, updatedAt)
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			continue
+		}
+		if err != nil {
+			return Decision{}, err
+		}
+		return Decision{Allowed: true}, nil
+	}
+
+	// Бакет постоянно меняют параллельные запросы того же клиента
+	return Decision{RetryAfter: refillTime(0, limit.Rate)}, nil
+}
+
+// read возвращает остаток и время изменения (мс); у нового бакета полный запас
+func (l *DynamoLimiter) read(ctx context.Context, key string, limit Limit) (float64, int64, error) {
+	result, err := l.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(l.tableName),
+		Key:            bucketKey(key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read rate limit bucket: %w", err)
+	}
+
+	tokensAttr, ok1 := result.Item["tokens"].(*dyntypes.AttributeValueMemberN)
+	updatedAttr, ok2 := result.Item["updated_at"].(*dyntypes.AttributeValueMemberN)
+	if !ok1 || !ok2 {
+		return float64(limit.Burst), 0, nil
+	}
+	tokens, err := strconv.ParseFloat(tokensAttr.Value, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid tokens in rate limit bucket %s: %w", key, err)
+	}
+	updatedAt, err := strconv.ParseInt(updatedAttr.Value, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid updated_at in rate limit bucket %s: %w", key, err)
+	}
+	return tokens, updatedAt, nil
+}
+
+func (l *DynamoLimiter) write(ctx context.Context, key string, limit Limit, tokens float64, now, previous int64) error {
+	// Бакет удаляется по TTL, когда успел бы полностью наполниться
+	expiresAt := time.UnixMilli(now).Add(refillTime(0, limit.Rate)*time.Duration(limit.Burst) + time.Minute)
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:        aws.String(l.tableName),
+		Key:              bucketKey(key),
+		UpdateExpression: aws.String("SET tokens = :tokens, updated_at = :now, expires_at = :expires_at"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":tokens":     &dyntypes.AttributeValueMemberN{Value: strconv.FormatFloat(tokens, 'f', 4, 64)},
+			":now":        &dyntypes.AttributeValueMemberN{Value: strconv.FormatInt(now, 10)},
+			":expires_at": &dyntypes.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+		},
+	}
+	if previous == 0 {
+		input.ConditionExpression = aws.String("attribute_not_exists(updated_at)")
+	} else {
+		input.ConditionExpression = aws.String("updated_at = :previous")
+		input.ExpressionAttributeValues[":previous"] = &dyntypes.AttributeValueMemberN{Value: strconv.FormatInt(previous, 10)}
+	}
+
+	if _, err := l.client.UpdateItem(ctx, input); err != nil {
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return err
+		}
+		return fmt.Errorf("failed to update rate limit bucket: %w", err)
+	}
+	return nil
+}
+
+func bucketKey(key string) map[string]dyntypes.AttributeValue {
+	return map[string]dyntypes.AttributeValue{
+		"bucket_key": &dyntypes.AttributeValueMemberS{Value: key},
+	}
+}
+
+// refillTime - через сколько в бакете появится целый токен
+func refillTime(tokens, rate float64) time.Duration {
+	if rate <= 0 {
+		return time.Hour
+	}
+	return time.Duration((1 - tokens) / rate * float64(time.Second))
+}
+
+// Лимиты по умолчанию: распознавание изображений и синтез речи - самые
+// дорогие вызовы AWS
+var defaultLimits = map[string]string{
+	"analyze-image":  "10/1m",
+	"text-to-speech": "20/1m",
+}
+
+const defaultLimit = "120/1m"
+
+// LoadLimit возвращает лимит эндпоинта. RATE_LIMITS переопределяет лимиты
+// по эндпоинтам ("analyze-image=10/1m,text-to-speech=off"), остальные
+// берут RATE_LIMIT_DEFAULT (120/1m). false - лимит выключен.
+func LoadLimit(endpoint string) (Limit, bool, error) {
+	value := defaultLimit
+	if v := os.Getenv("RATE_LIMIT_DEFAULT"); v != "" {
+		value = v
+	}
+	if v, ok := defaultLimits[endpoint]; ok {
+		value = v
+	}
+
+	if v := os.Getenv("RATE_LIMITS"); v != "" {
+		for _, entry := range strings.Split(v, ",") {
+			name, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if !ok {
+				return Limit{}, false, fmt.Errorf("invalid RATE_LIMITS entry: %q", entry)
+			}
+			if strings.TrimSpace(name) == endpoint {
+				value = strings.TrimSpace(spec)
+			}
+		}
+	}
+
+	if value == "off" {
+		return Limit{}, false, nil
+	}
+	limit, err := ParseLimit(value)
+	if err != nil {
+		return Limit{}, false, fmt.Errorf("invalid rate limit for %s: %w", endpoint, err)
+	}
+	return limit, true, nil
+}
+
+// ParseLimit разбирает "<запросов>/<период>", например "10/1m": бакет на 10
+// запросов, который наполняется за минуту
+func ParseLimit(value string) (Limit, error) {
+	countText, periodText, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("expected <count>/<period>, got %q", value)
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid request count %q", countText)
+	}
+	period, err := time.ParseDuration(periodText)
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("invalid period %q", periodText)
+	}
+	return Limit{Rate: float64(count) / period.Seconds(), Burst: count}, nil
+}
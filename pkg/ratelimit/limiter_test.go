@@ -0,0 +1,264 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// fakeDynamo - таблица бакетов за HTTP API DynamoDB: GetItem и UpdateItem с
+// условиями, которые использует DynamoLimiter
+type fakeDynamo struct {
+	mu      sync.Mutex
+	items   map[string]map[string]map[string]string // bucket_key -> атрибут -> {"N": ...}
+	updates int
+	// beforeUpdate вызывается перед каждым UpdateItem - так тест изображает
+	// параллельный запрос, изменивший бакет после чтения
+	beforeUpdate func(item map[string]map[string]string)
+}
+
+type fakeRequest struct {
+	Key                       map[string]map[string]string
+	ConditionExpression       string
+	ExpressionAttributeValues map[string]map[string]string
+}
+
+func newFakeDynamo(t *testing.T) (*fakeDynamo, *dynamodb.Client) {
+	t.Helper()
+	fake := &fakeDynamo{items: make(map[string]map[string]map[string]string)}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+
+	client := dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(server.URL),
+		Credentials:      aws.AnonymousCredentials{},
+		RetryMaxAttempts: 1,
+	})
+	return fake, client
+}
+
+func (f *fakeDynamo) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var request fakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key := request.Key["bucket_key"]["S"]
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+
+	switch strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.") {
+	case "GetItem":
+		json.NewEncoder(w).Encode(map[string]interface{}{"Item": f.items[key]})
+	case "UpdateItem":
+		f.updates++
+		item := f.items[key]
+		if f.beforeUpdate != nil && item != nil {
+			f.beforeUpdate(item)
+		}
+		values := request.ExpressionAttributeValues
+		var ok bool
+		switch request.ConditionExpression {
+		case "attribute_not_exists(updated_at)":
+			ok = item == nil
+		case "updated_at = :previous":
+			ok = item != nil && item["updated_at"]["N"] == values[":previous"]["N"]
+		}
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"__type":  "com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException",
+				"message": "The conditional request failed",
+			})
+			return
+		}
+		f.items[key] = map[string]map[string]string{
+			"tokens":     values[":tokens"],
+			"updated_at": values[":now"],
+			"expires_at": values[":expires_at"],
+		}
+		w.Write([]byte("{}"))
+	default:
+		http.Error(w, "unsupported operation", http.StatusBadRequest)
+	}
+}
+
+func TestDynamoLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+	_, client := newFakeDynamo(t)
+	limiter := NewDynamoLimiter(client, "rate_limits")
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	limiter.now = func() time.Time { return now }
+
+	// 10 запросов в минуту: токен каждые 6 секунд
+	limit := Limit{Rate: 10.0 / 60, Burst: 10}
+	for i := 0; i < 10; i++ {
+		decision, err := limiter.Allow(ctx, "search#ip:1.2.3.4", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !decision.Allowed {
+			t.Fatalf("request %d rejected, burst is 10", i+1)
+		}
+	}
+
+	steps := []struct {
+		at          time.Duration
+		wantAllowed bool
+		retryAfter  time.Duration
+	}{
+		{at: 0, wantAllowed: false, retryAfter: 6 * time.Second},
+		{at: 3 * time.Second, wantAllowed: false, retryAfter: 3 * time.Second},
+		{at: 6 * time.Second, wantAllowed: true},
+		{at: 6 * time.Second, wantAllowed: false, retryAfter: 6 * time.Second},
+		// За две минуты бакет наполняется только до burst
+		{at: 3 * time.Minute, wantAllowed: true},
+	}
+	for _, step := range steps {
+		now = start.Add(step.at)
+		decision, err := limiter.Allow(ctx, "search#ip:1.2.3.4", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decision.Allowed != step.wantAllowed {
+			t.Fatalf("at %s: allowed = %v, want %v", step.at, decision.Allowed, step.wantAllowed)
+		}
+		// Одновременные запросы сдвигают время бакета на 1 мс
+		if diff := decision.RetryAfter - step.retryAfter; math.Abs(float64(diff)) > float64(20*time.Millisecond) {
+			t.Errorf("at %s: RetryAfter = %s, want %s", step.at, decision.RetryAfter, step.retryAfter)
+		}
+	}
+
+	// Другой ключ - свой бакет
+	if decision, err := limiter.Allow(ctx, "search#ip:5.6.7.8", limit); err != nil || !decision.Allowed {
+		t.Errorf("other client: %+v, %v", decision, err)
+	}
+}
+
+func TestDynamoLimiterRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	fake, client := newFakeDynamo(t)
+	limiter := NewDynamoLimiter(client, "rate_limits")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 5}
+
+	if _, err := limiter.Allow(ctx, "key", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	// Параллельный запрос один раз успевает изменить бакет после чтения
+	conflicts := 1
+	fake.beforeUpdate = func(item map[string]map[string]string) {
+		if conflicts > 0 {
+			conflicts--
+			item["updated_at"] = map[string]string{"N": "1"}
+		}
+	}
+	fake.updates = 0
+	decision, err := limiter.Allow(ctx, "key", limit)
+	if err != nil || !decision.Allowed {
+		t.Fatalf("Allow() = %+v, %v; want allowed after reread", decision, err)
+	}
+	if fake.updates != 2 {
+		t.Errorf("updates = %d, want conflict and retry", fake.updates)
+	}
+
+	// Бакет меняется при каждой попытке - запрос отклоняется до следующего токена
+	writes := 0
+	fake.beforeUpdate = func(item map[string]map[string]string) {
+		writes++
+		item["updated_at"] = map[string]string{"N": strconv.Itoa(writes)}
+	}
+	fake.updates = 0
+	decision, err = limiter.Allow(ctx, "key", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Allowed || decision.RetryAfter != time.Second {
+		t.Errorf("Allow() = %+v, want rejected with RetryAfter 1s", decision)
+	}
+	if fake.updates != dynamoRetries {
+		t.Errorf("updates = %d, want %d", fake.updates, dynamoRetries)
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Limit
+		wantErr bool
+	}{
+		{value: "10/1m", want: Limit{Rate: 10.0 / 60, Burst: 10}},
+		{value: "120/1m", want: Limit{Rate: 2, Burst: 120}},
+		{value: "5/1s", want: Limit{Rate: 5, Burst: 5}},
+		{value: "1000/24h", want: Limit{Rate: 1000.0 / 86400, Burst: 1000}},
+		{value: "10", wantErr: true},
+		{value: "0/1m", wantErr: true},
+		{value: "-1/1m", wantErr: true},
+		{value: "ten/1m", wantErr: true},
+		{value: "10/0s", wantErr: true},
+		{value: "10/minute", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseLimit(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		endpoint    string
+		defaultSpec string
+		overrides   string
+		want        Limit
+		wantEnabled bool
+		wantErr     bool
+	}{
+		{name: "built-in default", endpoint: "search-products", want: Limit{Rate: 2, Burst: 120}, wantEnabled: true},
+		{name: "RATE_LIMIT_DEFAULT", endpoint: "search-products", defaultSpec: "60/1m", want: Limit{Rate: 1, Burst: 60}, wantEnabled: true},
+		{name: "per-endpoint default beats RATE_LIMIT_DEFAULT", endpoint: "analyze-image", defaultSpec: "60/1m", want: Limit{Rate: 10.0 / 60, Burst: 10}, wantEnabled: true},
+		{name: "RATE_LIMITS beats per-endpoint default", endpoint: "analyze-image", defaultSpec: "60/1m", overrides: "analyze-image=2/1s", want: Limit{Rate: 2, Burst: 2}, wantEnabled: true},
+		{name: "RATE_LIMITS for other endpoint", endpoint: "translate", overrides: "analyze-image=2/1s, text-to-speech=off", want: Limit{Rate: 2, Burst: 120}, wantEnabled: true},
+		{name: "off in RATE_LIMITS", endpoint: "text-to-speech", overrides: "analyze-image=2/1s, text-to-speech=off"},
+		{name: "off as default", endpoint: "translate", defaultSpec: "off"},
+		{name: "invalid entry", endpoint: "translate", overrides: "translate", wantErr: true},
+		{name: "invalid limit", endpoint: "translate", overrides: "translate=fast", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RATE_LIMIT_DEFAULT", tt.defaultSpec)
+			t.Setenv("RATE_LIMITS", tt.overrides)
+
+			got, enabled, err := LoadLimit(tt.endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if enabled != tt.wantEnabled || got != tt.want {
+				t.Errorf("LoadLimit() = %+v, %v; want %+v, %v", got, enabled, tt.want, tt.wantEnabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package recipient
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Форматы дат: полная дата или ежегодная без года
+const (
+	dateLayout       = "2006-01-02"
+	annualDateLayout = "01-02"
+)
+
+var (
+	ErrNotFound = errors.New("recipient not found")
+	// ErrConflict - получателя успели изменить параллельным запросом
+	ErrConflict = errors.New("recipient was modified concurrently")
+	// ErrInvalid - профиль получателя не прошел проверку
+	ErrInvalid = errors.New("invalid recipient")
+)
+
+// Recipient - человек из адресной книги пользователя
+type Recipient struct {
+	UserID    string          `dynamodbav:"user_id" json:"-"`
+	ID        string          `dynamodbav:"recipient_id" json:"id"`
+	Name      string          `dynamodbav:"name" json:"name"`
+	Relation  string          `dynamodbav:"relation,omitempty" json:"relation,omitempty"`     // мама, друг, коллега...
+	Gender    string          `dynamodbav:"gender,omitempty" json:"gender,omitempty"`         // Ключ пола из таксономии
+	BirthDate string          `dynamodbav:"birth_date,omitempty" json:"birth_date,omitempty"` // YYYY-MM-DD
+	Age       int             `dynamodbav:"age,omitempty" json:"age,omitempty"`               // Если дата рождения неизвестна
+	Interests []string        `dynamodbav:"interests,omitempty" json:"interests,omitempty"`
+	Dates     []ImportantDate `dynamodbav:"dates,omitempty" json:"dates,omitempty"`
+	PastGifts []PastGift      `dynamodbav:"past_gifts,omitempty" json:"past_gifts,omitempty"`
+	Version   int             `dynamodbav:"version" json:"version"` // Для оптимистичной блокировки
+	CreatedAt time.Time       `dynamodbav:"created_at" json:"created_at"`
+	UpdatedAt time.Time       `dynamodbav:"updated_at" json:"updated_at"`
+}
+
+// ImportantDate - повод, к которому нужен подарок
+type ImportantDate struct {
+	Occasion string `dynamodbav:"occasion" json:"occasion"` // Ключ повода из таксономии
+	Date     string `dynamodbav:"date" json:"date"`         // YYYY-MM-DD или MM-DD; дата повторяется ежегодно
+	Note     string `dynamodbav:"note,omitempty" json:"note,omitempty"`
+}
+
+// PastGift - уже подаренный товар; такие товары не рекомендуются повторно
+type PastGift struct {
+	ProductID string    `dynamodbav:"product_id" json:"product_id"`
+	Title     string    `dynamodbav:"title,omitempty" json:"title,omitempty"`
+	Store     string    `dynamodbav:"store,omitempty" json:"store,omitempty"`
+	URL       string    `dynamodbav:"url,omitempty" json:"url,omitempty"`
+	Occasion  string    `dynamodbav:"occasion,omitempty" json:"occasion,omitempty"`
+	GivenAt   time.Time `dynamodbav:"given_at" json:"given_at"`
+}
+
+// Normalize приводит пол к ключу таксономии и проверяет профиль
+func (r *Recipient) Normalize() error {
+	t := taxonomy.Current()
+	var problems []string
+
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		problems = append(problems, "name is required")
+	}
+
+	if r.Gender != "" {
+		gender, ok := t.ResolveGender(r.Gender)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown gender %q", r.Gender))
+		}
+		r.Gender = gender
+	}
+
+	if r.BirthDate != "" {
+		birth, err := time.Parse(dateLayout, r.BirthDate)
+		if err != nil || birth.After(time.Now()) {
+			problems = append(problems, "birth_date must be a past date in YYYY-MM-DD format")
+		}
+	}
+	if r.Age < 0 || r.Age > 120 {
+		problems = append(problems, "age must be between 0 and 120")
+	}
+
+	for i, date := range r.Dates {
+		if _, ok := t.Occasions[date.Occasion]; !ok {
+			problems = append(problems, fmt.Sprintf("dates[%d]: unknown occasion %q", i, date.Occasion))
+		}
+		if _, err := parseDate(date.Date); err != nil {
+			problems = append(problems, fmt.Sprintf("dates[%d]: date must be YYYY-MM-DD or MM-DD", i))
+		}
+	}
+
+	for i, gift := range r.PastGifts {
+		if gift.ProductID == "" && gift.URL == "" {
+			problems = append(problems, fmt.Sprintf("past_gifts[%d]: product_id or url is required", i))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// AgeAt возвращает возраст на дату now: по дате рождения, если она есть
+func (r *Recipient) AgeAt(now time.Time) int {
+	birth, err := time.Parse(dateLayout, r.BirthDate)
+	if r.BirthDate == "" || err != nil {
+		return r.Age
+	}
+	age := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		age--
+	}
+	return max(age, 0)
+}
+
+// NextDate возвращает ближайшую важную дату не раньше now
+func (r *Recipient) NextDate(now time.Time) (ImportantDate, time.Time, bool) {
+	type upcoming struct {
+		date ImportantDate
+		at   time.Time
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var dates []upcoming
+	for _, date := range r.Dates {
+		day, err := parseDate(date.Date)
+		if err != nil {
+			continue
+		}
+		// Даты ежегодные: год из YYYY-MM-DD не учитывается
+		at := time.Date(today.Year(), day.Month(), day.Day(), 0, 0, 0, 0, now.Location())
+		if at.Before(today) {
+			at = at.AddDate(1, 0, 0)
+		}
+		dates = append(dates, upcoming{date: date, at: at})
+	}
+	if len(dates) == 0 {
+		return ImportantDate{}, time.Time{}, false
+	}
+
+	sort.SliceStable(dates, func(i, j int) bool { return dates[i].at.Before(dates[j].at) })
+	return dates[0].date, dates[0].at, true
+}
+
+// GiftRequest дополняет запрос полями профиля. Поля, заданные в запросе,
+// важнее профиля; повод по умолчанию - ближайшая важная дата.
+func (r *Recipient) GiftRequest(request types.GiftRequest, now time.Time) types.GiftRequest {
+	if request.Gender == "" {
+		request.Gender = r.Gender
+	}
+	if request.Age == 0 {
+		request.Age = r.AgeAt(now)
+	}
+	if len(request.Interests) == 0 {
+		request.Interests = r.Interests
+	}
+	if request.Occasion == "" {
+		if date, _, ok := r.NextDate(now); ok {
+			request.Occasion = date.Occasion
+		}
+	}
+	return request
+}
+
+// GiftedProducts возвращает ID и ссылки уже подаренных товаров
+func (r *Recipient) GiftedProducts() []string {
+	var keys []string
+	for _, gift := range r.PastGifts {
+		if gift.ProductID != "" {
+			keys = append(keys, gift.ProductID)
+		}
+		if gift.URL != "" {
+			keys = append(keys, gift.URL)
+		}
+	}
+	return keys
+}
+
+// parseDate разбирает YYYY-MM-DD или MM-DD
+func parseDate(value string) (time.Time, error) {
+	if day, err := time.Parse(dateLayout, value); err == nil {
+		return day, nil
+	}
+	return time.Parse(annualDateLayout, value)
+}
+
+// NewID возвращает случайный идентификатор получателя
+func NewID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recipient id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
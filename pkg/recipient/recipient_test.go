@@ -0,0 +1,140 @@
+package recipient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+func day(value string) time.Time {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestAgeAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		recipient Recipient
+		now       string
+		want      int
+	}{
+		{name: "day before birthday", recipient: Recipient{BirthDate: "1990-05-10"}, now: "2026-05-09", want: 35},
+		{name: "on birthday", recipient: Recipient{BirthDate: "1990-05-10"}, now: "2026-05-10", want: 36},
+		{name: "later month", recipient: Recipient{BirthDate: "1990-05-10"}, now: "2026-12-01", want: 36},
+		{name: "born on feb 29 in non-leap year", recipient: Recipient{BirthDate: "2000-02-29"}, now: "2026-02-28", want: 25},
+		{name: "born on feb 29 after feb 28", recipient: Recipient{BirthDate: "2000-02-29"}, now: "2026-03-01", want: 26},
+		{name: "age without birth date", recipient: Recipient{Age: 42}, now: "2026-01-01", want: 42},
+		{name: "birth date wins over age", recipient: Recipient{BirthDate: "2020-01-01", Age: 42}, now: "2026-01-01", want: 6},
+		{name: "invalid birth date falls back to age", recipient: Recipient{BirthDate: "10.05.1990", Age: 30}, now: "2026-01-01", want: 30},
+		{name: "never negative", recipient: Recipient{BirthDate: "2027-01-01"}, now: "2026-01-01", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.recipient.AgeAt(day(tt.now)); got != tt.want {
+				t.Errorf("AgeAt(%s) = %d, want %d", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextDate(t *testing.T) {
+	recipient := Recipient{Dates: []ImportantDate{
+		{Occasion: "birthday", Date: "1990-05-10"},
+		{Occasion: "new_year", Date: "12-31"},
+		{Occasion: "broken", Date: "someday"},
+		{Occasion: "womens_day", Date: "03-08"},
+	}}
+
+	tests := []struct {
+		now          string
+		wantOccasion string
+		wantAt       string
+	}{
+		{now: "2026-03-01", wantOccasion: "womens_day", wantAt: "2026-03-08"},
+		{now: "2026-03-08", wantOccasion: "womens_day", wantAt: "2026-03-08"},
+		// Год в YYYY-MM-DD не учитывается - дата ежегодная
+		{now: "2026-03-09", wantOccasion: "birthday", wantAt: "2026-05-10"},
+		{now: "2026-06-01", wantOccasion: "new_year", wantAt: "2026-12-31"},
+		{now: "2027-01-01", wantOccasion: "womens_day", wantAt: "2027-03-08"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.now, func(t *testing.T) {
+			date, at, ok := recipient.NextDate(day(tt.now))
+			if !ok {
+				t.Fatal("NextDate() ok = false")
+			}
+			if date.Occasion != tt.wantOccasion || at.Format(dateLayout) != tt.wantAt {
+				t.Errorf("NextDate(%s) = %s %s, want %s %s", tt.now, date.Occasion, at.Format(dateLayout), tt.wantOccasion, tt.wantAt)
+			}
+		})
+	}
+
+	if _, _, ok := (&Recipient{Dates: []ImportantDate{{Occasion: "broken", Date: "someday"}}}).NextDate(day("2026-01-01")); ok {
+		t.Error("NextDate() without valid dates must return false")
+	}
+}
+
+func TestGiftRequest(t *testing.T) {
+	recipient := Recipient{
+		Gender:    "female",
+		BirthDate: "1970-04-20",
+		Interests: []string{"сад", "чай"},
+		Dates:     []ImportantDate{{Occasion: "birthday", Date: "1970-04-20"}},
+	}
+	now := day("2026-04-01")
+
+	tests := []struct {
+		name    string
+		request types.GiftRequest
+		want    types.GiftRequest
+	}{
+		{
+			name:    "empty request filled from profile",
+			request: types.GiftRequest{Language: "ru"},
+			want:    types.GiftRequest{Language: "ru", Gender: "female", Age: 55, Interests: []string{"сад", "чай"}, Occasion: "birthday"},
+		},
+		{
+			name:    "request fields win",
+			request: types.GiftRequest{Gender: "male", Age: 30, Interests: []string{"игры"}, Occasion: "new_year"},
+			want:    types.GiftRequest{Gender: "male", Age: 30, Interests: []string{"игры"}, Occasion: "new_year"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := recipient.GiftRequest(tt.request, now)
+			if got.Gender != tt.want.Gender || got.Age != tt.want.Age || got.Occasion != tt.want.Occasion || got.Language != tt.want.Language {
+				t.Errorf("GiftRequest() = %+v, want %+v", got, tt.want)
+			}
+			if len(got.Interests) != len(tt.want.Interests) || got.Interests[0] != tt.want.Interests[0] {
+				t.Errorf("Interests = %v, want %v", got.Interests, tt.want.Interests)
+			}
+		})
+	}
+
+	// Возраст считается на день повода, а не на сегодня
+	if got := recipient.GiftRequest(types.GiftRequest{}, day("2026-04-20")); got.Age != 56 {
+		t.Errorf("Age on occasion day = %d, want 56", got.Age)
+	}
+}
+
+func TestGiftedProducts(t *testing.T) {
+	recipient := Recipient{PastGifts: []PastGift{
+		{ProductID: "kaspi-1", URL: "https://kaspi.kz/shop/p/1/?utm_source=ig"},
+		{ProductID: "ozon-2"},
+		{URL: "https://m.aliexpress.ru/item/3.html"},
+	}}
+	got := recipient.GiftedProducts()
+	want := []string{"kaspi-1", "https://kaspi.kz/shop/p/1/?utm_source=ig", "ozon-2", "https://m.aliexpress.ru/item/3.html"}
+	if len(got) != len(want) {
+		t.Fatalf("GiftedProducts() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("GiftedProducts() = %v, want %v", got, want)
+		}
+	}
+}
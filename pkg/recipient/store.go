@@ -0,0 +1,162 @@
+package recipient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/versioned"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Store хранит адресные книги пользователей. Put записывает получателя,
+// только если его версия в хранилище равна expectedVersion (0 - получателя
+// еще нет), иначе ErrConflict.
+type Store interface {
+	List(ctx context.Context, userID string) ([]Recipient, error)
+	Get(ctx context.Context, userID, id string) (*Recipient, error)
+	Put(ctx context.Context, recipient *Recipient, expectedVersion int) error
+	Delete(ctx context.Context, userID, id string) error
+}
+
+// NewStoreFromEnv выбирает хранилище по RECIPIENTS_BACKEND и
+// RECIPIENTS_TABLE (по умолчанию recipients), см. versioned.FromEnv
+func NewStoreFromEnv(dynamoClient *dynamodb.Client) (Store, error) {
+	return versioned.FromEnv("RECIPIENTS", "recipients",
+		func(table string) Store { return NewDynamoStore(dynamoClient, table) },
+		func() Store { return NewMemoryStore() })
+}
+
+// DynamoStore хранит получателей в таблице с ключом user_id и ключом
+// сортировки recipient_id
+type DynamoStore struct {
+	client    *dynamodb.Client
+	tableName string
+	versions  *versioned.Table
+}
+
+func NewDynamoStore(client *dynamodb.Client, tableName string) *DynamoStore {
+	return &DynamoStore{
+		client:    client,
+		tableName: tableName,
+		versions:  versioned.NewTable(client, tableName, "recipient_id", ErrConflict),
+	}
+}
+
+func (s *DynamoStore) List(ctx context.Context, userID string) ([]Recipient, error) {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		KeyConditionExpression: aws.String("user_id = :user"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":user": &dyntypes.AttributeValueMemberS{Value: userID},
+		},
+	})
+
+	recipients := []Recipient{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query recipients: %w", err)
+		}
+		var items []Recipient
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal recipients: %w", err)
+		}
+		recipients = append(recipients, items...)
+	}
+	return recipients, nil
+}
+
+func (s *DynamoStore) Get(ctx context.Context, userID, id string) (*Recipient, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.tableName),
+		Key:            key(userID, id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipient: %w", err)
+	}
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var recipient Recipient
+	if err := attributevalue.UnmarshalMap(result.Item, &recipient); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recipient: %w", err)
+	}
+	return &recipient, nil
+}
+
+func (s *DynamoStore) Put(ctx context.Context, recipient *Recipient, expectedVersion int) error {
+	return s.versions.Put(ctx, recipient, expectedVersion)
+}
+
+func (s *DynamoStore) Delete(ctx context.Context, userID, id string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.tableName),
+		Key:                 key(userID, id),
+		ConditionExpression: aws.String("attribute_exists(recipient_id)"),
+	})
+	if err != nil {
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete recipient: %w", err)
+	}
+	return nil
+}
+
+func key(userID, id string) map[string]dyntypes.AttributeValue {
+	return map[string]dyntypes.AttributeValue{
+		"user_id":      &dyntypes.AttributeValueMemberS{Value: userID},
+		"recipient_id": &dyntypes.AttributeValueMemberS{Value: id},
+	}
+}
+
+// MemoryStore хранит получателей в памяти процесса - для локального запуска
+type MemoryStore struct {
+	recipients *versioned.Memory[versioned.Key, Recipient]
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{recipients: versioned.NewMemory[versioned.Key](func(r Recipient) int { return r.Version }, clone, ErrConflict)}
+}
+
+func (s *MemoryStore) List(ctx context.Context, userID string) ([]Recipient, error) {
+	recipients := s.recipients.Find(func(r Recipient) bool { return r.UserID == userID })
+	// Как в DynamoDB - по ключу сортировки
+	sort.Slice(recipients, func(i, j int) bool { return recipients[i].ID < recipients[j].ID })
+	return recipients, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, userID, id string) (*Recipient, error) {
+	recipient, ok := s.recipients.Get(versioned.Key{UserID: userID, ID: id})
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &recipient, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, recipient *Recipient, expectedVersion int) error {
+	return s.recipients.Put(versioned.Key{UserID: recipient.UserID, ID: recipient.ID}, *recipient, expectedVersion)
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, userID, id string) error {
+	if !s.recipients.Delete(versioned.Key{UserID: userID, ID: id}) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// clone копирует срезы, чтобы вызывающий код не менял сохраненную запись
+func clone(recipient Recipient) Recipient {
+	recipient.Interests = append([]string(nil), recipient.Interests...)
+	recipient.Dates = append([]ImportantDate(nil), recipient.Dates...)
+	recipient.PastGifts = append([]PastGift(nil), recipient.PastGifts...)
+	return recipient
+}
@@ -0,0 +1,125 @@
+package recommend
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Возраст старше этого считаем ошибкой ввода
+const maxAge = 120
+
+var (
+	ErrUnknownOccasion = errors.New("unknown occasion")
+	ErrInvalidAge      = errors.New("invalid age")
+)
+
+// Resolver подбирает взвешенный набор категорий по поводу, возрасту и полу
+// получателя. Границы возрастных групп, синонимы пола и веса берутся из
+// таксономии, поэтому меняются без пересборки.
+type Resolver struct {
+	taxonomy func() *taxonomy.Taxonomy
+}
+
+// NewResolver создает резолвер поверх таксономии по умолчанию
+func NewResolver() *Resolver {
+	return &Resolver{taxonomy: taxonomy.Current}
+}
+
+// NewResolverWithTaxonomy создает резолвер с фиксированной таксономией
+func NewResolverWithTaxonomy(t *taxonomy.Taxonomy) *Resolver {
+	return &Resolver{taxonomy: func() *taxonomy.Taxonomy { return t }}
+}
+
+// Resolve возвращает категории, отсортированные по весу (лучшая - 1).
+// Возраст 0 и меньше считается неизвестным, неизвестный пол не влияет на
+// веса. Если ни повод, ни возраст не заданы, все категории равновесны.
+func (r *Resolver) Resolve(request types.GiftRequest) (*types.CategoryResolution, error) {
+	t := r.taxonomy()
+	resolution := &types.CategoryResolution{TaxonomyVersion: t.Version}
+
+	scores := make(map[string]float64)
+	reasons := make(map[string][]string)
+	add := func(categories []string, weight float64, reason string) {
+		for _, category := range categories {
+			scores[category] += weight
+			reasons[category] = append(reasons[category], reason)
+		}
+	}
+
+	if occasion := strings.ToLower(strings.TrimSpace(request.Occasion)); occasion != "" {
+		if _, ok := t.Occasions[occasion]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownOccasion, request.Occasion)
+		}
+		resolution.Occasion = occasion
+		add(t.OccasionCategories(occasion), t.Scoring.Occasion, "occasion:"+occasion)
+	}
+
+	if request.Age > maxAge || request.Age < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidAge, request.Age)
+	}
+	if request.Age > 0 {
+		if key, group, ok := t.AgeGroupFor(request.Age); ok {
+			resolution.AgeGroup = key
+			add(group.Categories, t.Scoring.AgeGroup, "age:"+key)
+		}
+	}
+
+	// Без повода и возраста подходит любая категория
+	if len(scores) == 0 {
+		add(t.CategoryKeys(), 1, "default")
+	}
+
+	if gender, ok := t.ResolveGender(request.Gender); ok {
+		resolution.Gender = gender
+		for category, factor := range t.Genders[gender].Weights {
+			if _, ok := scores[category]; ok {
+				scores[category] *= factor
+				reasons[category] = append(reasons[category], "gender:"+gender)
+			}
+		}
+	}
+
+	best := 0.0
+	for _, score := range scores {
+		best = math.Max(best, score)
+	}
+
+	for category, score := range scores {
+		weight := 0.0
+		if best > 0 {
+			weight = math.Round(score/best*100) / 100
+		}
+		resolution.Categories = append(resolution.Categories, types.CategorySuggestion{
+			Category: category,
+			Name:     t.DisplayName(category, request.Language),
+			Weight:   weight,
+			Reasons:  reasons[category],
+		})
+	}
+
+	// При равном весе порядок стабилен по ключу категории
+	sort.Slice(resolution.Categories, func(i, j int) bool {
+		a, b := resolution.Categories[i], resolution.Categories[j]
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		return a.Category < b.Category
+	})
+
+	return resolution, nil
+}
+
+// CategoryKeys возвращает ключи категорий в порядке веса
+func CategoryKeys(resolution *types.CategoryResolution) []string {
+	keys := make([]string, len(resolution.Categories))
+	for i, suggestion := range resolution.Categories {
+		keys[i] = suggestion.Category
+	}
+	return keys
+}
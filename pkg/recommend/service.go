@@ -0,0 +1,144 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/marketplace"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/summary"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+const (
+	// Сколько лучших категорий передается в поиск
+	searchCategories = 3
+	// Сколько товаров попадает в подборку
+	recommendLimit = 5
+)
+
+// Speaker озвучивает текст и возвращает ссылку на аудио; его реализует
+// translator.Translator
+type Speaker interface {
+	TextToSpeech(ctx context.Context, text, lang string) (string, error)
+}
+
+// Service собирает подборку подарков: категории по запросу, поиск товаров,
+// текстовое резюме и, по желанию, его озвучку
+type Service struct {
+	resolver  *Resolver
+	products  *marketplace.ProductService
+	summaries *summary.Generator
+	speech    Speaker // nil - голосовые ответы отключены
+}
+
+func NewService(resolver *Resolver, products *marketplace.ProductService, summaries *summary.Generator, speech Speaker) *Service {
+	return &Service{
+		resolver:  resolver,
+		products:  products,
+		summaries: summaries,
+		speech:    speech,
+	}
+}
+
+// Recommend подбирает подарки по запросу. exclude - ID или ссылки товаров,
+// которые не нужно предлагать (например, уже подаренные). Ошибки
+// ErrUnknownOccasion и ErrInvalidAge означают некорректный запрос.
+func (s *Service) Recommend(ctx context.Context, request types.GiftRequest, exclude []string) (*types.GiftRecommendation, error) {
+	resolution, err := s.resolver.Resolve(request)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := resolution.Categories
+	if len(suggestions) > searchCategories {
+		suggestions = suggestions[:searchCategories]
+	}
+
+	result, err := s.products.SearchProducts(ctx, types.SearchIntent{
+		Categories:  CategoryKeys(&types.CategoryResolution{Categories: suggestions}),
+		Interests:   request.Interests,
+		PriceRange:  request.PriceRange,
+		Marketplace: request.Marketplace,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+	result.Products = excludeProducts(result.Products, exclude)
+
+	page, err := s.products.List(result, marketplace.ListOptions{
+		Sort:  marketplace.SortRelevance,
+		Limit: recommendLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+
+	text, err := s.summaries.Summarize(ctx, summary.Input{
+		Request:    request,
+		Products:   page.Products,
+		Total:      page.Total,
+		Categories: suggestions,
+		Currency:   result.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recommendation := &types.GiftRecommendation{
+		Products: page.Products,
+		Summary:  text,
+	}
+
+	if request.VoiceEnabled && s.speech != nil {
+		audioURL, err := s.speech.TextToSpeech(ctx, text, request.Language)
+		if err != nil {
+			fmt.Printf("Failed to synthesize summary: %v\n", err)
+		} else {
+			recommendation.AudioURL = audioURL
+		}
+	}
+
+	return recommendation, nil
+}
+
+// excludeProducts убирает товары, у которых ID или ссылка (в том числе в
+// объединенных предложениях других магазинов) есть в exclude. Ссылки
+// сравниваются в каноническом виде: сохраненная ссылка с метками или
+// мобильным хостом совпадает с той же карточкой из поиска.
+func excludeProducts(products []types.Product, exclude []string) []types.Product {
+	if len(exclude) == 0 {
+		return products
+	}
+	excluded := make(map[string]bool, 2*len(exclude))
+	for _, key := range exclude {
+		if key == "" {
+			continue
+		}
+		excluded[key] = true
+		excluded[marketplace.CanonicalURL(key)] = true
+	}
+
+	filtered := make([]types.Product, 0, len(products))
+	for _, product := range products {
+		if !isExcluded(product, excluded) {
+			filtered = append(filtered, product)
+		}
+	}
+	return filtered
+}
+
+func isExcluded(product types.Product, excluded map[string]bool) bool {
+	if excluded[product.ID] || isExcludedURL(product.URL, excluded) {
+		return true
+	}
+	for _, offer := range product.Offers {
+		if excluded[offer.ProductID] || isExcludedURL(offer.URL, excluded) {
+			return true
+		}
+	}
+	return false
+}
+
+func isExcludedURL(url string, excluded map[string]bool) bool {
+	return url != "" && excluded[marketplace.CanonicalURL(url)]
+}
@@ -0,0 +1,49 @@
+package recommend
+
+import (
+	"testing"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+func TestExcludeProducts(t *testing.T) {
+	products := []types.Product{
+		{ID: "kaspi-1", URL: "https://kaspi.kz/shop/p/naushniki-1/"},
+		{ID: "ae-100", URL: "https://aliexpress.com/item/100.html"},
+		{ID: "wb-7", URL: "https://www.wildberries.ru/catalog/7/detail.aspx", Offers: []types.Offer{
+			{ProductID: "ozon-5", URL: "https://www.ozon.ru/product/5/"},
+		}},
+		{ID: "dyn-9", URL: ""},
+	}
+
+	tests := []struct {
+		name    string
+		exclude []string
+		want    []string
+	}{
+		{name: "nothing excluded", exclude: nil, want: []string{"kaspi-1", "ae-100", "wb-7", "dyn-9"}},
+		{name: "by product id", exclude: []string{"kaspi-1"}, want: []string{"ae-100", "wb-7", "dyn-9"}},
+		{name: "url with tracking params", exclude: []string{"https://kaspi.kz/shop/p/naushniki-1?utm_source=ig&ref=share"}, want: []string{"ae-100", "wb-7", "dyn-9"}},
+		{name: "mobile regional host", exclude: []string{"https://m.aliexpress.ru/item/100.html"}, want: []string{"kaspi-1", "wb-7", "dyn-9"}},
+		{name: "offer url of merged product", exclude: []string{"http://m.ozon.ru/product/5?from=share"}, want: []string{"kaspi-1", "ae-100", "dyn-9"}},
+		{name: "offer product id", exclude: []string{"ozon-5"}, want: []string{"kaspi-1", "ae-100", "dyn-9"}},
+		{name: "empty key ignored", exclude: []string{""}, want: []string{"kaspi-1", "ae-100", "wb-7", "dyn-9"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := excludeProducts(products, tt.exclude)
+			var ids []string
+			for _, product := range got {
+				ids = append(ids, product.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("products = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("products = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
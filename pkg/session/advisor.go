@@ -0,0 +1,135 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/recommend"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Advisor ведет диалог: разбирает ответы, задает следующий вопрос и после
+// каждого ответа уточняет подборку подарков
+type Advisor struct {
+	store       Store
+	recommender *recommend.Service
+	ttl         time.Duration
+}
+
+func NewAdvisor(store Store, recommender *recommend.Service, ttl time.Duration) *Advisor {
+	return &Advisor{
+		store:       store,
+		recommender: recommender,
+		ttl:         ttl,
+	}
+}
+
+// Start создает сессию. prefill - уже известные поля запроса, message -
+// первое сообщение пользователя; оба необязательны.
+func (a *Advisor) Start(ctx context.Context, prefill *types.GiftRequest, message string) (*types.AdvisorTurnResponseApi, error) {
+	id, err := NewID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := &Session{ID: id, CreatedAt: now}
+	if prefill != nil {
+		session.Request = *prefill
+	}
+	a.apply(session, message)
+
+	return a.turn(ctx, session, 0)
+}
+
+// Reply добавляет ответ пользователя в сессию. Если сессию параллельно
+// изменил другой запрос, возвращается ErrConflict.
+func (a *Advisor) Reply(ctx context.Context, id, message string) (*types.AdvisorTurnResponseApi, error) {
+	session, err := a.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedVersion := session.Version
+	a.apply(session, message)
+
+	return a.turn(ctx, session, expectedVersion)
+}
+
+// Get возвращает состояние сессии и текущий вопрос без поиска товаров
+func (a *Advisor) Get(ctx context.Context, id string) (*types.AdvisorTurnResponseApi, error) {
+	session, err := a.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.AdvisorTurnResponseApi{
+		SessionID: session.ID,
+		Request:   session.Request,
+		Missing:   session.Missing(),
+		Turns:     session.Turns,
+	}
+	if session.Pending != "" {
+		response.Question = Question(session.Pending, session.Request.Language)
+	}
+	return response, nil
+}
+
+// apply разбирает сообщение и запоминает отказ отвечать на текущий вопрос
+func (a *Advisor) apply(session *Session, message string) {
+	if session.Request.Language == "" {
+		session.Request.Language = DetectLanguage(message)
+	}
+	if message != "" {
+		session.Turns++
+	}
+
+	answer := ParseAnswer(message, session.Pending, &session.Request)
+	if answer.Skipped && !session.skipped(session.Pending) {
+		session.Skipped = append(session.Skipped, session.Pending)
+	}
+}
+
+// turn подбирает подарки по известным полям, выбирает следующий вопрос и
+// сохраняет сессию
+func (a *Advisor) turn(ctx context.Context, session *Session, expectedVersion int) (*types.AdvisorTurnResponseApi, error) {
+	recommendation, err := a.recommender.Recommend(ctx, session.Request, nil)
+	if errors.Is(err, recommend.ErrUnknownOccasion) || errors.Is(err, recommend.ErrInvalidAge) {
+		return nil, err
+	}
+
+	missing := session.Missing()
+	session.Pending = ""
+	if len(missing) > 0 {
+		session.Pending = missing[0]
+	}
+
+	response := &types.AdvisorTurnResponseApi{
+		SessionID: session.ID,
+		Missing:   missing,
+		Turns:     session.Turns,
+	}
+	if session.Pending != "" {
+		response.Question = Question(session.Pending, session.Request.Language)
+	}
+
+	// Ошибка поиска не должна обрывать диалог: вопрос задаем в любом случае
+	if err != nil {
+		fmt.Printf("Failed to recommend gifts for session %s: %v\n", session.ID, err)
+	} else {
+		response.Recommendation = recommendation
+	}
+
+	now := time.Now().UTC()
+	session.Version = expectedVersion + 1
+	session.UpdatedAt = now
+	session.ExpiresAt = now.Add(a.ttl).Unix()
+	if err := a.store.Put(ctx, session, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	response.Request = session.Request
+	return response, nil
+}
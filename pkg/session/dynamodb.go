@@ -0,0 +1,61 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/versioned"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoStore хранит сессии в таблице с ключом session_id и TTL по
+// атрибуту expires_at
+type DynamoStore struct {
+	client    *dynamodb.Client
+	tableName string
+	versions  *versioned.Table
+}
+
+func NewDynamoStore(client *dynamodb.Client, tableName string) *DynamoStore {
+	return &DynamoStore{
+		client:    client,
+		tableName: tableName,
+		versions:  versioned.NewTable(client, tableName, "session_id", ErrConflict),
+	}
+}
+
+func (s *DynamoStore) Get(ctx context.Context, id string) (*Session, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key: map[string]dyntypes.AttributeValue{
+			"session_id": &dyntypes.AttributeValueMemberS{Value: id},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var session Session
+	if err := attributevalue.UnmarshalMap(result.Item, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+
+	// DynamoDB удаляет просроченные записи с задержкой, проверяем срок сами
+	if session.ExpiresAt != 0 && time.Now().Unix() >= session.ExpiresAt {
+		return nil, ErrNotFound
+	}
+
+	return &session, nil
+}
+
+func (s *DynamoStore) Put(ctx context.Context, session *Session, expectedVersion int) error {
+	return s.versions.Put(ctx, session, expectedVersion)
+}
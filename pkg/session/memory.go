@@ -0,0 +1,36 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/versioned"
+)
+
+// MemoryStore хранит сессии в памяти процесса - для локального запуска
+type MemoryStore struct {
+	sessions *versioned.Memory[string, Session]
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: versioned.NewMemory[string](func(s Session) int { return s.Version }, clone, ErrConflict)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Session, error) {
+	session, ok := s.sessions.Get(id)
+	if !ok || (session.ExpiresAt != 0 && time.Now().Unix() >= session.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return &session, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, session *Session, expectedVersion int) error {
+	return s.sessions.Put(session.ID, *session, expectedVersion)
+}
+
+// clone копирует срезы, чтобы вызывающий код не менял сохраненную сессию
+func clone(session Session) Session {
+	session.Skipped = append([]string(nil), session.Skipped...)
+	session.Request.Interests = append([]string(nil), session.Request.Interests...)
+	return session
+}
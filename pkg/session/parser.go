@@ -0,0 +1,348 @@
+package session
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/stemmer"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Ответы, которыми пользователь пропускает вопрос
+var skipPhrases = []string{
+	"не знаю", "неважно", "без разницы", "пропусти", "любой", "любая", "любые",
+	"skip", "don't know", "dont know", "any", "whatever",
+	"білмеймін", "маңызды емес",
+}
+
+var (
+	digitGroups = regexp.MustCompile(`(\d) (\d{3})\b`)
+
+	number = `(\d+(?:[.,]\d+)?)\s*(к|k|тыс\.?|тысяч[аи]?|мың)?`
+
+	agePattern = regexp.MustCompile(`(\d{1,3})\s*(?:-?\s*(?:лет|год(?:а|ик(?:а|ов)?)?|years?|y\.?o\.?|жас(?:та|қа|ар)?))`)
+
+	rangePattern = regexp.MustCompile(`(?:от\s+)?` + number + `\s*(?:-|–|—|до|to)\s*` + number)
+	// Ключевые слова бюджета - только целыми словами: "до" в "надо" не граница
+	// цены. \b в regexp не работает с кириллицей, поэтому граница задается явно.
+	maxPattern   = regexp.MustCompile(`(?:^|[^\pL])(?:до|не дороже|не больше|в пределах|максимум|up to|under|below|max)\s*` + number)
+	maxKkPattern = regexp.MustCompile(number + `\s*(?:теңге|тг|₸)?\pL*\s+дейін`)
+	minPattern   = regexp.MustCompile(`(?:^|[^\pL])(?:от|не дешевле|from|over|at least)\s*` + number)
+	plainNumber  = regexp.MustCompile(`^\s*` + number + `\s*(?:тенге|тг|₸|теңге|руб\w*|₽|\$|usd|kzt)?\s*$`)
+
+	interestPattern = regexp.MustCompile(`(?:любит|увлекается|интересуется|нравится|нравятся|обожает|likes?|loves?|into|interested in|ұнатады|қызығады)\s+(.+?)(?:[.;!?]|$)`)
+	interestSplit   = regexp.MustCompile(`\s*(?:,|;|/|\sи\s|\sand\s|\sжәне\s|\sмен\s)\s*`)
+)
+
+// Названия маркетплейсов в свободном тексте
+var marketplaceAliases = map[string]string{
+	"kaspi": "kaspi", "каспи": "kaspi",
+	"ozon": "ozon", "озон": "ozon",
+	"wildberries": "wildberries", "вайлдберриз": "wildberries",
+	"aliexpress": "aliexpress", "алиэкспресс": "aliexpress",
+}
+
+// Сокращения, которые совпадают с обычными словами ("подарок для Али"):
+// принимаются, только если это весь ответ или в тексте речь о магазине
+var shortMarketplaceAliases = map[string]string{
+	"wb": "wildberries", "вб": "wildberries",
+	"али": "aliexpress",
+}
+
+var marketplaceContext = []string{"маркетплейс", "магазин", "marketplace", "shop", "store", "дүкен"}
+
+// Answer - что удалось извлечь из ответа пользователя
+type Answer struct {
+	Updated []string // Заполненные поля GiftRequest
+	Skipped bool     // Пользователь отказался отвечать на заданный вопрос
+}
+
+// ParseAnswer разбирает свободный текст и дополняет request. pending -
+// поле, о котором спросили последним: голое число в ответе на вопрос о
+// возрасте - это возраст, на вопрос о бюджете - верхняя граница цены.
+func ParseAnswer(text, pending string, request *types.GiftRequest) Answer {
+	var answer Answer
+	lower := strings.ToLower(strings.TrimSpace(text))
+	if lower == "" {
+		return answer
+	}
+	// "10 000" -> "10000", чтобы разделитель разрядов не разрывал число
+	lower = digitGroups.ReplaceAllString(lower, "$1$2")
+	t := taxonomy.Current()
+	stems := stemmer.Tokens(lower)
+	if DetectLanguage(lower) == "kk" {
+		// В казахском тексте слова без особых букв ("анама") иначе
+		// разбираются русским стеммером
+		for _, word := range strings.FieldsFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) }) {
+			if stem := stemmer.Kazakh(word); !contains(stems, stem) {
+				stems = append(stems, stem)
+			}
+		}
+	}
+
+	if occasion := matchOccasion(t, lower, stems); occasion != "" {
+		request.Occasion = occasion
+		answer.Updated = append(answer.Updated, FieldOccasion)
+	}
+
+	// Возраст вырезаем из текста, чтобы "30 лет" не стало бюджетом
+	rest := lower
+	if m := agePattern.FindStringSubmatchIndex(lower); m != nil {
+		if age, err := strconv.Atoi(lower[m[2]:m[3]]); err == nil && age > 0 && age <= 120 {
+			request.Age = age
+			answer.Updated = append(answer.Updated, FieldAge)
+			rest = lower[:m[0]] + " " + lower[m[1]:]
+		}
+	} else if pending == FieldAge {
+		if m := plainNumber.FindStringSubmatch(lower); m != nil {
+			if age, err := strconv.Atoi(m[1]); err == nil && age > 0 && age <= 120 {
+				request.Age = age
+				answer.Updated = append(answer.Updated, FieldAge)
+				rest = ""
+			}
+		}
+	}
+
+	if gender := matchGender(t, stems); gender != "" {
+		request.Gender = gender
+		answer.Updated = append(answer.Updated, FieldGender)
+	}
+
+	if priceRange, ok := parseBudget(rest, pending == FieldPriceRange); ok {
+		request.PriceRange = priceRange
+		answer.Updated = append(answer.Updated, FieldPriceRange)
+	}
+
+	if interests := parseInterests(lower, pending == FieldInterests && !isSkip(lower)); len(interests) > 0 {
+		request.Interests = mergeInterests(request.Interests, interests)
+		answer.Updated = append(answer.Updated, FieldInterests)
+	}
+
+	if marketplace := matchMarketplace(lower); marketplace != "" {
+		request.Marketplace = marketplace
+	}
+
+	if pending != "" && !contains(answer.Updated, pending) && isSkip(lower) {
+		answer.Skipped = true
+	}
+
+	return answer
+}
+
+// matchMarketplace ищет маркетплейс по названию; короткие сокращения
+// учитываются только в ответе из одного слова или рядом со словом "магазин"
+func matchMarketplace(text string) string {
+	tokens := strings.Fields(text)
+	short := len(tokens) == 1
+	for _, word := range marketplaceContext {
+		if strings.Contains(text, word) {
+			short = true
+			break
+		}
+	}
+	for _, token := range tokens {
+		token = strings.Trim(token, ".,!?;:")
+		if marketplace, ok := marketplaceAliases[token]; ok {
+			return marketplace
+		}
+		if marketplace, ok := shortMarketplaceAliases[token]; ok && short {
+			return marketplace
+		}
+	}
+	return ""
+}
+
+// matchOccasion ищет повод по названиям и синонимам из таксономии;
+// фраза из нескольких слов должна совпасть целиком
+func matchOccasion(t *taxonomy.Taxonomy, text string, stems []string) string {
+	for _, key := range t.OccasionKeys() {
+		occasion := t.Occasions[key]
+		phrases := append([]string{key}, occasion.Aliases...)
+		for _, name := range occasion.Names {
+			phrases = append(phrases, name)
+		}
+		for _, phrase := range phrases {
+			if phraseMatches(strings.ToLower(phrase), stems) {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+func phraseMatches(phrase string, stems []string) bool {
+	words := stemmer.Tokens(phrase)
+	if len(words) == 0 {
+		return false
+	}
+	for _, word := range words {
+		if !contains(stems, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchGender сравнивает основы слов с полами и их синонимами ("маме" -> female)
+func matchGender(t *taxonomy.Taxonomy, stems []string) string {
+	for _, stem := range stems {
+		for _, key := range t.GenderKeys() {
+			for _, alias := range append([]string{key}, t.Genders[key].Aliases...) {
+				alias = strings.ToLower(alias)
+				// Однобуквенные синонимы ("м", "ж") принимаем только как весь ответ
+				if len([]rune(alias)) < 2 && len(stems) > 1 {
+					continue
+				}
+				if stemmer.Stem(alias) == stem || stemmer.Kazakh(alias) == stem {
+					return key
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// parseBudget ищет диапазон "от 10 до 20 тысяч", "10-20к", "до 15000",
+// "от 5000"; голое число считается верхней границей, если спрашивали бюджет
+func parseBudget(text string, expected bool) (types.Range, bool) {
+	var priceRange types.Range
+	if currency, ok := money.DetectCurrency(text); ok {
+		priceRange.Currency = currency
+	}
+
+	switch {
+	case rangePattern.MatchString(text):
+		m := rangePattern.FindStringSubmatch(text)
+		// Множитель "тысяч" после второго числа относится к обоим: "10-20 тысяч"
+		unit := m[4]
+		if m[2] != "" {
+			unit = m[2]
+		}
+		priceRange.Min = amount(m[1], unit)
+		priceRange.Max = amount(m[3], m[4])
+	case maxPattern.MatchString(text):
+		m := maxPattern.FindStringSubmatch(text)
+		priceRange.Max = amount(m[1], m[2])
+	case maxKkPattern.MatchString(text):
+		m := maxKkPattern.FindStringSubmatch(text)
+		priceRange.Max = amount(m[1], m[2])
+	case minPattern.MatchString(text):
+		m := minPattern.FindStringSubmatch(text)
+		priceRange.Min = amount(m[1], m[2])
+	case expected && plainNumber.MatchString(text):
+		m := plainNumber.FindStringSubmatch(text)
+		priceRange.Max = amount(m[1], m[2])
+	default:
+		return types.Range{}, false
+	}
+
+	if priceRange.Min <= 0 && priceRange.Max <= 0 {
+		return types.Range{}, false
+	}
+	if priceRange.Max > 0 && priceRange.Min > priceRange.Max {
+		priceRange.Min, priceRange.Max = priceRange.Max, priceRange.Min
+	}
+	return priceRange, true
+}
+
+func amount(value, unit string) float64 {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+	if err != nil {
+		return 0
+	}
+	if unit != "" {
+		v *= 1000
+	}
+	return v
+}
+
+// parseInterests берет интересы после "любит", "увлекается" и т.п.; если
+// спрашивали об интересах, интересами считается весь ответ
+func parseInterests(text string, expected bool) []string {
+	var phrase string
+	if m := interestPattern.FindStringSubmatch(text); m != nil {
+		phrase = m[1]
+	} else if expected {
+		phrase = strings.TrimRight(text, ".!?")
+	}
+	if phrase == "" {
+		return nil
+	}
+
+	var interests []string
+	for _, part := range interestSplit.Split(phrase, -1) {
+		part = strings.TrimSpace(part)
+		// Бюджет и возраст после перечисления интересов ("..., бюджет 10к") не интересы
+		if part == "" || len([]rune(part)) > 40 || strings.ContainsAny(part, "0123456789") || notInterest(part) {
+			continue
+		}
+		interests = append(interests, part)
+	}
+	return interests
+}
+
+var notInterestWords = []string{"бюджет", "budget", "бюджеті", "цена", "price", "баға"}
+
+func notInterest(part string) bool {
+	first := strings.Fields(part)[0]
+	for _, word := range notInterestWords {
+		if first == word {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeInterests(existing, added []string) []string {
+	merged := append([]string(nil), existing...)
+	for _, interest := range added {
+		if !contains(merged, interest) {
+			merged = append(merged, interest)
+		}
+	}
+	return merged
+}
+
+func isSkip(text string) bool {
+	for _, phrase := range skipPhrases {
+		if text == phrase || strings.HasPrefix(text, phrase+" ") || strings.HasPrefix(text, phrase+",") {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectLanguage определяет язык сообщения: казахские буквы - kk, другая
+// кириллица - ru, латиница - en; пустая строка, если букв нет
+func DetectLanguage(text string) string {
+	text = strings.ToLower(text)
+	if strings.ContainsAny(text, "әғқңөұүһі") {
+		return "kk"
+	}
+	latin := false
+	for _, r := range text {
+		switch {
+		case r >= 'а' && r <= 'я' || r == 'ё':
+			return "ru"
+		case r >= 'a' && r <= 'z':
+			latin = true
+		}
+	}
+	if latin {
+		return "en"
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+func TestParseAnswer(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		pending     string
+		age         int
+		priceRange  types.Range
+		marketplace string
+		skipped     bool
+	}{
+		{name: "max budget", text: "до 15000", priceRange: types.Range{Max: 15000}},
+		{name: "max budget with digit groups", text: "не дороже 15 000 тенге", priceRange: types.Range{Max: 15000, Currency: "KZT"}},
+		{name: "range with thousands", text: "от 10 до 20 тысяч", priceRange: types.Range{Min: 10000, Max: 20000}},
+		{name: "dash range", text: "10-20к", priceRange: types.Range{Min: 10000, Max: 20000}},
+		{name: "min budget", text: "от 5000", priceRange: types.Range{Min: 5000}},
+		{name: "kazakh max budget", text: "20 мың дейін", priceRange: types.Range{Max: 20000}},
+		{name: "do inside word is not budget", text: "надо 3 штуки"},
+		{name: "ot inside word is not budget", text: "работа 5 дней в неделю"},
+		{name: "age is not budget", text: "30 лет", age: 30},
+		{name: "bare number for age", text: "45", pending: FieldAge, age: 45},
+		{name: "bare number for budget", text: "25к", pending: FieldPriceRange, priceRange: types.Range{Max: 25000}},
+		{name: "bare number without question", text: "25000"},
+		{name: "marketplace name", text: "лучше на каспи", marketplace: "kaspi"},
+		{name: "marketplace latin", text: "ozon please", marketplace: "ozon"},
+		{name: "name is not marketplace", text: "подарок для Али на день рождения"},
+		{name: "short alias as whole answer", text: "Али", marketplace: "aliexpress"},
+		{name: "short alias with context", text: "любой магазин, можно вб", marketplace: "wildberries"},
+		{name: "skip", text: "не знаю", pending: FieldPriceRange, skipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request types.GiftRequest
+			answer := ParseAnswer(tt.text, tt.pending, &request)
+
+			if request.Age != tt.age {
+				t.Errorf("Age = %d, want %d", request.Age, tt.age)
+			}
+			if request.PriceRange != tt.priceRange {
+				t.Errorf("PriceRange = %+v, want %+v", request.PriceRange, tt.priceRange)
+			}
+			if request.Marketplace != tt.marketplace {
+				t.Errorf("Marketplace = %q, want %q", request.Marketplace, tt.marketplace)
+			}
+			if answer.Skipped != tt.skipped {
+				t.Errorf("Skipped = %v, want %v", answer.Skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestParseAnswerOccasionAndGender(t *testing.T) {
+	var request types.GiftRequest
+	answer := ParseAnswer("Подарок маме на день рождения, она любит чай и книги", "", &request)
+
+	if request.Occasion == "" {
+		t.Error("occasion not detected")
+	}
+	if request.Gender != "female" {
+		t.Errorf("Gender = %q, want female", request.Gender)
+	}
+	if len(request.Interests) != 2 || request.Interests[0] != "чай" || request.Interests[1] != "книги" {
+		t.Errorf("Interests = %q, want [чай книги]", request.Interests)
+	}
+	if !contains(answer.Updated, FieldInterests) {
+		t.Errorf("Updated = %q, want interests", answer.Updated)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"подарок маме":     "ru",
+		"gift for mom":     "en",
+		"анама сыйлық":     "kk",
+		"12345":            "",
+		"бюджет 10k":       "ru",
+		"әкеме сыйлық бер": "kk",
+	}
+	for text, want := range tests {
+		if got := DetectLanguage(text); got != want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
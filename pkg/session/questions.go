@@ -0,0 +1,75 @@
+package session
+
+import (
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Тексты уточняющих вопросов по языкам
+var questionTexts = map[string]map[string]string{
+	"ru": {
+		FieldOccasion:   "По какому поводу подарок?",
+		FieldAge:        "Сколько лет получателю?",
+		FieldGender:     "Кому дарим: мужчине или женщине?",
+		FieldInterests:  "Чем увлекается получатель?",
+		FieldPriceRange: "На какой бюджет рассчитываете?",
+	},
+	"en": {
+		FieldOccasion:   "What is the occasion?",
+		FieldAge:        "How old is the recipient?",
+		FieldGender:     "Is the gift for a man or a woman?",
+		FieldInterests:  "What is the recipient interested in?",
+		FieldPriceRange: "What is your budget?",
+	},
+	"kk": {
+		FieldOccasion:   "Сыйлық қандай себеппен?",
+		FieldAge:        "Алушы неше жаста?",
+		FieldGender:     "Кімге сыйлаймыз: ер адамға ма, әйелге ме?",
+		FieldInterests:  "Алушы немен айналысады?",
+		FieldPriceRange: "Бюджетіңіз қандай?",
+	},
+}
+
+// Варианты ответа на вопрос о бюджете
+var budgetOptions = map[string][]string{
+	"ru": {"до 10 000 тенге", "10 000 - 25 000 тенге", "25 000 - 50 000 тенге", "от 50 000 тенге"},
+	"en": {"up to 10000 KZT", "10000 - 25000 KZT", "25000 - 50000 KZT", "from 50000 KZT"},
+	"kk": {"10 000 теңгеге дейін", "10 000 - 25 000 теңге", "25 000 - 50 000 теңге", "50 000 теңгеден"},
+}
+
+// Question возвращает вопрос о поле на языке lang с вариантами ответа
+func Question(field, lang string) *types.AdvisorQuestion {
+	texts, ok := questionTexts[lang]
+	if !ok {
+		lang = "ru"
+		texts = questionTexts[lang]
+	}
+
+	question := &types.AdvisorQuestion{
+		Field: field,
+		Text:  texts[field],
+	}
+
+	t := taxonomy.Current()
+	switch field {
+	case FieldOccasion:
+		for _, key := range t.OccasionKeys() {
+			question.Options = append(question.Options, localizedName(t.Occasions[key].Names, key, lang))
+		}
+	case FieldGender:
+		for _, key := range t.GenderKeys() {
+			question.Options = append(question.Options, localizedName(t.Genders[key].Names, key, lang))
+		}
+	case FieldPriceRange:
+		question.Options = budgetOptions[lang]
+	}
+
+	return question
+}
+
+func localizedName(names map[string]string, key, lang string) string {
+	if name, ok := names[lang]; ok {
+		return name
+	}
+	return key
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/versioned"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Сколько живет сессия без новых сообщений
+const defaultTTL = 24 * time.Hour
+
+var (
+	ErrNotFound = errors.New("session not found")
+	// ErrConflict - сессию успели изменить параллельным запросом
+	ErrConflict = errors.New("session was modified concurrently")
+)
+
+// Поля GiftRequest, о которых советник спрашивает, в порядке вопросов
+const (
+	FieldOccasion   = "occasion"
+	FieldAge        = "age"
+	FieldGender     = "gender"
+	FieldInterests  = "interests"
+	FieldPriceRange = "price_range"
+)
+
+var Fields = []string{FieldOccasion, FieldAge, FieldGender, FieldInterests, FieldPriceRange}
+
+// Session - частично заполненный GiftRequest и ход диалога
+type Session struct {
+	ID        string            `dynamodbav:"session_id" json:"session_id"`
+	Request   types.GiftRequest `dynamodbav:"request" json:"request"`
+	Pending   string            `dynamodbav:"pending,omitempty" json:"pending,omitempty"` // Поле, о котором спросили последним
+	Skipped   []string          `dynamodbav:"skipped,omitempty" json:"skipped,omitempty"` // Поля, на которые пользователь не стал отвечать
+	Turns     int               `dynamodbav:"turns" json:"turns"`
+	Version   int               `dynamodbav:"version" json:"version"` // Для оптимистичной блокировки
+	CreatedAt time.Time         `dynamodbav:"created_at" json:"created_at"`
+	UpdatedAt time.Time         `dynamodbav:"updated_at" json:"updated_at"`
+	ExpiresAt int64             `dynamodbav:"expires_at" json:"-"` // TTL DynamoDB, unix-время
+}
+
+// Missing возвращает незаполненные поля, на которые пользователь еще не
+// отказался отвечать, в порядке вопросов
+func (s *Session) Missing() []string {
+	var missing []string
+	for _, field := range Fields {
+		if !s.filled(field) && !s.skipped(field) {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+func (s *Session) filled(field string) bool {
+	request := s.Request
+	switch field {
+	case FieldOccasion:
+		return request.Occasion != ""
+	case FieldAge:
+		return request.Age > 0
+	case FieldGender:
+		return request.Gender != ""
+	case FieldInterests:
+		return len(request.Interests) > 0
+	case FieldPriceRange:
+		return request.PriceRange.Min > 0 || request.PriceRange.Max > 0
+	}
+	return false
+}
+
+func (s *Session) skipped(field string) bool {
+	for _, f := range s.Skipped {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Store хранит сессии. Put записывает сессию, только если ее версия в
+// хранилище равна expectedVersion (0 - сессии еще нет), иначе ErrConflict.
+type Store interface {
+	Get(ctx context.Context, id string) (*Session, error)
+	Put(ctx context.Context, session *Session, expectedVersion int) error
+}
+
+// NewStoreFromEnv выбирает хранилище по SESSION_BACKEND и SESSION_TABLE
+// (по умолчанию advisor_sessions), см. versioned.FromEnv
+func NewStoreFromEnv(dynamoClient *dynamodb.Client) (Store, error) {
+	return versioned.FromEnv("SESSION", "advisor_sessions",
+		func(table string) Store { return NewDynamoStore(dynamoClient, table) },
+		func() Store { return NewMemoryStore() })
+}
+
+// LoadTTL читает SESSION_TTL (по умолчанию 24h)
+func LoadTTL() (time.Duration, error) {
+	v := os.Getenv("SESSION_TTL")
+	if v == "" {
+		return defaultTTL, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid SESSION_TTL: %w", err)
+	}
+	return d, nil
+}
+
+// NewID возвращает случайный идентификатор сессии
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,58 @@
+package stemmer
+
+import (
+	"sort"
+	"strings"
+)
+
+// Минимальная длина основы: короче суффиксы уже не отрезаются
+const kazakhMinStem = 3
+
+// Аффиксы казахского языка в порядке отрезания с конца слова:
+// падеж, затем принадлежность, затем множественное число
+var (
+	kkCase = kkAffixes(
+		"ның", "нің", "дың", "дің", "тың", "тің",
+		"ға", "ге", "қа", "ке", "на", "не", "а", "е",
+		"ны", "ні", "ды", "ді", "ты", "ті",
+		"да", "де", "та", "те", "нда", "нде",
+		"дан", "ден", "тан", "тен", "нан", "нен",
+		"мен", "бен", "пен",
+	)
+	kkPossessive = kkAffixes(
+		"ым", "ім", "м", "ың", "ің", "ң", "сы", "сі", "ы", "і",
+		"ымыз", "іміз", "мыз", "міз", "ыңыз", "іңіз", "ңыз", "ңіз",
+	)
+	kkPlural = kkAffixes("лар", "лер", "дар", "дер", "тар", "тер")
+)
+
+func kkAffixes(affixes ...string) []string {
+	sort.SliceStable(affixes, func(i, j int) bool {
+		return len([]rune(affixes[i])) > len([]rune(affixes[j]))
+	})
+	return affixes
+}
+
+// Kazakh отрезает падежные, притяжательные аффиксы и аффиксы
+// множественного числа: "кітаптарымызға" -> "кітап"
+func Kazakh(word string) string {
+	word = strings.ToLower(word)
+	for _, affixes := range [][]string{kkCase, kkPossessive, kkPlural} {
+		word = kkStrip(word, affixes)
+	}
+	return word
+}
+
+func kkStrip(word string, affixes []string) string {
+	length := len([]rune(word))
+	for _, affix := range affixes {
+		if !strings.HasSuffix(word, affix) {
+			continue
+		}
+		if length-len([]rune(affix)) < kazakhMinStem {
+			continue
+		}
+		return strings.TrimSuffix(word, affix)
+	}
+	return word
+}
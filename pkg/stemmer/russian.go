@@ -0,0 +1,152 @@
+package stemmer
+
+import (
+	"sort"
+	"strings"
+)
+
+// Окончания алгоритма Snowball для русского языка. Группы "после а/я"
+// отрезаются, только если перед окончанием стоит а или я.
+var (
+	ruPerfectiveGerund1 = ruEndings("в", "вши", "вшись")
+	ruPerfectiveGerund2 = ruEndings("ив", "ивши", "ившись", "ыв", "ывши", "ывшись")
+	ruAdjective         = ruEndings("ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом", "его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею")
+	ruParticiple1       = ruEndings("ем", "нн", "вш", "ющ", "щ")
+	ruParticiple2       = ruEndings("ивш", "ывш", "ующ")
+	ruReflexive         = ruEndings("ся", "сь")
+	ruVerb1             = ruEndings("ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно")
+	ruVerb2             = ruEndings("ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен", "ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю")
+	ruNoun              = ruEndings("а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й", "иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я")
+	ruSuperlative       = ruEndings("ейш", "ейше")
+	ruDerivational      = ruEndings("ост", "ость")
+)
+
+// ruEndings сортирует окончания по убыванию длины, чтобы находить самое длинное
+func ruEndings(endings ...string) [][]rune {
+	result := make([][]rune, len(endings))
+	for i, ending := range endings {
+		result[i] = []rune(ending)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i]) > len(result[j])
+	})
+	return result
+}
+
+func isRuVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
+
+// Russian возвращает основу русского слова по алгоритму Snowball (Портера)
+func Russian(word string) string {
+	w := []rune(strings.ReplaceAll(strings.ToLower(word), "ё", "е"))
+
+	rv, r2 := ruRegions(w)
+	if rv >= len(w) {
+		return string(w)
+	}
+
+	// Шаг 1: деепричастие, иначе возвратная частица и одно из окончаний
+	if n, ok := ruStrip(w, rv, ruPerfectiveGerund1, true); ok {
+		w = w[:n]
+	} else if n, ok := ruStrip(w, rv, ruPerfectiveGerund2, false); ok {
+		w = w[:n]
+	} else {
+		if n, ok := ruStrip(w, rv, ruReflexive, false); ok {
+			w = w[:n]
+		}
+		if n, ok := ruStrip(w, rv, ruAdjective, false); ok {
+			w = w[:n]
+			if n, ok := ruStrip(w, rv, ruParticiple1, true); ok {
+				w = w[:n]
+			} else if n, ok := ruStrip(w, rv, ruParticiple2, false); ok {
+				w = w[:n]
+			}
+		} else if n, ok := ruStrip(w, rv, ruVerb1, true); ok {
+			w = w[:n]
+		} else if n, ok := ruStrip(w, rv, ruVerb2, false); ok {
+			w = w[:n]
+		} else if n, ok := ruStrip(w, rv, ruNoun, false); ok {
+			w = w[:n]
+		}
+	}
+
+	// Шаг 2: конечное "и"
+	if len(w) > rv && w[len(w)-1] == 'и' {
+		w = w[:len(w)-1]
+	}
+
+	// Шаг 3: словообразовательный суффикс в R2
+	if n, ok := ruStrip(w, r2, ruDerivational, false); ok {
+		w = w[:n]
+	}
+
+	// Шаг 4: превосходная степень, двойное "н" и мягкий знак
+	if n, ok := ruStrip(w, rv, ruSuperlative, false); ok {
+		w = w[:n]
+	}
+	switch {
+	case len(w)-2 >= rv && len(w) >= 2 && w[len(w)-1] == 'н' && w[len(w)-2] == 'н':
+		w = w[:len(w)-1]
+	case len(w) > rv && w[len(w)-1] == 'ь':
+		w = w[:len(w)-1]
+	}
+
+	return string(w)
+}
+
+// ruRegions возвращает начало RV (после первой гласной) и R2
+func ruRegions(w []rune) (rv, r2 int) {
+	rv, r1 := len(w), len(w)
+	r2 = len(w)
+	for i := 0; i < len(w); i++ {
+		if isRuVowel(w[i]) {
+			rv = i + 1
+			break
+		}
+	}
+	for i := 1; i < len(w); i++ {
+		if !isRuVowel(w[i]) && isRuVowel(w[i-1]) {
+			r1 = i + 1
+			break
+		}
+	}
+	for i := r1 + 1; i < len(w); i++ {
+		if !isRuVowel(w[i]) && isRuVowel(w[i-1]) {
+			r2 = i + 1
+			break
+		}
+	}
+	return rv, r2
+}
+
+// ruStrip ищет самое длинное окончание внутри региона и возвращает новую
+// длину слова. afterA требует, чтобы перед окончанием стояла а или я.
+func ruStrip(w []rune, region int, endings [][]rune, afterA bool) (int, bool) {
+	for _, ending := range endings {
+		start := len(w) - len(ending)
+		if start < region || !hasRuneSuffix(w, ending) {
+			continue
+		}
+		if afterA {
+			if start-1 < region || (w[start-1] != 'а' && w[start-1] != 'я') {
+				continue
+			}
+		}
+		return start, true
+	}
+	return 0, false
+}
+
+func hasRuneSuffix(w, suffix []rune) bool {
+	if len(suffix) > len(w) {
+		return false
+	}
+	offset := len(w) - len(suffix)
+	for i, r := range suffix {
+		if w[offset+i] != r {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package stemmer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Буквы, которые есть в казахском алфавите, но не в русском
+const kazakhLetters = "әғқңөұүһі"
+
+var stopWords = map[string]bool{
+	// ru
+	"и": true, "в": true, "во": true, "на": true, "для": true, "с": true, "со": true, "из": true,
+	"по": true, "к": true, "о": true, "от": true, "до": true, "или": true, "а": true, "не": true,
+	// kk
+	"және": true, "мен": true, "үшін": true, "бен": true, "пен": true,
+	// en
+	"the": true, "and": true, "for": true, "with": true, "of": true, "a": true, "an": true, "or": true,
+}
+
+// Stem возвращает основу слова. Язык определяется по алфавиту: латиница
+// обрабатывается как английский, кириллица с казахскими буквами - как
+// казахский, остальная кириллица - как русский.
+func Stem(word string) string {
+	word = strings.ToLower(word)
+	switch {
+	case strings.ContainsAny(word, kazakhLetters):
+		return Kazakh(word)
+	case isCyrillic(word):
+		return Russian(word)
+	default:
+		return English(word)
+	}
+}
+
+// Tokens разбивает текст на слова и возвращает их основы без стоп-слов.
+// Порядок сохраняется, повторы отбрасываются.
+func Tokens(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var tokens []string
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if stopWords[field] {
+			continue
+		}
+		stem := Stem(field)
+		if stem == "" || seen[stem] {
+			continue
+		}
+		seen[stem] = true
+		tokens = append(tokens, stem)
+	}
+	return tokens
+}
+
+func isCyrillic(word string) bool {
+	for _, r := range word {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// English отбрасывает окончания множественного числа
+func English(word string) string {
+	word = strings.ToLower(word)
+	n := len(word)
+	switch {
+	case n > 4 && strings.HasSuffix(word, "ies"):
+		return word[:n-3] + "y"
+	case n > 4 && (strings.HasSuffix(word, "ches") || strings.HasSuffix(word, "shes") || strings.HasSuffix(word, "xes")):
+		return word[:n-2]
+	case n > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		return word[:n-1]
+	}
+	return word
+}
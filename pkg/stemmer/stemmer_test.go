@@ -0,0 +1,51 @@
+package stemmer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		// ru
+		{"наушники", "наушник"},
+		{"Наушников", "наушник"},
+		{"книгами", "книг"},
+		{"игрушками", "игрушк"},
+		{"красивая", "красив"},
+		{"Ёлочные", "елочн"},
+		// kk
+		{"кітаптарымызға", "кітап"},
+		{"кітапқа", "кітап"},
+		{"балалардың", "бала"},
+		{"ойыншықтар", "ойыншық"},
+		// en
+		{"headphones", "headphone"},
+		{"boxes", "box"},
+		{"kitties", "kitty"},
+		{"glass", "glass"},
+	}
+	for _, tt := range tests {
+		if got := Stem(tt.word); got != tt.want {
+			t.Errorf("Stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestKazakhKeepsShortStem(t *testing.T) {
+	// Основа короче трех букв не укорачивается
+	if got := Kazakh("ана"); got != "ана" {
+		t.Errorf("Kazakh(ана) = %q, want ана", got)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	got := Tokens("Наушники и наушник для кітаптарымызға, книги")
+	want := []string{"наушник", "кітап", "книг"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens() = %q, want %q", got, want)
+	}
+}
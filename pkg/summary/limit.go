@@ -0,0 +1,64 @@
+package summary
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	urlPattern      = regexp.MustCompile(`https?://\S+`)
+	markdownPattern = regexp.MustCompile("[*_#`>|~\\[\\]]")
+)
+
+// ForSpeech готовит текст к озвучке: убирает ссылки, разметку, эмодзи
+// и лишние пробелы
+func ForSpeech(text string) string {
+	text = urlPattern.ReplaceAllString(text, "")
+	text = markdownPattern.ReplaceAllString(text, "")
+	text = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.So, r) || unicode.Is(unicode.Cs, r) || r == '️' {
+			return -1
+		}
+		return r
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Truncate ограничивает текст maxChars символами. Резать стараемся по концу
+// предложения, затем по слову, чтобы озвучка не обрывалась на полуслове.
+func Truncate(text string, maxChars int) string {
+	runes := []rune(text)
+	if maxChars <= 0 || len(runes) <= maxChars {
+		return text
+	}
+
+	cut := string(runes[:maxChars])
+	if i := sentenceEnd(cut); i > 0 && i >= len(cut)/2 {
+		return cut[:i+1]
+	}
+	// Оставляем место под многоточие, чтобы не превысить лимит
+	if maxChars > 3 {
+		cut = string(runes[:maxChars-3])
+	}
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:-") + "..."
+}
+
+// sentenceEnd возвращает позицию последнего конца предложения. Точка после
+// цифры ("1. Наушники") считается номером пункта, а не концом предложения.
+func sentenceEnd(text string) int {
+	for i := len(text) - 1; i > 0; i-- {
+		switch text[i] {
+		case '!', '?':
+			return i
+		case '.':
+			if text[i-1] < '0' || text[i-1] > '9' {
+				return i
+			}
+		}
+	}
+	return -1
+}
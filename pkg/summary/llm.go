@@ -0,0 +1,141 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+)
+
+// Prompt - запрос к языковой модели
+type Prompt struct {
+	System    string
+	User      string
+	MaxTokens int
+}
+
+// LLMClient - языковая модель, которая переписывает резюме живым текстом
+type LLMClient interface {
+	Complete(ctx context.Context, prompt Prompt) (string, error)
+}
+
+// OpenAIClient работает с любым OpenAI-совместимым API /chat/completions
+type OpenAIClient struct {
+	client   httpclient.Doer
+	endpoint string
+	apiKey   string
+	model    string
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// NewOpenAIClient создает клиент; baseURL - адрес API без /chat/completions
+func NewOpenAIClient(client httpclient.Doer, baseURL, apiKey, model string) *OpenAIClient {
+	return &OpenAIClient{
+		client:   client,
+		endpoint: strings.TrimRight(baseURL, "/") + "/chat/completions",
+		apiKey:   apiKey,
+		model:    model,
+	}
+}
+
+func (c *OpenAIClient) Complete(ctx context.Context, prompt Prompt) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: prompt.System},
+			{Role: "user", Content: prompt.User},
+		},
+		MaxTokens:   prompt.MaxTokens,
+		Temperature: 0.4,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("LLM API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var chat chatResponse
+	if err := json.Unmarshal(respBody, &chat); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(chat.Choices) == 0 || strings.TrimSpace(chat.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("LLM API returned no text")
+	}
+	return chat.Choices[0].Message.Content, nil
+}
+
+// StubClient - локальная заглушка: возвращает заданный ответ или ошибку и
+// запоминает запросы. Нужна для тестов и локального запуска без ключа.
+type StubClient struct {
+	Response string
+	Err      error
+
+	mu      sync.Mutex
+	prompts []Prompt
+}
+
+func (c *StubClient) Complete(ctx context.Context, prompt Prompt) (string, error) {
+	c.mu.Lock()
+	c.prompts = append(c.prompts, prompt)
+	c.mu.Unlock()
+
+	if c.Err != nil {
+		return "", c.Err
+	}
+	if c.Response == "" {
+		// Без заданного ответа возвращаем черновик из шаблона как есть
+		return prompt.User[strings.LastIndex(prompt.User, "\n")+1:], nil
+	}
+	return c.Response, nil
+}
+
+// Prompts возвращает запросы, полученные заглушкой
+func (c *StubClient) Prompts() []Prompt {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Prompt(nil), c.prompts...)
+}
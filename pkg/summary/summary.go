@@ -0,0 +1,315 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/httpclient"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/taxonomy"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Polly (neural) синтезирует не больше 3000 символов за запрос
+const PollyMaxChars = 3000
+
+// Options - ограничения резюме
+type Options struct {
+	MaxChars    int           // Длина резюме; текст обрезается по границе предложения
+	TopProducts int           // Сколько товаров называть
+	MaxTitle    int           // Длина названия товара
+	LLMTimeout  time.Duration // Сколько ждать модель перед откатом на шаблон
+}
+
+var DefaultOptions = Options{
+	MaxChars:    1000,
+	TopProducts: 3,
+	MaxTitle:    60,
+	LLMTimeout:  5 * time.Second,
+}
+
+// Input - данные для резюме
+type Input struct {
+	Request    types.GiftRequest
+	Products   []types.Product            // В порядке показа пользователю
+	Total      int                        // Сколько найдено всего; 0 - len(Products)
+	Categories []types.CategorySuggestion // Подобранные категории (необязательно)
+	Currency   string                     // Валюта цен товаров (по умолчанию KZT)
+}
+
+// Generator пишет текстовое резюме рекомендаций. Без LLM используется
+// только шаблон; с LLM шаблон служит черновиком и запасным вариантом.
+type Generator struct {
+	templates map[string]*template.Template
+	llm       LLMClient
+	options   Options
+	rates     money.RateTable
+}
+
+// New создает генератор; llm может быть nil. Цены товаров переводятся в
+// валюту запроса по money.DefaultRates, пока не вызван SetRates
+func New(llm LLMClient, options Options) (*Generator, error) {
+	templates, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+	if options.MaxChars <= 0 || options.MaxChars > PollyMaxChars {
+		options.MaxChars = PollyMaxChars
+	}
+	if options.TopProducts <= 0 {
+		options.TopProducts = DefaultOptions.TopProducts
+	}
+	if options.MaxTitle <= 0 {
+		options.MaxTitle = DefaultOptions.MaxTitle
+	}
+	return &Generator{templates: templates, llm: llm, options: options, rates: money.DefaultRates}, nil
+}
+
+// SetRates задает курсы для перевода цен товаров в валюту запроса
+func (g *Generator) SetRates(rates money.RateTable) {
+	g.rates = rates
+}
+
+// NewFromEnv настраивает генератор по SUMMARY_BACKEND (template или llm),
+// LLM_API_URL, LLM_API_KEY, LLM_MODEL, LLM_TIMEOUT и SUMMARY_MAX_CHARS;
+// курсы валют - money.LoadRates
+func NewFromEnv() (*Generator, error) {
+	options := DefaultOptions
+	if v := os.Getenv("SUMMARY_MAX_CHARS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SUMMARY_MAX_CHARS: %w", err)
+		}
+		options.MaxChars = n
+	}
+	if v := os.Getenv("LLM_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_TIMEOUT: %w", err)
+		}
+		options.LLMTimeout = d
+	}
+
+	var llm LLMClient
+	switch backend := os.Getenv("SUMMARY_BACKEND"); backend {
+	case "", "template":
+	case "llm":
+		baseURL := os.Getenv("LLM_API_URL")
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
+		model := os.Getenv("LLM_MODEL")
+		if model == "" {
+			model = "gpt-4o-mini"
+		}
+		llm = NewOpenAIClient(httpclient.Default(), baseURL, os.Getenv("LLM_API_KEY"), model)
+	case "stub":
+		llm = &StubClient{}
+	default:
+		return nil, fmt.Errorf("unknown SUMMARY_BACKEND: %s", backend)
+	}
+
+	generator, err := New(llm, options)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := money.LoadRates()
+	if err != nil {
+		fmt.Printf("Failed to load currency rates, using defaults: %v\n", err)
+		rates = money.DefaultRates
+	}
+	generator.SetRates(rates)
+	return generator, nil
+}
+
+// Summarize возвращает резюме на языке запроса (ru по умолчанию). Ошибка
+// LLM не прерывает работу: возвращается текст из шаблона.
+func (g *Generator) Summarize(ctx context.Context, input Input) (string, error) {
+	lang := input.Request.Language
+	if _, ok := g.templates[lang]; !ok {
+		lang = "ru"
+	}
+
+	draft, err := render(g.templates[lang], g.templateData(input, lang))
+	if err != nil {
+		return "", fmt.Errorf("failed to render summary: %w", err)
+	}
+	draft = ForSpeech(draft)
+
+	if g.llm != nil {
+		llmCtx, cancel := context.WithTimeout(ctx, g.options.LLMTimeout)
+		text, err := g.llm.Complete(llmCtx, g.prompt(lang, draft))
+		cancel()
+		if err != nil {
+			fmt.Printf("LLM summary failed, using template: %v\n", err)
+		} else if spoken := ForSpeech(text); spoken != "" {
+			return Truncate(spoken, g.options.MaxChars), nil
+		} else {
+			// Пустой ответ или только ссылки и эмодзи - озвучивать нечего
+			fmt.Printf("LLM returned empty summary, using template\n")
+		}
+	}
+
+	return Truncate(draft, g.options.MaxChars), nil
+}
+
+var promptLanguages = map[string]string{"ru": "русском", "en": "английском", "kk": "казахском"}
+
+func (g *Generator) prompt(lang, draft string) Prompt {
+	return Prompt{
+		System: fmt.Sprintf("Ты помощник по выбору подарков. Перепиши черновик дружелюбно и естественно на %s языке. "+
+			"Используй только факты из черновика, не придумывай товары и цены. "+
+			"Текст будет озвучен: без ссылок, эмодзи, списков и разметки, не длиннее %d символов.",
+			promptLanguages[lang], g.options.MaxChars),
+		// Черновик всегда последней строкой
+		User:      "Черновик:\n" + draft,
+		MaxTokens: g.options.MaxChars / 2,
+	}
+}
+
+type templateProduct struct {
+	N     int
+	Title string
+	Price string
+	Store string
+}
+
+type templateData struct {
+	Occasion   string
+	Gender     string
+	Age        int
+	Total      int
+	Min        string
+	Max        string
+	Currency   string
+	Top        []templateProduct
+	Categories []string
+}
+
+func (g *Generator) templateData(input Input, lang string) templateData {
+	t := taxonomy.Current()
+	request := input.Request
+
+	currency := input.Currency
+	if currency == "" {
+		currency = money.KZT
+	}
+	data := templateData{
+		Age:      request.Age,
+		Total:    input.Total,
+		Currency: currencyWord(lang, currency),
+	}
+	if data.Total == 0 {
+		data.Total = len(input.Products)
+	}
+
+	if occasion, ok := t.Occasions[strings.ToLower(request.Occasion)]; ok {
+		data.Occasion = localized(occasion.Names, lang)
+	}
+	if gender, ok := t.ResolveGender(request.Gender); ok {
+		data.Gender = strings.ToLower(localized(t.Genders[gender].Names, lang))
+	}
+	if request.PriceRange.Min > 0 {
+		data.Min = formatAmount(request.PriceRange.Min)
+	}
+	if request.PriceRange.Max > 0 {
+		data.Max = formatAmount(request.PriceRange.Max)
+	}
+
+	for i, product := range input.Products {
+		if i == g.options.TopProducts {
+			break
+		}
+		item := templateProduct{
+			N:     i + 1,
+			Title: shortTitle(product.Title, g.options.MaxTitle),
+			Store: storeName(product.Store),
+		}
+		if price, ok := g.price(product, currency); ok {
+			item.Price = formatAmount(price)
+		}
+		data.Top = append(data.Top, item)
+	}
+
+	for _, suggestion := range input.Categories {
+		if len(data.Categories) == 3 {
+			break
+		}
+		data.Categories = append(data.Categories, t.DisplayName(suggestion.Category, lang))
+	}
+
+	return data
+}
+
+// price переводит цену товара в валюту резюме. Если курса нет, цену лучше
+// не называть, чем назвать в чужой валюте.
+func (g *Generator) price(product types.Product, currency string) (float64, bool) {
+	if product.Price <= 0 {
+		return 0, false
+	}
+	from := product.Currency
+	if from == "" {
+		from = money.StoreCurrency(product.Store)
+	}
+	price, err := g.rates.Convert(product.Price, from, currency)
+	if err != nil {
+		return 0, false
+	}
+	return price, true
+}
+
+func localized(names map[string]string, lang string) string {
+	if name := names[lang]; name != "" {
+		return name
+	}
+	return names["ru"]
+}
+
+func currencyWord(lang, currency string) string {
+	if word, ok := currencyWords[lang][currency]; ok {
+		return word
+	}
+	return currency
+}
+
+// formatAmount округляет цену до целого: дробные тиыны в озвучке не нужны
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(math.Round(v), 'f', 0, 64)
+}
+
+// storeName возвращает название магазина для озвучки; внутренние
+// источники не называем
+func storeName(store string) string {
+	switch store {
+	case "", "dynamodb", "unknown":
+		return ""
+	}
+	runes := []rune(store)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// shortTitle обрезает название по границе слова
+func shortTitle(title string, limit int) string {
+	title = strings.Join(strings.Fields(title), " ")
+	runes := []rune(title)
+	if len(runes) <= limit {
+		return title
+	}
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	// Не заканчиваем название предлогом или союзом
+	if i := strings.LastIndex(cut, " "); i > 0 && len([]rune(cut[i+1:])) <= 2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:-")
+}
@@ -0,0 +1,248 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/money"
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+func testInput(lang string) Input {
+	return Input{
+		Request: types.GiftRequest{
+			Occasion:   "birthday",
+			Gender:     "female",
+			Age:        32,
+			PriceRange: types.Range{Min: 10000, Max: 50000},
+			Language:   lang,
+		},
+		Products: []types.Product{
+			{Title: "Наушники Apple AirPods Pro 2", Price: 45990.4, Store: "kaspi"},
+			{Title: "Плед флисовый", Price: 12500, Store: "dynamodb"},
+			{Title: "Кофемашина", Price: 0, Store: "ozon"},
+			{Title: "Не попадет в резюме", Price: 1000, Store: "kaspi"},
+		},
+		Total:      12,
+		Categories: []types.CategorySuggestion{{Category: "electronics"}},
+	}
+}
+
+func TestSummarizeTemplates(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{
+			lang: "ru",
+			want: "Повод: День рождения. Получатель: женщина, 32 года. Подобрали 12 вариантов в бюджете от 10000 до 50000 тенге. " +
+				"Лучшие варианты: 1. Наушники Apple AirPods Pro 2 за 45990 тенге, Kaspi. 2. Плед флисовый за 12500 тенге. 3. Кофемашина, Ozon. " +
+				"Больше всего подходят категории: Электроника.",
+		},
+		{
+			lang: "en",
+			want: "Occasion: Birthday. Recipient: female, 32 years old. We found 12 options from 10000 to 50000 tenge. " +
+				"Top picks: 1. Наушники Apple AirPods Pro 2 for 45990 tenge at Kaspi. 2. Плед флисовый for 12500 tenge. 3. Кофемашина at Ozon. " +
+				"Best-matching categories: Electronics.",
+		},
+		{
+			lang: "kk",
+			want: "Мереке: Туған күн. Алушы: әйел, 32 жаста. 12 нұсқа таптық, бағасы 10000 - 50000 теңге. " +
+				"Үздік нұсқалар: 1. Наушники Apple AirPods Pro 2, 45990 теңге, Kaspi. 2. Плед флисовый, 12500 теңге. 3. Кофемашина, Ozon. " +
+				"Ең қолайлы санаттар: Электроника.",
+		},
+	}
+
+	generator, err := New(nil, DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			got, err := generator.Summarize(context.Background(), testInput(tt.lang))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Summarize():\ngot:  %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeConvertsProductPrices(t *testing.T) {
+	generator, err := New(nil, DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := Input{
+		Request: types.GiftRequest{Language: "ru"},
+		Products: []types.Product{
+			{Title: "Кружка", Price: 1500, Currency: money.RUB, Store: "ozon"},
+			{Title: "Лампа", Price: 20, Store: "aliexpress"},
+			{Title: "Шарф", Price: 30, Currency: "GBP", Store: "kaspi"},
+		},
+		Currency: money.KZT,
+	}
+
+	got, err := generator.Summarize(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Валюта товара берется из Currency, без нее - по магазину; цену в
+	// валюте без курса не называем
+	want := "Лучшие варианты: 1. Кружка за 8400 тенге, Ozon. 2. Лампа за 9600 тенге, Aliexpress. 3. Шарф, Kaspi."
+	if !strings.Contains(got, want) {
+		t.Errorf("Summarize():\ngot:  %s\nwant: ...%s...", got, want)
+	}
+
+	generator.SetRates(money.RateTable{Base: money.KZT, Rates: map[string]float64{money.KZT: 1, money.RUB: 5}})
+	input.Currency = money.RUB
+	input.Products = input.Products[:1]
+	got, err = generator.Summarize(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "Кружка за 1500 рублей") {
+		t.Errorf("Summarize() = %q, want price in request currency", got)
+	}
+}
+
+func TestSummarizeUnknownLanguageFallsBackToRussian(t *testing.T) {
+	generator, err := New(nil, DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generator.Summarize(context.Background(), Input{Request: types.GiftRequest{Language: "de"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "К сожалению, по этим параметрам ничего не нашлось.") {
+		t.Errorf("Summarize() = %q, want russian empty result", got)
+	}
+}
+
+func TestSummarizeLLM(t *testing.T) {
+	template, err := New(nil, DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	draft, err := template.Summarize(context.Background(), testInput("ru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		stub *StubClient
+		want string
+	}{
+		{
+			name: "llm text prepared for speech",
+			stub: &StubClient{Response: "**Отличный выбор!** 🎁 Наушники: https://kaspi.kz/p/1 ждут маму."},
+			want: "Отличный выбор! Наушники: ждут маму.",
+		},
+		{name: "error falls back to template", stub: &StubClient{Err: errors.New("timeout")}, want: draft},
+		{name: "empty text falls back to template", stub: &StubClient{Response: "🎁 https://kaspi.kz"}, want: draft},
+		{name: "draft echoed by stub", stub: &StubClient{}, want: draft},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator, err := New(tt.stub, DefaultOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := generator.Summarize(context.Background(), testInput("ru"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Summarize():\ngot:  %s\nwant: %s", got, tt.want)
+			}
+
+			prompts := tt.stub.Prompts()
+			if len(prompts) != 1 {
+				t.Fatalf("prompts = %d, want 1", len(prompts))
+			}
+			if !strings.HasSuffix(prompts[0].User, "\n"+draft) {
+				t.Errorf("prompt must end with the template draft: %q", prompts[0].User)
+			}
+			if !strings.Contains(prompts[0].System, "русском") {
+				t.Errorf("prompt language: %q", prompts[0].System)
+			}
+		})
+	}
+}
+
+func TestSummarizeMaxChars(t *testing.T) {
+	stub := &StubClient{Response: strings.Repeat("Очень длинное предложение о подарке. ", 200)}
+	generator, err := New(stub, Options{MaxChars: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generator.Summarize(context.Background(), testInput("ru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := utf8.RuneCountInString(got); n > 100 {
+		t.Errorf("len = %d, want <= 100", n)
+	}
+	if !strings.HasSuffix(got, ".") {
+		t.Errorf("summary must end on a sentence: %q", got)
+	}
+	if prompts := stub.Prompts(); prompts[0].MaxTokens != 50 {
+		t.Errorf("MaxTokens = %d, want 50", prompts[0].MaxTokens)
+	}
+}
+
+func TestNewLimitsMaxCharsToPolly(t *testing.T) {
+	for _, maxChars := range []int{0, -1, PollyMaxChars + 1} {
+		generator, err := New(nil, Options{MaxChars: maxChars})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if generator.options.MaxChars != PollyMaxChars {
+			t.Errorf("MaxChars(%d) = %d, want %d", maxChars, generator.options.MaxChars, PollyMaxChars)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxChars int
+		want     string
+	}{
+		{name: "short text unchanged", text: "Короткий текст.", maxChars: 100, want: "Короткий текст."},
+		{name: "no limit", text: "Любой текст", maxChars: 0, want: "Любой текст"},
+		{name: "cut at sentence end", text: "Первое предложение. Второе предложение длиннее.", maxChars: 30, want: "Первое предложение."},
+		{name: "item number is not sentence end", text: "Лучшие: 1. Наушники и колонка для дома", maxChars: 25, want: "Лучшие: 1. Наушники и..."},
+		{name: "cut at word", text: "Одно очень длинное предложение без точек", maxChars: 20, want: "Одно очень..."},
+		{name: "tiny limit", text: "Наушники", maxChars: 3, want: "Нау..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.text, tt.maxChars); got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.text, tt.maxChars, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForSpeech(t *testing.T) {
+	tests := map[string]string{
+		"Смотрите https://kaspi.kz/shop/p/1 тут": "Смотрите тут",
+		"**Жирный** _курсив_ `код` # заголовок":  "Жирный курсив код заголовок",
+		"Подарок 🎁 готов ✨":                      "Подарок готов",
+		"  много   пробелов\n\nи строк  ":        "много пробелов и строк",
+	}
+	for text, want := range tests {
+		if got := ForSpeech(text); got != want {
+			t.Errorf("ForSpeech(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
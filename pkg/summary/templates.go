@@ -0,0 +1,82 @@
+package summary
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Шаблоны резюме по языкам. Текст озвучивается через Polly, поэтому без
+// ссылок, эмодзи и разметки, цены - числами со словом валюты.
+var templateSources = map[string]string{
+	"ru": `{{if .Occasion}}Повод: {{.Occasion}}. {{end}}` +
+		`{{if .Gender}}Получатель: {{.Gender}}{{if .Age}}, {{.Age}} {{plural .Age "год" "года" "лет"}}{{end}}. {{else if .Age}}Получателю {{.Age}} {{plural .Age "год" "года" "лет"}}. {{end}}` +
+		`{{if .Total}}Подобрали {{.Total}} {{plural .Total "вариант" "варианта" "вариантов"}}` +
+		`{{if and .Min .Max}} в бюджете от {{.Min}} до {{.Max}} {{.Currency}}{{else if .Max}} в бюджете до {{.Max}} {{.Currency}}{{else if .Min}} дороже {{.Min}} {{.Currency}}{{end}}. ` +
+		`{{if .Top}}Лучшие варианты: {{range .Top}}{{.N}}. {{.Title}}{{if .Price}} за {{.Price}} {{$.Currency}}{{end}}{{if .Store}}, {{.Store}}{{end}}. {{end}}{{end}}` +
+		`{{if .Categories}}Больше всего подходят категории: {{join .Categories ", "}}.{{end}}` +
+		`{{else}}К сожалению, по этим параметрам ничего не нашлось. Попробуйте расширить бюджет или выбрать другие категории.{{end}}`,
+
+	"en": `{{if .Occasion}}Occasion: {{.Occasion}}. {{end}}` +
+		`{{if .Gender}}Recipient: {{.Gender}}{{if .Age}}, {{.Age}} years old{{end}}. {{else if .Age}}The recipient is {{.Age}} years old. {{end}}` +
+		`{{if .Total}}We found {{.Total}} {{if eq .Total 1}}option{{else}}options{{end}}` +
+		`{{if and .Min .Max}} from {{.Min}} to {{.Max}} {{.Currency}}{{else if .Max}} up to {{.Max}} {{.Currency}}{{else if .Min}} from {{.Min}} {{.Currency}}{{end}}. ` +
+		`{{if .Top}}Top picks: {{range .Top}}{{.N}}. {{.Title}}{{if .Price}} for {{.Price}} {{$.Currency}}{{end}}{{if .Store}} at {{.Store}}{{end}}. {{end}}{{end}}` +
+		`{{if .Categories}}Best-matching categories: {{join .Categories ", "}}.{{end}}` +
+		`{{else}}Unfortunately, nothing matched these preferences. Try a wider budget or other categories.{{end}}`,
+
+	"kk": `{{if .Occasion}}Мереке: {{.Occasion}}. {{end}}` +
+		`{{if .Gender}}Алушы: {{.Gender}}{{if .Age}}, {{.Age}} жаста{{end}}. {{else if .Age}}Алушы {{.Age}} жаста. {{end}}` +
+		`{{if .Total}}{{.Total}} нұсқа таптық` +
+		`{{if and .Min .Max}}, бағасы {{.Min}} - {{.Max}} {{.Currency}}{{else if .Max}}, бағасы {{.Max}} {{.Currency}} дейін{{else if .Min}}, бағасы {{.Min}} {{.Currency}} бастап{{end}}. ` +
+		`{{if .Top}}Үздік нұсқалар: {{range .Top}}{{.N}}. {{.Title}}{{if .Price}}, {{.Price}} {{$.Currency}}{{end}}{{if .Store}}, {{.Store}}{{end}}. {{end}}{{end}}` +
+		`{{if .Categories}}Ең қолайлы санаттар: {{join .Categories ", "}}.{{end}}` +
+		`{{else}}Өкінішке орай, бұл параметрлер бойынша ештеңе табылмады. Бюджетті кеңейтіп немесе басқа санаттарды таңдап көріңіз.{{end}}`,
+}
+
+// Слово валюты для каждого языка; для неизвестной валюты выводится ее код
+var currencyWords = map[string]map[string]string{
+	"ru": {"KZT": "тенге", "RUB": "рублей", "USD": "долларов", "EUR": "евро"},
+	"en": {"KZT": "tenge", "RUB": "rubles", "USD": "dollars", "EUR": "euros"},
+	"kk": {"KZT": "теңге", "RUB": "рубль", "USD": "доллар", "EUR": "еуро"},
+}
+
+var templateFuncs = template.FuncMap{
+	"plural": pluralRu,
+	"join":   strings.Join,
+}
+
+func parseTemplates() (map[string]*template.Template, error) {
+	templates := make(map[string]*template.Template, len(templateSources))
+	for lang, source := range templateSources {
+		t, err := template.New(lang).Funcs(templateFuncs).Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s summary template: %w", lang, err)
+		}
+		templates[lang] = t
+	}
+	return templates, nil
+}
+
+func render(t *template.Template, data interface{}) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// pluralRu выбирает форму слова для числа: 1 год, 2 года, 5 лет
+func pluralRu(n int, one, few, many string) string {
+	n %= 100
+	if n >= 11 && n <= 14 {
+		return many
+	}
+	switch n % 10 {
+	case 1:
+		return one
+	case 2, 3, 4:
+		return few
+	}
+	return many
+}
@@ -0,0 +1,195 @@
+package taxonomy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Как часто теплая Lambda перечитывает источник таксономии
+const defaultReloadInterval = 5 * time.Minute
+
+// Время на чтение источника при перезагрузке
+const reloadTimeout = 3 * time.Second
+
+// Source - откуда загружается документ таксономии
+type Source interface {
+	Load(ctx context.Context) ([]byte, error)
+	Name() string
+}
+
+// FileSource читает таксономию из JSON-файла
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Load(ctx context.Context) ([]byte, error) {
+	return os.ReadFile(s.Path)
+}
+
+func (s FileSource) Name() string { return "file:" + s.Path }
+
+// DynamoSource читает таксономию из атрибута document элемента таблицы
+type DynamoSource struct {
+	Client *dynamodb.Client
+	Table  string
+	ID     string // Значение ключа taxonomy_id
+}
+
+func (s DynamoSource) Load(ctx context.Context) ([]byte, error) {
+	out, err := s.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: &s.Table,
+		Key: map[string]dyntypes.AttributeValue{
+			"taxonomy_id": &dyntypes.AttributeValueMemberS{Value: s.ID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get taxonomy: %w", err)
+	}
+	document, ok := out.Item["document"].(*dyntypes.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf("taxonomy %q not found in %s", s.ID, s.Table)
+	}
+	return []byte(document.Value), nil
+}
+
+func (s DynamoSource) Name() string { return "dynamodb:" + s.Table + "/" + s.ID }
+
+// Store хранит текущую таксономию и перечитывает источник не чаще, чем
+// раз в interval. Перезагрузка ленивая - при обращении, потому что
+// замороженная Lambda не выполняет фоновые горутины. Если новая версия не
+// прошла проверку, остается предыдущая.
+type Store struct {
+	source    Source
+	interval  time.Duration
+	current   atomic.Pointer[Taxonomy]
+	checkedAt atomic.Int64
+	reloading sync.Mutex
+}
+
+// NewStore загружает таксономию из источника; source nil означает встроенную
+func NewStore(ctx context.Context, source Source, interval time.Duration) (*Store, error) {
+	s := &Store{source: source, interval: interval}
+	if source == nil {
+		s.current.Store(Embedded())
+		return s, nil
+	}
+
+	t, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.current.Store(t)
+	s.checkedAt.Store(time.Now().UnixNano())
+	return s, nil
+}
+
+// NewStoreFromEnv выбирает источник по TAXONOMY_TABLE (DynamoDB) или
+// TAXONOMY_FILE; без них используется встроенная таксономия.
+// Интервал перезагрузки задается TAXONOMY_RELOAD_INTERVAL.
+func NewStoreFromEnv(ctx context.Context, dynamoClient *dynamodb.Client) (*Store, error) {
+	interval := defaultReloadInterval
+	if v := os.Getenv("TAXONOMY_RELOAD_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TAXONOMY_RELOAD_INTERVAL: %w", err)
+		}
+		interval = d
+	}
+
+	var source Source
+	switch {
+	case os.Getenv("TAXONOMY_TABLE") != "":
+		if dynamoClient == nil {
+			return nil, fmt.Errorf("TAXONOMY_TABLE requires a DynamoDB client")
+		}
+		id := os.Getenv("TAXONOMY_ID")
+		if id == "" {
+			id = "current"
+		}
+		source = DynamoSource{Client: dynamoClient, Table: os.Getenv("TAXONOMY_TABLE"), ID: id}
+	case os.Getenv("TAXONOMY_FILE") != "":
+		source = FileSource{Path: os.Getenv("TAXONOMY_FILE")}
+	}
+
+	return NewStore(ctx, source, interval)
+}
+
+// Current возвращает актуальную таксономию, при необходимости перечитывая источник
+func (s *Store) Current() *Taxonomy {
+	if s.source != nil && s.interval > 0 && time.Since(time.Unix(0, s.checkedAt.Load())) >= s.interval {
+		s.reload()
+	}
+	return s.current.Load()
+}
+
+// reload перечитывает источник; параллельные вызовы не ждут и получают
+// текущую версию
+func (s *Store) reload() {
+	if !s.reloading.TryLock() {
+		return
+	}
+	defer s.reloading.Unlock()
+	defer s.checkedAt.Store(time.Now().UnixNano())
+
+	ctx, cancel := context.WithTimeout(context.Background(), reloadTimeout)
+	defer cancel()
+
+	t, err := s.load(ctx)
+	if err != nil {
+		fmt.Printf("Taxonomy reload from %s failed, keeping version %s: %v\n", s.source.Name(), s.current.Load().Version, err)
+		return
+	}
+	if old := s.current.Load(); old.Version != t.Version {
+		fmt.Printf("Taxonomy reloaded from %s: %s -> %s\n", s.source.Name(), old.Version, t.Version)
+	}
+	s.current.Store(t)
+}
+
+func (s *Store) load(ctx context.Context) (*Taxonomy, error) {
+	data, err := s.source.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// CategoryName и HasMarketplace позволяют передавать Store туда, где нужна
+// всегда свежая таксономия
+func (s *Store) CategoryName(marketplace, category string) (string, bool) {
+	return s.Current().CategoryName(marketplace, category)
+}
+
+func (s *Store) HasMarketplace(marketplace string) bool {
+	return s.Current().HasMarketplace(marketplace)
+}
+
+var defaultStore atomic.Pointer[Store]
+
+// SetDefault задает хранилище, которое используют пакеты без явной ссылки
+// на таксономию; обычно вызывается в init() Lambda
+func SetDefault(s *Store) {
+	defaultStore.Store(s)
+}
+
+// Default возвращает хранилище по умолчанию; пока SetDefault не вызывался,
+// это встроенная таксономия
+func Default() *Store {
+	if s := defaultStore.Load(); s != nil {
+		return s
+	}
+	s, _ := NewStore(context.Background(), nil, 0)
+	defaultStore.CompareAndSwap(nil, s)
+	return defaultStore.Load()
+}
+
+// Current - таксономия хранилища по умолчанию
+func Current() *Taxonomy {
+	return Default().Current()
+}
@@ -0,0 +1,319 @@
+package taxonomy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Встроенная таксономия используется, если не задан внешний источник
+//
+//go:embed taxonomy.json
+var embedded []byte
+
+// ErrInvalid возвращается, если документ таксономии не прошел проверку
+var ErrInvalid = errors.New("invalid taxonomy")
+
+// Taxonomy - категории подарков, их названия на маркетплейсах и правила
+// подбора по поводу, возрасту и меткам Rekognition
+type Taxonomy struct {
+	Version      string              `json:"version"`
+	Languages    []string            `json:"languages"`
+	Marketplaces []string            `json:"marketplaces"`
+	Categories   map[string]Category `json:"categories"`
+	Occasions    map[string]Occasion `json:"occasions"`
+	AgeGroups    map[string]AgeGroup `json:"age_groups"`
+	Genders      map[string]Gender   `json:"genders"`
+	Scoring      Scoring             `json:"scoring"`
+	Labels       map[string][]string `json:"labels"`
+}
+
+type Category struct {
+	Names        map[string]string `json:"names"`        // Название для пользователя по языкам
+	Marketplaces map[string]string `json:"marketplaces"` // Название в каталоге маркетплейса
+}
+
+type Occasion struct {
+	Names      map[string]string `json:"names"`
+	Aliases    []string          `json:"aliases"` // Как повод называют в свободном тексте
+	Categories []string          `json:"categories"`
+}
+
+// AgeGroup - возрастная группа; MaxAge 0 означает группу без верхней границы
+type AgeGroup struct {
+	MinAge     int      `json:"min_age"`
+	MaxAge     int      `json:"max_age,omitempty"`
+	Categories []string `json:"categories"`
+}
+
+// Gender - пол получателя: синонимы, по которым он распознается во входных
+// данных, и множители веса категорий
+type Gender struct {
+	Names   map[string]string  `json:"names"`
+	Aliases []string           `json:"aliases"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+// Scoring - вклад повода и возрастной группы в вес категории
+type Scoring struct {
+	Occasion float64 `json:"occasion"`
+	AgeGroup float64 `json:"age_group"`
+}
+
+// Embedded возвращает встроенную таксономию
+func Embedded() *Taxonomy {
+	t, err := Parse(embedded)
+	if err != nil {
+		// Встроенный файл проверяется при сборке релиза, сюда попасть нельзя
+		panic(fmt.Sprintf("embedded taxonomy: %v", err))
+	}
+	return t
+}
+
+// Parse разбирает и проверяет документ таксономии
+func Parse(data []byte) (*Taxonomy, error) {
+	var t Taxonomy
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate проверяет, что у каждой категории есть названия на всех языках
+// и во всех маркетплейсах, а поводы, возрастные группы и метки ссылаются
+// только на существующие категории
+func (t *Taxonomy) Validate() error {
+	var problems []string
+	if t.Version == "" {
+		problems = append(problems, "version is empty")
+	}
+	if len(t.Categories) == 0 {
+		problems = append(problems, "no categories")
+	}
+
+	for _, key := range sortedKeys(t.Categories) {
+		category := t.Categories[key]
+		for _, lang := range t.Languages {
+			if category.Names[lang] == "" {
+				problems = append(problems, fmt.Sprintf("category %q has no %s name", key, lang))
+			}
+		}
+		for _, marketplace := range t.Marketplaces {
+			if category.Marketplaces[marketplace] == "" {
+				problems = append(problems, fmt.Sprintf("category %q has no %s mapping", key, marketplace))
+			}
+		}
+	}
+
+	checkRefs := func(owner string, categories []string) {
+		if len(categories) == 0 {
+			problems = append(problems, owner+" has no categories")
+		}
+		for _, category := range categories {
+			if _, ok := t.Categories[category]; !ok {
+				problems = append(problems, fmt.Sprintf("%s references unknown category %q", owner, category))
+			}
+		}
+	}
+	for _, key := range sortedKeys(t.Occasions) {
+		checkRefs(fmt.Sprintf("occasion %q", key), t.Occasions[key].Categories)
+	}
+	for _, key := range sortedKeys(t.AgeGroups) {
+		checkRefs(fmt.Sprintf("age group %q", key), t.AgeGroups[key].Categories)
+	}
+	for _, key := range sortedKeys(t.Labels) {
+		checkRefs(fmt.Sprintf("label %q", key), t.Labels[key])
+	}
+	problems = append(problems, t.checkAgeGroups()...)
+	problems = append(problems, t.checkGenders()...)
+	if t.Scoring.Occasion < 0 || t.Scoring.AgeGroup < 0 {
+		problems = append(problems, "scoring weights must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w %s: %s", ErrInvalid, t.Version, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkAgeGroups проверяет, что возрастные группы не пересекаются
+func (t *Taxonomy) checkAgeGroups() []string {
+	var problems []string
+	keys := sortedKeys(t.AgeGroups)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return t.AgeGroups[keys[i]].MinAge < t.AgeGroups[keys[j]].MinAge
+	})
+	for i, key := range keys {
+		group := t.AgeGroups[key]
+		if group.MaxAge != 0 && group.MaxAge < group.MinAge {
+			problems = append(problems, fmt.Sprintf("age group %q ends before it starts", key))
+		}
+		if i > 0 {
+			prev := t.AgeGroups[keys[i-1]]
+			if prev.MaxAge == 0 || prev.MaxAge >= group.MinAge {
+				problems = append(problems, fmt.Sprintf("age groups %q and %q overlap", keys[i-1], key))
+			}
+		}
+	}
+	return problems
+}
+
+// checkGenders проверяет множители и то, что синоним не ведет к двум полам
+func (t *Taxonomy) checkGenders() []string {
+	var problems []string
+	owners := make(map[string]string)
+	for _, key := range sortedKeys(t.Genders) {
+		gender := t.Genders[key]
+		for _, category := range sortedKeys(gender.Weights) {
+			if _, ok := t.Categories[category]; !ok {
+				problems = append(problems, fmt.Sprintf("gender %q references unknown category %q", key, category))
+			}
+			if gender.Weights[category] <= 0 {
+				problems = append(problems, fmt.Sprintf("gender %q has non-positive weight for %q", key, category))
+			}
+		}
+		for _, alias := range append([]string{key}, gender.Aliases...) {
+			alias = strings.ToLower(alias)
+			if owner, ok := owners[alias]; ok && owner != key {
+				problems = append(problems, fmt.Sprintf("alias %q belongs to genders %q and %q", alias, owner, key))
+			}
+			owners[alias] = key
+		}
+	}
+	return problems
+}
+
+// HasCategory сообщает, известна ли категория
+func (t *Taxonomy) HasCategory(category string) bool {
+	_, ok := t.Categories[category]
+	return ok
+}
+
+// CategoryKeys возвращает ключи всех категорий в алфавитном порядке
+func (t *Taxonomy) CategoryKeys() []string {
+	return sortedKeys(t.Categories)
+}
+
+// HasMarketplace сообщает, есть ли у маркетплейса свои названия категорий
+func (t *Taxonomy) HasMarketplace(marketplace string) bool {
+	for _, m := range t.Marketplaces {
+		if m == marketplace {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryName возвращает название категории в каталоге маркетплейса
+func (t *Taxonomy) CategoryName(marketplace, category string) (string, bool) {
+	name, ok := t.Categories[category].Marketplaces[marketplace]
+	return name, ok && name != ""
+}
+
+// DisplayName возвращает название категории на языке пользователя;
+// для неизвестного языка - русское, для неизвестной категории - ключ
+func (t *Taxonomy) DisplayName(category, lang string) string {
+	names := t.Categories[category].Names
+	if name := names[lang]; name != "" {
+		return name
+	}
+	if name := names["ru"]; name != "" {
+		return name
+	}
+	return category
+}
+
+// Names возвращает все известные названия категории: на всех языках и
+// во всех маркетплейсах, без повторов
+func (t *Taxonomy) Names(category string) []string {
+	c, ok := t.Categories[category]
+	if !ok {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, source := range []map[string]string{c.Names, c.Marketplaces} {
+		for _, key := range sortedKeys(source) {
+			if name := source[key]; name != "" && !seen[strings.ToLower(name)] {
+				seen[strings.ToLower(name)] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// OccasionKeys возвращает ключи поводов в алфавитном порядке
+func (t *Taxonomy) OccasionKeys() []string {
+	return sortedKeys(t.Occasions)
+}
+
+// GenderKeys возвращает ключи полов в алфавитном порядке
+func (t *Taxonomy) GenderKeys() []string {
+	return sortedKeys(t.Genders)
+}
+
+// OccasionCategories возвращает категории для повода
+func (t *Taxonomy) OccasionCategories(occasion string) []string {
+	return t.Occasions[occasion].Categories
+}
+
+// AgeGroupFor возвращает возрастную группу, в которую попадает возраст
+func (t *Taxonomy) AgeGroupFor(age int) (string, AgeGroup, bool) {
+	for _, key := range sortedKeys(t.AgeGroups) {
+		group := t.AgeGroups[key]
+		if age >= group.MinAge && (group.MaxAge == 0 || age <= group.MaxAge) {
+			return key, group, true
+		}
+	}
+	return "", AgeGroup{}, false
+}
+
+// ResolveGender находит пол по ключу или синониму без учета регистра
+func (t *Taxonomy) ResolveGender(value string) (string, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return "", false
+	}
+	for _, key := range sortedKeys(t.Genders) {
+		if key == value {
+			return key, true
+		}
+		for _, alias := range t.Genders[key].Aliases {
+			if strings.ToLower(alias) == value {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// LabelCategories переводит метки Rekognition в категории без повторов
+func (t *Taxonomy) LabelCategories(labels []string) []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, label := range labels {
+		for _, category := range t.Labels[label] {
+			if !seen[category] {
+				seen[category] = true
+				categories = append(categories, category)
+			}
+		}
+	}
+	return categories
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
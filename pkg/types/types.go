@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 type GiftRequest struct {
 	Occasion     string   `json:"occasion"`      // Повод для подарка
 	Gender       string   `json:"gender"`        // Пол получателя
@@ -13,14 +15,25 @@ type GiftRequest struct {
 }
 
 type Range struct {
-	Min float64 `json:"min"`
-	Max float64 `json:"max"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	Currency string  `json:"currency,omitempty"` // Валюта границ (по умолчанию KZT)
 }
 
 type GiftRecommendation struct {
-	Products []Product `json:"products"`
-	Summary  string    `json:"summary"`   // Текстовое описание рекомендаций
-	AudioURL string    `json:"audio_url"` // URL аудио-версии (если запрошено)
+	Products     []Product `json:"products"`
+	Summary      string    `json:"summary"`                 // Текстовое описание рекомендаций
+	AudioURL     string    `json:"audio_url"`               // URL аудио-версии (если запрошено)
+	ImpressionID string    `json:"impression_id,omitempty"` // Для реакций в POST /feedback
+}
+
+// SearchIntent - что ищет пользователь: категории, свободный текст и интересы
+type SearchIntent struct {
+	Categories  []string
+	Query       string
+	Interests   []string
+	PriceRange  Range
+	Marketplace string
 }
 
 type Product struct {
@@ -28,95 +41,37 @@ type Product struct {
 	Title       string  `json:"title"`
 	Description string  `json:"description"`
 	Price       float64 `json:"price"`
+	Currency    string  `json:"currency"` // Код валюты ISO 4217
 	Rating      float64 `json:"rating"`
 	URL         string  `json:"url"`
 	ImageURL    string  `json:"image_url"`
 	Store       string  `json:"store"`
 	Category    string  `json:"category"`
+	Offers      []Offer `json:"offers,omitempty"`    // Все предложения объединенных дубликатов
+	Relevance   float64 `json:"relevance,omitempty"` // Совпадение с текстом запроса и интересами (0..1)
 }
 
-// Маппинг категорий для разных маркетплейсов
-var CategoryMappings = map[string]map[string]string{
-	"kaspi": {
-		"electronics": "Электроника",
-		"books":       "Книги",
-		"sports":      "Спорт и отдых",
-		"beauty":      "Красота и здоровье",
-		"toys":        "Детские товары",
-		"home":        "Товары для дома",
-	},
-	"aliexpress": {
-		"electronics": "Electronics",
-		"books":       "Books & Office",
-		"sports":      "Sports & Entertainment",
-		"beauty":      "Beauty & Health",
-		"toys":        "Toys & Hobbies",
-		"home":        "Home & Garden",
-	},
-	"wildberries": {
-		"electronics": "Электроника",
-		"books":       "Книги",
-		"sports":      "Спорт",
-		"beauty":      "Красота",
-		"toys":        "Детям",
-		"home":        "Дом",
-	},
-	"ozon": {
-		"electronics": "Электроника",
-		"books":       "Книги",
-		"sports":      "Спорт и отдых",
-		"beauty":      "Красота и здоровье",
-		"toys":        "Детские товары",
-		"home":        "Дом и сад",
-	},
-}
-
-// Маппинг поводов для подарков на категории
-var OccasionCategories = map[string][]string{
-	"birthday": {
-		"electronics",
-		"beauty",
-		"sports",
-		"home",
-	},
-	"wedding": {
-		"home",
-		"electronics",
-	},
-	"graduation": {
-		"electronics",
-		"books",
-		"sports",
-	},
-	"newborn": {
-		"toys",
-		"home",
-	},
-}
-
-// Маппинг возрастных групп на категории
-var AgeCategories = map[string][]string{
-	"child": { // 0-12
-		"toys",
-		"books",
-		"sports",
-	},
-	"teen": { // 13-19
-		"electronics",
-		"sports",
-		"books",
-	},
-	"adult": { // 20-59
-		"electronics",
-		"beauty",
-		"home",
-		"sports",
-	},
-	"senior": { // 60+
-		"home",
-		"books",
-		"health",
-	},
+// Предложение товара в конкретном магазине
+type Offer struct {
+	ProductID        string  `json:"product_id,omitempty"` // Карточка товара, к которой относится предложение
+	Store            string  `json:"store"`                // Магазин, где продается товар
+	Source           string  `json:"source"`               // Источник, из которого пришло предложение
+	Price            float64 `json:"price"`
+	Currency         string  `json:"currency"`
+	URL              string  `json:"url"`
+	DeliveryEstimate string  `json:"delivery_estimate,omitempty"` // Срок доставки в формате магазина
+}
+
+// Группа одинаковых товаров из разных магазинов с лучшим предложением
+type ProductGroup struct {
+	Title      string   `json:"title"`
+	Category   string   `json:"category,omitempty"`
+	ProductIDs []string `json:"product_ids"`
+	Offers     []Offer  `json:"offers"` // Отсортированы по цене
+	BestOffer  Offer    `json:"best_offer"`
+	BestPrice  float64  `json:"best_price"` // Лучшая цена в валюте запроса
+	Currency   string   `json:"currency"`
+	Savings    float64  `json:"savings"` // Разница между самым дорогим и самым дешевым предложением
 }
 
 // Структуры для API ответов
@@ -161,11 +116,184 @@ type SpeechResponseApi struct {
 
 // Структуры для поиска продуктов
 type ProductSearchRequestApi struct {
-	Categories  []string `json:"categories"`
+	Categories  []string `json:"categories,omitempty"`
+	Query       string   `json:"query,omitempty"`     // Свободный текст, например "беспроводные наушники"
+	Interests   []string `json:"interests,omitempty"` // Интересы получателя, поднимают подходящие товары выше
 	PriceRange  *Range   `json:"price_range,omitempty"`
 	Marketplace string   `json:"marketplace,omitempty"`
+	Sort        string   `json:"sort,omitempty"`   // relevance (по умолчанию), price_asc, price_desc, rating
+	Limit       int      `json:"limit,omitempty"`  // Размер страницы (по умолчанию 20, максимум 100)
+	Offset      int      `json:"offset,omitempty"` // Смещение от начала выборки
+	Cursor      string   `json:"cursor,omitempty"` // Курсор следующей страницы (заменяет offset)
 }
 
 type ProductSearchResponseApi struct {
-	Products []Product `json:"products"`
+	Products     []Product      `json:"products"`
+	Total        int            `json:"total"` // Сколько товаров найдено всего
+	Limit        int            `json:"limit"`
+	Offset       int            `json:"offset"`
+	NextCursor   string         `json:"next_cursor,omitempty"` // Пусто на последней странице
+	Facets       Facets         `json:"facets"`
+	Groups       []ProductGroup `json:"groups"`                  // Сравнение цен на одинаковые товары
+	Sources      []SourceStatus `json:"sources"`                 // Состояние каждого источника поиска
+	Cache        *CacheInfo     `json:"cache,omitempty"`         // Метаданные кэша (если кэш включен)
+	ImpressionID string         `json:"impression_id,omitempty"` // Для реакций в POST /feedback
+}
+
+// Статусы источников поиска
+const (
+	SourceOK             = "ok"
+	SourceError          = "error"
+	SourceTimeout        = "timeout"
+	SourceSkippedNoToken = "skipped-no-token"
+	SourceSkippedQuota   = "skipped-quota" // Квота платного API исчерпана
+)
+
+// Результат опроса одного источника (DynamoDB, Serper или маркетплейс)
+type SourceStatus struct {
+	Source    string `json:"source"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+	Count     int    `json:"count"`
+	Hedged    bool   `json:"hedged,omitempty"` // Запрос дублировался из-за медленного ответа
+}
+
+// Фасеты для фильтров в интерфейсе, считаются по всей выборке
+type Facets struct {
+	Stores       []FacetCount  `json:"stores"`
+	Categories   []FacetCount  `json:"categories"`
+	PriceBuckets []PriceBucket `json:"price_buckets"`
+	Currency     string        `json:"currency"` // Валюта границ ценовых корзин
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Ценовая корзина [min, max); max = 0 у последней корзины означает "и выше"
+type PriceBucket struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max,omitempty"`
+	Count int     `json:"count"`
+}
+
+// Метаданные кэша результатов поиска
+type CacheInfo struct {
+	Hit     bool                `json:"hit"`     // Все источники отданы из кэша
+	Sources []CacheSourceStatus `json:"sources"` // Состояние кэша по каждому источнику
+}
+
+type CacheSourceStatus struct {
+	Source   string    `json:"source"`
+	Hit      bool      `json:"hit"`
+	CachedAt time.Time `json:"cached_at,omitzero"` // Когда результат был сохранен в кэш
+}
+
+// Структуры для подбора категорий
+type CategoryResolution struct {
+	Occasion        string               `json:"occasion,omitempty"`
+	AgeGroup        string               `json:"age_group,omitempty"`
+	Gender          string               `json:"gender,omitempty"`
+	Categories      []CategorySuggestion `json:"categories"` // По убыванию веса
+	TaxonomyVersion string               `json:"taxonomy_version"`
+}
+
+type CategorySuggestion struct {
+	Category string   `json:"category"`
+	Name     string   `json:"name"`    // Название на языке запроса
+	Weight   float64  `json:"weight"`  // Относительный вес, у лучшей категории 1
+	Reasons  []string `json:"reasons"` // Что повлияло на вес: occasion:*, age:*, gender:*
+}
+
+// Структуры для диалога с советником
+type AdvisorMessageRequestApi struct {
+	Message string       `json:"message"`           // Ответ пользователя в свободной форме
+	Request *GiftRequest `json:"request,omitempty"` // Уже известные поля (только при создании сессии)
+}
+
+type AdvisorQuestion struct {
+	Field   string   `json:"field"` // Поле GiftRequest, о котором спрашиваем
+	Text    string   `json:"text"`
+	Options []string `json:"options,omitempty"` // Подсказки для быстрых ответов
+}
+
+type AdvisorTurnResponseApi struct {
+	SessionID      string              `json:"session_id"`
+	Request        GiftRequest         `json:"request"`            // Что известно после разбора ответов
+	Missing        []string            `json:"missing"`            // Незаполненные поля
+	Question       *AdvisorQuestion    `json:"question,omitempty"` // Следующий вопрос; nil - все известно
+	Turns          int                 `json:"turns"`
+	Recommendation *GiftRecommendation `json:"recommendation,omitempty"`
+}
+
+// Структуры для подбора подарков. Если указан recipient_id, пол, возраст,
+// интересы и повод берутся из профиля получателя, когда не заданы явно
+type RecommendRequestApi struct {
+	GiftRequest
+	RecipientID string `json:"recipient_id,omitempty"`
+}
+
+type RecommendResponseApi struct {
+	Request        GiftRequest        `json:"request"` // Запрос с полями из профиля получателя
+	Recommendation GiftRecommendation `json:"recommendation"`
+}
+
+// Структуры для обратной связи по рекомендациям
+type FeedbackRequestApi struct {
+	ImpressionID string             `json:"impression_id"` // impression_id из ответа с товарами
+	Events       []FeedbackEventApi `json:"events"`
+}
+
+type FeedbackEventApi struct {
+	Type      string `json:"type"` // clicked, liked, dismissed, purchased; показы пишет сервер
+	ProductID string `json:"product_id"`
+}
+
+type FeedbackResponseApi struct {
+	Recorded int `json:"recorded"`
+}
+
+// Структуры для списков желаний
+type WishlistRequestApi struct {
+	Name    string `json:"name"`
+	Version int    `json:"version,omitempty"` // Версия, которую видел клиент; 0 - без проверки
+}
+
+type WishlistItemRequestApi struct {
+	Product Product `json:"product"` // Товар из ProductSearchResponseApi
+	Note    string  `json:"note,omitempty"`
+}
+
+type ReserveRequestApi struct {
+	Name string `json:"name"` // Кто покупает подарок
+}
+
+type ReserveResponseApi struct {
+	ClaimID string `json:"claim_id"` // Нужен, чтобы снять резерв
+}
+
+// Структуры для отслеживания цен
+type PriceWatchRequestApi struct {
+	Product     Product `json:"product"`                // Товар из ProductSearchResponseApi или списка желаний; цена берется из источника
+	TargetPrice float64 `json:"target_price,omitempty"` // Оповестить, когда цена дойдет до этой; 0 - при заметном снижении
+	EmailAlerts bool    `json:"email_alerts,omitempty"` // Присылать письма на подтвержденный адрес пользователя
+	Language    string  `json:"language,omitempty"`     // Язык письма: ru, en, kk
+}
+
+// Структуры для диагностики
+type DiagnosticsResponseApi struct {
+	SerperQuota *QuotaStatusApi `json:"serper_quota,omitempty"`
+}
+
+// Остаток квоты платного API; -1 в remaining означает отсутствие лимита
+type QuotaStatusApi struct {
+	DailyUsed        int64 `json:"daily_used"`
+	DailyLimit       int64 `json:"daily_limit"`
+	DailyRemaining   int64 `json:"daily_remaining"`
+	MonthlyUsed      int64 `json:"monthly_used"`
+	MonthlyLimit     int64 `json:"monthly_limit"`
+	MonthlyRemaining int64 `json:"monthly_remaining"`
+	SoftLimitReached bool  `json:"soft_limit_reached"`
 }
@@ -0,0 +1,61 @@
+package versioned
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Table пишет записи в таблицу DynamoDB с проверкой атрибута version
+type Table struct {
+	client      *dynamodb.Client
+	name        string
+	idAttribute string // Атрибут ключа: его отсутствие значит, что записи еще нет
+	conflict    error
+}
+
+// NewTable создает таблицу; conflict возвращается, если версия не совпала
+func NewTable(client *dynamodb.Client, name, idAttribute string, conflict error) *Table {
+	return &Table{
+		client:      client,
+		name:        name,
+		idAttribute: idAttribute,
+		conflict:    conflict,
+	}
+}
+
+// Put записывает item, если версия записи в таблице равна expectedVersion
+func (t *Table) Put(ctx context.Context, item interface{}, expectedVersion int) error {
+	attributes, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s item: %w", t.name, err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(t.name),
+		Item:      attributes,
+	}
+	if expectedVersion == 0 {
+		input.ConditionExpression = aws.String("attribute_not_exists(" + t.idAttribute + ")")
+	} else {
+		input.ConditionExpression = aws.String("version = :version")
+		input.ExpressionAttributeValues = map[string]dyntypes.AttributeValue{
+			":version": &dyntypes.AttributeValueMemberN{Value: strconv.Itoa(expectedVersion)},
+		}
+	}
+
+	if _, err := t.client.PutItem(ctx, input); err != nil {
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return t.conflict
+		}
+		return fmt.Errorf("failed to put %s item: %w", t.name, err)
+	}
+	return nil
+}
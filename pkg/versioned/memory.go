@@ -0,0 +1,75 @@
+package versioned
+
+import "sync"
+
+// Memory хранит записи в памяти процесса - для локального запуска. Записи
+// копируются через clone, чтобы вызывающий код не менял сохраненные.
+type Memory[K comparable, V any] struct {
+	mu       sync.Mutex
+	items    map[K]V
+	version  func(V) int
+	clone    func(V) V
+	conflict error
+}
+
+// NewMemory создает хранилище; conflict возвращается, если версия не совпала
+func NewMemory[K comparable, V any](version func(V) int, clone func(V) V, conflict error) *Memory[K, V] {
+	return &Memory[K, V]{
+		items:    make(map[K]V),
+		version:  version,
+		clone:    clone,
+		conflict: conflict,
+	}
+}
+
+// Get возвращает копию записи
+func (m *Memory[K, V]) Get(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok {
+		return item, false
+	}
+	return m.clone(item), true
+}
+
+// Put записывает копию item, если версия сохраненной записи равна
+// expectedVersion (0 - записи еще нет)
+func (m *Memory[K, V]) Put(key K, item V, expectedVersion int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, exists := m.items[key]
+	if (expectedVersion == 0 && exists) || (expectedVersion != 0 && (!exists || m.version(current) != expectedVersion)) {
+		return m.conflict
+	}
+	m.items[key] = m.clone(item)
+	return nil
+}
+
+// Delete удаляет запись; false - записи не было
+func (m *Memory[K, V]) Delete(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.items[key]; !ok {
+		return false
+	}
+	delete(m.items, key)
+	return true
+}
+
+// Find возвращает копии записей, для которых match вернула true
+func (m *Memory[K, V]) Find(match func(V) bool) []V {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	found := []V{}
+	for _, item := range m.items {
+		if match(item) {
+			found = append(found, m.clone(item))
+		}
+	}
+	return found
+}
@@ -0,0 +1,33 @@
+// Package versioned - хранилища записей с оптимистичной блокировкой: запись
+// сохраняется, только если ее версия в хранилище равна ожидаемой (0 - записи
+// еще нет), иначе возвращается ошибка конфликта пакета-владельца.
+package versioned
+
+import (
+	"fmt"
+	"os"
+)
+
+// Key - ключ записи пользователя: user_id и ключ сортировки
+type Key struct {
+	UserID string
+	ID     string
+}
+
+// FromEnv выбирает хранилище по <prefix>_BACKEND: dynamodb (по умолчанию,
+// таблица <prefix>_TABLE или defaultTable) или memory для локального запуска
+func FromEnv[S any](prefix, defaultTable string, dynamo func(table string) S, memory func() S) (S, error) {
+	switch backend := os.Getenv(prefix + "_BACKEND"); backend {
+	case "", "dynamodb":
+		table := os.Getenv(prefix + "_TABLE")
+		if table == "" {
+			table = defaultTable
+		}
+		return dynamo(table), nil
+	case "memory":
+		return memory(), nil
+	default:
+		var store S
+		return store, fmt.Errorf("unknown %s_BACKEND: %s", prefix, backend)
+	}
+}
@@ -0,0 +1,106 @@
+package versioned
+
+import (
+	"errors"
+	"testing"
+)
+
+var errConflict = errors.New("conflict")
+
+type record struct {
+	ID      string
+	Tags    []string
+	Version int
+}
+
+func newTestMemory() *Memory[string, record] {
+	return NewMemory[string](
+		func(r record) int { return r.Version },
+		func(r record) record { r.Tags = append([]string(nil), r.Tags...); return r },
+		errConflict,
+	)
+}
+
+func TestMemoryPutVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   *record
+		expected int
+		wantErr  error
+	}{
+		{name: "create", expected: 0},
+		{name: "create existing", stored: &record{ID: "a", Version: 1}, expected: 0, wantErr: errConflict},
+		{name: "update current version", stored: &record{ID: "a", Version: 1}, expected: 1},
+		{name: "update stale version", stored: &record{ID: "a", Version: 2}, expected: 1, wantErr: errConflict},
+		{name: "update missing", expected: 1, wantErr: errConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := newTestMemory()
+			if tt.stored != nil {
+				if err := memory.Put("a", *tt.stored, 0); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := memory.Put("a", record{ID: "a", Version: tt.expected + 1}, tt.expected)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Put() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMemoryClones(t *testing.T) {
+	memory := newTestMemory()
+	item := record{ID: "a", Tags: []string{"x"}, Version: 1}
+	if err := memory.Put("a", item, 0); err != nil {
+		t.Fatal(err)
+	}
+	item.Tags[0] = "changed"
+
+	got, ok := memory.Get("a")
+	if !ok || got.Tags[0] != "x" {
+		t.Fatalf("Get() = %+v, %v; stored record must not change with the caller's copy", got, ok)
+	}
+	got.Tags[0] = "changed"
+	if found := memory.Find(func(r record) bool { return r.ID == "a" }); found[0].Tags[0] != "x" {
+		t.Errorf("Find() = %+v; returned copies must not change the store", found)
+	}
+
+	if !memory.Delete("a") || memory.Delete("a") {
+		t.Error("Delete() must report whether the record existed")
+	}
+	if found := memory.Find(func(record) bool { return true }); found == nil || len(found) != 0 {
+		t.Errorf("Find() = %#v, want empty slice", found)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	dynamo := func(table string) string { return "dynamodb:" + table }
+	memory := func() string { return "memory" }
+
+	tests := []struct {
+		backend string
+		table   string
+		want    string
+		wantErr bool
+	}{
+		{want: "dynamodb:items"},
+		{backend: "dynamodb", table: "custom", want: "dynamodb:custom"},
+		{backend: "memory", want: "memory"},
+		{backend: "redis", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			t.Setenv("TEST_BACKEND", tt.backend)
+			t.Setenv("TEST_TABLE", tt.table)
+			got, err := FromEnv("TEST", "items", dynamo, memory)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FromEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package wishlist
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Форматы выгрузки списка
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+var csvHeader = []string{"title", "price", "currency", "store", "url", "note", "reserved"}
+
+// Export пишет список в формате JSON или CSV. Выгрузку делает владелец,
+// поэтому имена зарезервировавших в нее не попадают.
+func Export(w io.Writer, wishlist *Wishlist, format string) error {
+	view := wishlist.OwnerView()
+	switch format {
+	case "", FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(view)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, item := range view.Items {
+			product := item.Product
+			err := writer.Write([]string{
+				product.Title,
+				strconv.FormatFloat(product.Price, 'f', -1, 64),
+				product.Currency,
+				product.Store,
+				product.URL,
+				item.Note,
+				strconv.FormatBool(item.Reserved),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("%w: unsupported export format %q", ErrInvalid, format)
+	}
+}
+
+// ContentType возвращает MIME-тип выгрузки
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json"
+}
@@ -0,0 +1,100 @@
+package wishlist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+func exportList() *Wishlist {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &Wishlist{
+		UserID:     "u1",
+		ID:         "list1",
+		Name:       "День рождения",
+		ShareToken: "secret-token",
+		Version:    3,
+		CreatedAt:  at,
+		UpdatedAt:  at,
+		Items: []Item{
+			{
+				ID:      "i1",
+				Product: types.Product{ID: "kaspi-1", Title: "Наушники, черные", Price: 45990.5, Currency: "KZT", Store: "kaspi", URL: "https://kaspi.kz/p/1"},
+				Note:    `размер "M"`,
+				AddedAt: at,
+				Reservation: &Reservation{
+					By:         "Айгуль",
+					ClaimID:    "claim-secret",
+					ReservedAt: at,
+				},
+			},
+			{
+				ID:      "i2",
+				Product: types.Product{ID: "ozon-2", Title: "Плед", Price: 1500, Currency: "RUB", Store: "ozon", URL: "https://ozon.ru/p/2"},
+				AddedAt: at,
+			},
+		},
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	var out bytes.Buffer
+	if err := Export(&out, exportList(), FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "title,price,currency,store,url,note,reserved\n" +
+		"\"Наушники, черные\",45990.5,KZT,kaspi,https://kaspi.kz/p/1,\"размер \"\"M\"\"\",true\n" +
+		"Плед,1500,RUB,ozon,https://ozon.ru/p/2,,false\n"
+	if out.String() != want {
+		t.Errorf("Export(csv):\ngot:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if strings.Contains(out.String(), "Айгуль") {
+		t.Error("CSV export must not contain who reserved")
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	for _, format := range []string{"", FormatJSON} {
+		var out bytes.Buffer
+		if err := Export(&out, exportList(), format); err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range []string{"Айгуль", "claim-secret"} {
+			if strings.Contains(out.String(), secret) {
+				t.Errorf("JSON export contains %q:\n%s", secret, out.String())
+			}
+		}
+
+		var view View
+		if err := json.Unmarshal(out.Bytes(), &view); err != nil {
+			t.Fatal(err)
+		}
+		if view.ID != "list1" || view.Name != "День рождения" || view.Version != 3 || len(view.Items) != 2 {
+			t.Fatalf("view = %+v", view)
+		}
+		if !view.Items[0].Reserved || view.Items[0].ReservedBy != "" || view.Items[1].Reserved {
+			t.Errorf("items = %+v, want first reserved without name", view.Items)
+		}
+		if view.Items[0].Note != `размер "M"` || view.Items[1].Product.Price != 1500 {
+			t.Errorf("items = %+v", view.Items)
+		}
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if err := Export(&bytes.Buffer{}, exportList(), "xlsx"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Export(xlsx) error = %v, want ErrInvalid", err)
+	}
+	if got := ContentType(FormatCSV); got != "text/csv; charset=utf-8" {
+		t.Errorf("ContentType(csv) = %q", got)
+	}
+	if got := ContentType(FormatJSON); got != "application/json" {
+		t.Errorf("ContentType(json) = %q", got)
+	}
+}
@@ -0,0 +1,256 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Сколько раз повторять изменение при параллельной записи
+const updateRetries = 3
+
+// Service - операции со списками поверх хранилища. Каждое изменение
+// перечитывает список и записывает его с проверкой версии.
+type Service struct {
+	store Store
+}
+
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+func (s *Service) List(ctx context.Context, userID string) ([]Wishlist, error) {
+	return s.store.List(ctx, userID)
+}
+
+func (s *Service) Get(ctx context.Context, userID, id string) (*Wishlist, error) {
+	return s.store.Get(ctx, userID, id)
+}
+
+func (s *Service) Delete(ctx context.Context, userID, id string) error {
+	return s.store.Delete(ctx, userID, id)
+}
+
+// Create создает пустой список
+func (s *Service) Create(ctx context.Context, userID, name string) (*Wishlist, error) {
+	name, err := validName(name)
+	if err != nil {
+		return nil, err
+	}
+	id, err := newToken(8)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	wishlist := &Wishlist{
+		UserID:    userID,
+		ID:        id,
+		Name:      name,
+		Items:     []Item{},
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.store.Put(ctx, wishlist, 0); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// Rename меняет название. version - версия, которую видел клиент; 0 -
+// без проверки.
+func (s *Service) Rename(ctx context.Context, userID, id, name string, version int) (*Wishlist, error) {
+	name, err := validName(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.update(ctx, userID, id, version, func(w *Wishlist) error {
+		w.Name = name
+		return nil
+	})
+}
+
+// AddItem добавляет товар из результатов поиска. Повторно тот же товар не
+// добавляется - возвращается список как есть.
+func (s *Service) AddItem(ctx context.Context, userID, id string, product types.Product, note string) (*Wishlist, error) {
+	if product.ID == "" || strings.TrimSpace(product.Title) == "" {
+		return nil, fmt.Errorf("%w: product id and title are required", ErrInvalid)
+	}
+	itemID, err := newToken(6)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, userID, id, 0, func(w *Wishlist) error {
+		for _, item := range w.Items {
+			if item.Product.ID == product.ID {
+				return errUnchanged
+			}
+		}
+		if len(w.Items) >= MaxItems {
+			return fmt.Errorf("%w: list is limited to %d items", ErrInvalid, MaxItems)
+		}
+		w.Items = append(w.Items, Item{
+			ID:      itemID,
+			Product: product,
+			Note:    strings.TrimSpace(note),
+			AddedAt: time.Now().UTC(),
+		})
+		return nil
+	})
+}
+
+func (s *Service) RemoveItem(ctx context.Context, userID, id, itemID string) (*Wishlist, error) {
+	return s.update(ctx, userID, id, 0, func(w *Wishlist) error {
+		if _, err := w.item(itemID); err != nil {
+			return err
+		}
+		items := w.Items[:0]
+		for _, item := range w.Items {
+			if item.ID != itemID {
+				items = append(items, item)
+			}
+		}
+		w.Items = items
+		return nil
+	})
+}
+
+// Share создает публичную ссылку или заменяет старую - прежняя перестает
+// работать
+func (s *Service) Share(ctx context.Context, userID, id string) (*Wishlist, error) {
+	token, err := newToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.update(ctx, userID, id, 0, func(w *Wishlist) error {
+		w.ShareToken = token
+		return nil
+	})
+}
+
+// Unshare отзывает публичную ссылку
+func (s *Service) Unshare(ctx context.Context, userID, id string) (*Wishlist, error) {
+	return s.update(ctx, userID, id, 0, func(w *Wishlist) error {
+		if w.ShareToken == "" {
+			return errUnchanged
+		}
+		w.ShareToken = ""
+		return nil
+	})
+}
+
+// Shared возвращает список по публичной ссылке
+func (s *Service) Shared(ctx context.Context, token string) (*Wishlist, error) {
+	if token == "" {
+		return nil, ErrNotFound
+	}
+	return s.store.GetByToken(ctx, token)
+}
+
+// Reserve отмечает товар как купленный гостем by и возвращает секрет для
+// отмены резерва. Если товар уже зарезервирован - ErrReserved.
+func (s *Service) Reserve(ctx context.Context, token, itemID, by string) (string, error) {
+	by = strings.TrimSpace(by)
+	if by == "" || len([]rune(by)) > 100 {
+		return "", fmt.Errorf("%w: name of who reserves is required (up to 100 characters)", ErrInvalid)
+	}
+	claimID, err := newToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.updateShared(ctx, token, func(w *Wishlist) error {
+		item, err := w.item(itemID)
+		if err != nil {
+			return err
+		}
+		if item.Reservation != nil {
+			return ErrReserved
+		}
+		item.Reservation = &Reservation{By: by, ClaimID: claimID, ReservedAt: time.Now().UTC()}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return claimID, nil
+}
+
+// CancelReservation снимает резерв; нужен секрет, выданный при резерве
+func (s *Service) CancelReservation(ctx context.Context, token, itemID, claimID string) (*Wishlist, error) {
+	return s.updateShared(ctx, token, func(w *Wishlist) error {
+		item, err := w.item(itemID)
+		if err != nil {
+			return err
+		}
+		if item.Reservation == nil {
+			return errUnchanged
+		}
+		if claimID == "" || item.Reservation.ClaimID != claimID {
+			return ErrReserved
+		}
+		item.Reservation = nil
+		return nil
+	})
+}
+
+// errUnchanged - изменение не нужно, список сохранять не надо
+var errUnchanged = errors.New("unchanged")
+
+// update применяет change к свежей версии списка владельца и сохраняет
+// его. При параллельной записи повторяет, если клиент не передал версию.
+func (s *Service) update(ctx context.Context, userID, id string, version int, change func(*Wishlist) error) (*Wishlist, error) {
+	return s.retry(ctx, version, func() (*Wishlist, error) {
+		return s.store.Get(ctx, userID, id)
+	}, change)
+}
+
+func (s *Service) updateShared(ctx context.Context, token string, change func(*Wishlist) error) (*Wishlist, error) {
+	return s.retry(ctx, 0, func() (*Wishlist, error) {
+		return s.Shared(ctx, token)
+	}, change)
+}
+
+func (s *Service) retry(ctx context.Context, version int, load func() (*Wishlist, error), change func(*Wishlist) error) (*Wishlist, error) {
+	for attempt := 0; ; attempt++ {
+		wishlist, err := load()
+		if err != nil {
+			return nil, err
+		}
+		if version != 0 && wishlist.Version != version {
+			return nil, ErrConflict
+		}
+
+		expectedVersion := wishlist.Version
+		if err := change(wishlist); errors.Is(err, errUnchanged) {
+			return wishlist, nil
+		} else if err != nil {
+			return nil, err
+		}
+		wishlist.Version++
+		wishlist.UpdatedAt = time.Now().UTC()
+
+		err = s.store.Put(ctx, wishlist, expectedVersion)
+		if errors.Is(err, ErrConflict) && version == 0 && attempt < updateRetries {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return wishlist, nil
+	}
+}
+
+func validName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > 100 {
+		return "", fmt.Errorf("%w: name is required (up to 100 characters)", ErrInvalid)
+	}
+	return name, nil
+}
@@ -0,0 +1,151 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// sharedList создает опубликованный список с одним товаром
+func sharedList(t *testing.T, service *Service) (*Wishlist, string) {
+	t.Helper()
+	ctx := context.Background()
+	wishlist, err := service.Create(ctx, "u1", "День рождения")
+	if err != nil {
+		t.Fatal(err)
+	}
+	product := types.Product{ID: "kaspi-1", Title: "Наушники", Price: 45990, Currency: "KZT", Store: "kaspi"}
+	if wishlist, err = service.AddItem(ctx, "u1", wishlist.ID, product, "черные"); err != nil {
+		t.Fatal(err)
+	}
+	if wishlist, err = service.Share(ctx, "u1", wishlist.ID); err != nil {
+		t.Fatal(err)
+	}
+	return wishlist, wishlist.Items[0].ID
+}
+
+func TestReserve(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(NewMemoryStore())
+	wishlist, itemID := sharedList(t, service)
+
+	claimID, err := service.Reserve(ctx, wishlist.ShareToken, itemID, "Айгуль")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimID == "" {
+		t.Fatal("Reserve() must return claim id")
+	}
+
+	if _, err := service.Reserve(ctx, wishlist.ShareToken, itemID, "Ерлан"); !errors.Is(err, ErrReserved) {
+		t.Errorf("second Reserve() error = %v, want ErrReserved", err)
+	}
+	if _, err := service.Reserve(ctx, wishlist.ShareToken, "missing", "Ерлан"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Reserve(missing item) error = %v, want ErrItemNotFound", err)
+	}
+	if _, err := service.Reserve(ctx, wishlist.ShareToken, itemID, "  "); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Reserve(empty name) error = %v, want ErrInvalid", err)
+	}
+
+	shared, err := service.Shared(ctx, wishlist.ShareToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if view := shared.PublicView(); !view.Items[0].Reserved || view.Items[0].ReservedBy != "Айгуль" {
+		t.Errorf("public item = %+v, want reserved by Айгуль", view.Items[0])
+	}
+	if view := shared.OwnerView(); !view.Items[0].Reserved || view.Items[0].ReservedBy != "" {
+		t.Errorf("owner item = %+v, want reserved without name", view.Items[0])
+	}
+}
+
+func TestCancelReservation(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(NewMemoryStore())
+	wishlist, itemID := sharedList(t, service)
+
+	claimID, err := service.Reserve(ctx, wishlist.ShareToken, itemID, "Айгуль")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, wrong := range []string{"", "not-the-claim"} {
+		if _, err := service.CancelReservation(ctx, wishlist.ShareToken, itemID, wrong); !errors.Is(err, ErrReserved) {
+			t.Errorf("CancelReservation(%q) error = %v, want ErrReserved", wrong, err)
+		}
+	}
+
+	updated, err := service.CancelReservation(ctx, wishlist.ShareToken, itemID, claimID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Items[0].Reservation != nil {
+		t.Error("reservation must be removed")
+	}
+	if _, err := service.Reserve(ctx, wishlist.ShareToken, itemID, "Ерлан"); err != nil {
+		t.Errorf("Reserve() after cancel error = %v", err)
+	}
+}
+
+func TestRevokedShareToken(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(NewMemoryStore())
+	wishlist, itemID := sharedList(t, service)
+	oldToken := wishlist.ShareToken
+
+	// Новая ссылка заменяет старую
+	reshared, err := service.Share(ctx, "u1", wishlist.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reshared.ShareToken == oldToken {
+		t.Fatal("Share() must issue a new token")
+	}
+	if _, err := service.Shared(ctx, oldToken); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Shared(replaced token) error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := service.Unshare(ctx, "u1", wishlist.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Shared(ctx, reshared.ShareToken); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Shared(revoked token) error = %v, want ErrNotFound", err)
+	}
+	if _, err := service.Reserve(ctx, reshared.ShareToken, itemID, "Айгуль"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Reserve(revoked token) error = %v, want ErrNotFound", err)
+	}
+	if _, err := service.Shared(ctx, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Shared(empty) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateVersions(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(NewMemoryStore())
+	wishlist, _ := sharedList(t, service)
+
+	// Повторное добавление того же товара список не меняет
+	same, err := service.AddItem(ctx, "u1", wishlist.ID, wishlist.Items[0].Product, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(same.Items) != 1 || same.Version != wishlist.Version {
+		t.Errorf("AddItem(duplicate) = %d items, version %d; want unchanged", len(same.Items), same.Version)
+	}
+
+	renamed, err := service.Rename(ctx, "u1", wishlist.ID, "Новый год", wishlist.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Version != wishlist.Version+1 {
+		t.Errorf("version = %d, want %d", renamed.Version, wishlist.Version+1)
+	}
+	if _, err := service.Rename(ctx, "u1", wishlist.ID, "Старое", wishlist.Version); !errors.Is(err, ErrConflict) {
+		t.Errorf("Rename(stale version) error = %v, want ErrConflict", err)
+	}
+	if _, err := service.Rename(ctx, "u2", wishlist.ID, "Чужой", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Rename(other user) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,222 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/versioned"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dyntypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Store хранит списки. Put записывает список, только если его версия в
+// хранилище равна expectedVersion (0 - списка еще нет), иначе ErrConflict.
+type Store interface {
+	List(ctx context.Context, userID string) ([]Wishlist, error)
+	Get(ctx context.Context, userID, id string) (*Wishlist, error)
+	GetByToken(ctx context.Context, token string) (*Wishlist, error)
+	Put(ctx context.Context, wishlist *Wishlist, expectedVersion int) error
+	Delete(ctx context.Context, userID, id string) error
+}
+
+// NewStoreFromEnv выбирает хранилище по WISHLIST_BACKEND и WISHLIST_TABLE
+// (по умолчанию wishlists), см. versioned.FromEnv; индекс публичных ссылок -
+// WISHLIST_SHARE_INDEX
+func NewStoreFromEnv(dynamoClient *dynamodb.Client) (Store, error) {
+	return versioned.FromEnv("WISHLIST", "wishlists",
+		func(table string) Store {
+			index := os.Getenv("WISHLIST_SHARE_INDEX")
+			if index == "" {
+				index = "share_token-index"
+			}
+			return NewDynamoStore(dynamoClient, table, index)
+		},
+		func() Store { return NewMemoryStore() })
+}
+
+// DynamoStore хранит списки в таблице с ключом user_id и ключом сортировки
+// list_id; публичные ссылки ищутся по глобальному индексу share_token
+type DynamoStore struct {
+	client     *dynamodb.Client
+	tableName  string
+	shareIndex string
+	versions   *versioned.Table
+}
+
+func NewDynamoStore(client *dynamodb.Client, tableName, shareIndex string) *DynamoStore {
+	return &DynamoStore{
+		client:     client,
+		tableName:  tableName,
+		shareIndex: shareIndex,
+		versions:   versioned.NewTable(client, tableName, "list_id", ErrConflict),
+	}
+}
+
+func (s *DynamoStore) List(ctx context.Context, userID string) ([]Wishlist, error) {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		KeyConditionExpression: aws.String("user_id = :user"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":user": &dyntypes.AttributeValueMemberS{Value: userID},
+		},
+	})
+
+	wishlists := []Wishlist{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query wishlists: %w", err)
+		}
+		var items []Wishlist
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal wishlists: %w", err)
+		}
+		wishlists = append(wishlists, items...)
+	}
+	return wishlists, nil
+}
+
+func (s *DynamoStore) Get(ctx context.Context, userID, id string) (*Wishlist, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.tableName),
+		Key:            key(userID, id),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist: %w", err)
+	}
+	if result.Item == nil {
+		return nil, ErrNotFound
+	}
+
+	var wishlist Wishlist
+	if err := attributevalue.UnmarshalMap(result.Item, &wishlist); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal wishlist: %w", err)
+	}
+	return &wishlist, nil
+}
+
+// GetByToken находит список по индексу и перечитывает его консистентно:
+// глобальный индекс может отставать, а резервы требуют свежей версии
+func (s *DynamoStore) GetByToken(ctx context.Context, token string) (*Wishlist, error) {
+	result, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		IndexName:              aws.String(s.shareIndex),
+		KeyConditionExpression: aws.String("share_token = :token"),
+		ExpressionAttributeValues: map[string]dyntypes.AttributeValue{
+			":token": &dyntypes.AttributeValueMemberS{Value: token},
+		},
+		Limit: aws.Int32(1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wishlist by token: %w", err)
+	}
+	if len(result.Items) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var found Wishlist
+	if err := attributevalue.UnmarshalMap(result.Items[0], &found); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal wishlist: %w", err)
+	}
+
+	wishlist, err := s.Get(ctx, found.UserID, found.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Ссылку могли отозвать после обновления индекса
+	if wishlist.ShareToken != token {
+		return nil, ErrNotFound
+	}
+	return wishlist, nil
+}
+
+func (s *DynamoStore) Put(ctx context.Context, wishlist *Wishlist, expectedVersion int) error {
+	return s.versions.Put(ctx, wishlist, expectedVersion)
+}
+
+func (s *DynamoStore) Delete(ctx context.Context, userID, id string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.tableName),
+		Key:                 key(userID, id),
+		ConditionExpression: aws.String("attribute_exists(list_id)"),
+	})
+	if err != nil {
+		var conditionFailed *dyntypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete wishlist: %w", err)
+	}
+	return nil
+}
+
+func key(userID, id string) map[string]dyntypes.AttributeValue {
+	return map[string]dyntypes.AttributeValue{
+		"user_id": &dyntypes.AttributeValueMemberS{Value: userID},
+		"list_id": &dyntypes.AttributeValueMemberS{Value: id},
+	}
+}
+
+// MemoryStore хранит списки в памяти процесса - для локального запуска
+type MemoryStore struct {
+	wishlists *versioned.Memory[versioned.Key, Wishlist]
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{wishlists: versioned.NewMemory[versioned.Key](func(w Wishlist) int { return w.Version }, clone, ErrConflict)}
+}
+
+func (s *MemoryStore) List(ctx context.Context, userID string) ([]Wishlist, error) {
+	wishlists := s.wishlists.Find(func(w Wishlist) bool { return w.UserID == userID })
+	// Как в DynamoDB - по ключу сортировки
+	sort.Slice(wishlists, func(i, j int) bool { return wishlists[i].ID < wishlists[j].ID })
+	return wishlists, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, userID, id string) (*Wishlist, error) {
+	wishlist, ok := s.wishlists.Get(versioned.Key{UserID: userID, ID: id})
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &wishlist, nil
+}
+
+func (s *MemoryStore) GetByToken(ctx context.Context, token string) (*Wishlist, error) {
+	found := s.wishlists.Find(func(w Wishlist) bool { return token != "" && w.ShareToken == token })
+	if len(found) == 0 {
+		return nil, ErrNotFound
+	}
+	return &found[0], nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, wishlist *Wishlist, expectedVersion int) error {
+	return s.wishlists.Put(versioned.Key{UserID: wishlist.UserID, ID: wishlist.ID}, *wishlist, expectedVersion)
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, userID, id string) error {
+	if !s.wishlists.Delete(versioned.Key{UserID: userID, ID: id}) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// clone копирует товары и резервы, чтобы вызывающий код не менял
+// сохраненный список
+func clone(wishlist Wishlist) Wishlist {
+	items := make([]Item, len(wishlist.Items))
+	for i, item := range wishlist.Items {
+		if item.Reservation != nil {
+			reservation := *item.Reservation
+			item.Reservation = &reservation
+		}
+		items[i] = item
+	}
+	wishlist.Items = items
+	return wishlist
+}
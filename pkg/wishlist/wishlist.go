@@ -0,0 +1,137 @@
+package wishlist
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MrRobotDumbazz/nFactorial-AI-Cup-2025/pkg/types"
+)
+
+// Сколько товаров помещается в один список (элемент DynamoDB до 400 КБ)
+const MaxItems = 100
+
+var (
+	ErrNotFound = errors.New("wishlist not found")
+	// ErrItemNotFound - товара нет в списке
+	ErrItemNotFound = errors.New("wishlist item not found")
+	// ErrConflict - список успели изменить параллельным запросом
+	ErrConflict = errors.New("wishlist was modified concurrently")
+	// ErrReserved - товар уже зарезервировал кто-то другой
+	ErrReserved = errors.New("item is already reserved")
+	// ErrInvalid - некорректное название, товар или переполненный список
+	ErrInvalid = errors.New("invalid wishlist")
+)
+
+// Wishlist - именованный список товаров пользователя
+type Wishlist struct {
+	UserID     string    `dynamodbav:"user_id"`
+	ID         string    `dynamodbav:"list_id"`
+	Name       string    `dynamodbav:"name"`
+	Items      []Item    `dynamodbav:"items"`
+	ShareToken string    `dynamodbav:"share_token,omitempty"` // Пусто - список не опубликован
+	Version    int       `dynamodbav:"version"`               // Для оптимистичной блокировки
+	CreatedAt  time.Time `dynamodbav:"created_at"`
+	UpdatedAt  time.Time `dynamodbav:"updated_at"`
+}
+
+// Item - товар в списке
+type Item struct {
+	ID          string        `dynamodbav:"item_id"`
+	Product     types.Product `dynamodbav:"product"`
+	Note        string        `dynamodbav:"note,omitempty"`
+	AddedAt     time.Time     `dynamodbav:"added_at"`
+	Reservation *Reservation  `dynamodbav:"reservation,omitempty"`
+}
+
+// Reservation - отметка "я куплю это"; ClaimID знает только тот, кто
+// зарезервировал, и по нему резерв можно снять
+type Reservation struct {
+	By         string    `dynamodbav:"by"`
+	ClaimID    string    `dynamodbav:"claim_id"`
+	ReservedAt time.Time `dynamodbav:"reserved_at"`
+}
+
+func (w *Wishlist) item(id string) (*Item, error) {
+	for i := range w.Items {
+		if w.Items[i].ID == id {
+			return &w.Items[i], nil
+		}
+	}
+	return nil, ErrItemNotFound
+}
+
+// View - список для владельца или по публичной ссылке
+type View struct {
+	ID         string     `json:"id,omitempty"`
+	Name       string     `json:"name"`
+	Items      []ItemView `json:"items"`
+	ShareToken string     `json:"share_token,omitempty"`
+	Version    int        `json:"version,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type ItemView struct {
+	ID         string        `json:"id"`
+	Product    types.Product `json:"product"`
+	Note       string        `json:"note,omitempty"`
+	AddedAt    time.Time     `json:"added_at"`
+	Reserved   bool          `json:"reserved"`
+	ReservedBy string        `json:"reserved_by,omitempty"`
+}
+
+// OwnerView показывает владельцу, что товар зарезервирован, но не кем.
+// Это не защита сюрприза: ссылка открывается без авторизации, и владелец
+// видит имена в PublicView, как и все, кому он ее отправил.
+func (w *Wishlist) OwnerView() View {
+	view := View{
+		ID:         w.ID,
+		Name:       w.Name,
+		ShareToken: w.ShareToken,
+		Version:    w.Version,
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}
+	view.Items = w.itemViews(false)
+	return view
+}
+
+// PublicView - список по ссылке: без служебных полей, но с именами тех,
+// кто уже зарезервировал подарок, чтобы родные договорились между собой
+func (w *Wishlist) PublicView() View {
+	return View{
+		Name:      w.Name,
+		Items:     w.itemViews(true),
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+func (w *Wishlist) itemViews(showReservedBy bool) []ItemView {
+	items := make([]ItemView, len(w.Items))
+	for i, item := range w.Items {
+		items[i] = ItemView{
+			ID:       item.ID,
+			Product:  item.Product,
+			Note:     item.Note,
+			AddedAt:  item.AddedAt,
+			Reserved: item.Reservation != nil,
+		}
+		if showReservedBy && item.Reservation != nil {
+			items[i].ReservedBy = item.Reservation.By
+		}
+	}
+	return items
+}
+
+// newToken возвращает случайный hex-идентификатор длиной 2*size символов
+func newToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}